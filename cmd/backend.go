@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"go-crud/config"
+	deliveryHTTP "go-crud/delivery/http"
+	"go-crud/internal/repository"
+	"go-crud/internal/repository/memory"
+)
+
+// Backend penyimpanan yang bisa dipilih lewat --backend
+const (
+	backendPostgres = "postgres"
+	backendMemory   = "memory"
+)
+
+// backend berisi semua repository dan dependency penyimpanan yang dipakai aplikasi
+type backend struct {
+	userRepo       repository.UserRepository
+	repoRepo       repository.RepositoryRepository
+	codeReviewRepo repository.CodeReviewRepository
+	reviewJobRepo  repository.ReviewJobRepository
+	reviewEvents   repository.ReviewEventRepository
+	analyzerRepo   repository.AnalyzerSettingsRepository
+	aiUsageRepo    repository.AIUsageRepository
+	webhookSecrets repository.WebhookSecretRepository
+	webhookRepo    repository.WebhookRepository
+	notifyPrefs    repository.NotificationPreferenceRepository
+	commandRepo    repository.CommandRepository
+	outboxRepo     repository.OutboxRepository
+	deadLetterRepo repository.DeadLetterRepository
+	auditRepo      repository.AuditLogMongoRepository
+	cache          repository.CacheRepository
+	healthChecks   []deliveryHTTP.HealthCheck
+	cleanup        func()
+}
+
+func newBackend(kind string) (*backend, error) {
+	switch kind {
+	case backendPostgres:
+		return newPostgresBackend()
+	case backendMemory:
+		return newMemoryBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q, use %s or %s", kind, backendPostgres, backendMemory)
+	}
+}
+
+// newPostgresBackend memakai Postgres, Redis dan MongoDB
+func newPostgresBackend() (*backend, error) {
+	// Ambil URI dan DB name dari env
+	mongoURI := os.Getenv("MONGO_URI")
+	mongoDBName := os.Getenv("MONGO_DB_NAME")
+
+	if mongoURI == "" || mongoDBName == "" {
+		return nil, errors.New("MONGO_URI or MONGO_DB_NAME not set in environment variables")
+	}
+
+	// Init PostgreSQL
+	config.InitDB()
+
+	// Init Redis
+	config.InitRedis()
+
+	// Init MongoDB
+	mongoClient, _, mongoCleanup, err := config.InitMongoDB(mongoURI, mongoDBName)
+	if err != nil {
+		config.CloseRedis()
+		config.CloseDB()
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	cache := repository.NewRedisCacheRepository(config.RedisClient)
+
+	return &backend{
+		userRepo:       repository.NewUserRepository(config.DBPool),
+		repoRepo:       repository.NewRepositoryRepository(config.DBPool),
+		codeReviewRepo: repository.NewCodeReviewRepository(config.DBPool),
+		reviewJobRepo:  repository.NewReviewJobRepository(config.DBPool),
+		reviewEvents:   repository.NewRedisReviewEventRepository(config.RedisClient),
+		analyzerRepo:   repository.NewAnalyzerSettingsRepository(config.DBPool),
+		aiUsageRepo:    repository.NewAIUsageRepository(config.DBPool),
+		webhookSecrets: repository.NewWebhookSecretRepository(config.DBPool),
+		webhookRepo:    repository.NewWebhookRepository(config.DBPool),
+		notifyPrefs:    repository.NewNotificationPreferenceRepository(config.DBPool),
+		commandRepo:    repository.NewCommandRepository(config.DBPool),
+		outboxRepo:     repository.NewOutboxRepository(config.DBPool),
+		deadLetterRepo: repository.NewDeadLetterRepository(config.DBPool),
+		auditRepo:      repository.NewAuditLogMongoRepository(mongoClient.Database("audit_log_db")),
+		cache:          cache,
+		healthChecks: []deliveryHTTP.HealthCheck{
+			{Name: "DB", Ping: config.DBPool.Ping},
+			{Name: "Redis", Ping: cache.Ping},
+		},
+		cleanup: func() {
+			mongoCleanup()
+			config.CloseRedis()
+			config.CloseDB()
+		},
+	}, nil
+}
+
+// newMemoryBackend menyimpan semua data di memori proses, tanpa dependency eksternal
+func newMemoryBackend() *backend {
+	log.Println("🧠 Backend memory aktif, data hilang saat aplikasi berhenti")
+
+	store := memory.NewStore()
+	cache := memory.NewCacheRepository()
+
+	return &backend{
+		userRepo:       memory.NewUserRepository(store),
+		repoRepo:       memory.NewRepositoryRepository(store),
+		codeReviewRepo: memory.NewCodeReviewRepository(store),
+		reviewJobRepo:  memory.NewReviewJobRepository(store),
+		reviewEvents:   memory.NewReviewEventRepository(0),
+		analyzerRepo:   memory.NewAnalyzerSettingsRepository(store),
+		aiUsageRepo:    memory.NewAIUsageRepository(store),
+		webhookSecrets: memory.NewWebhookSecretRepository(store),
+		webhookRepo:    memory.NewWebhookRepository(store),
+		notifyPrefs:    memory.NewNotificationPreferenceRepository(store),
+		commandRepo:    memory.NewCommandRepository(store),
+		outboxRepo:     memory.NewOutboxRepository(store),
+		deadLetterRepo: memory.NewDeadLetterRepository(store),
+		auditRepo:      memory.NewAuditLogRepository(store),
+		cache:          cache,
+		healthChecks: []deliveryHTTP.HealthCheck{
+			{Name: "Cache", Ping: cache.Ping},
+		},
+		cleanup: func() {},
+	}
+}
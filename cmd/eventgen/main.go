@@ -0,0 +1,151 @@
+// eventgen membaca schema Avro di schemas/*.avsc, mendaftarkannya ke schema
+// registry berbasis file, lalu menghasilkan struct Go dan file .proto.
+//
+// Dijalankan lewat: go generate ./internal/event
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-crud/internal/schemaregistry"
+)
+
+var initialisms = map[string]bool{"id": true, "url": true, "ai": true, "api": true, "sha": true}
+
+var goTypes = map[string]string{
+	schemaregistry.TypeInt:     "int",
+	schemaregistry.TypeLong:    "int64",
+	schemaregistry.TypeString:  "string",
+	schemaregistry.TypeBoolean: "bool",
+	schemaregistry.TypeDouble:  "float64",
+}
+
+var protoTypes = map[string]string{
+	schemaregistry.TypeInt:     "int32",
+	schemaregistry.TypeLong:    "int64",
+	schemaregistry.TypeString:  "string",
+	schemaregistry.TypeBoolean: "bool",
+	schemaregistry.TypeDouble:  "double",
+}
+
+func main() {
+	schemaDir := flag.String("schemas", "schemas", "direktori file .avsc")
+	registryDir := flag.String("registry", "schemas/registry", "direktori schema registry")
+	goOut := flag.String("out", "internal/event/types_gen.go", "file Go yang dihasilkan")
+	protoOut := flag.String("proto", "schemas/proto/events.proto", "file .proto yang dihasilkan")
+	pkg := flag.String("package", "event", "nama package Go")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join(*schemaDir, "*.avsc"))
+	if err != nil {
+		log.Fatalf("❌ Gagal membaca schema: %v", err)
+	}
+	sort.Strings(files)
+
+	registry, err := schemaregistry.Open(*registryDir)
+	if err != nil {
+		log.Fatalf("❌ Gagal membuka schema registry: %v", err)
+	}
+
+	var schemas []*schemaregistry.Schema
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("❌ Gagal membaca %s: %v", file, err)
+		}
+		s, err := schemaregistry.Parse(raw)
+		if err != nil {
+			log.Fatalf("❌ Schema %s tidak valid: %v", file, err)
+		}
+		version, err := registry.Register(s)
+		if err != nil {
+			log.Fatalf("❌ Schema %s ditolak registry: %v", file, err)
+		}
+		log.Printf("✅ %s terdaftar sebagai v%d", s.FullName(), version)
+		schemas = append(schemas, s)
+	}
+
+	if err := writeGo(*goOut, *pkg, schemas); err != nil {
+		log.Fatalf("❌ Gagal menulis %s: %v", *goOut, err)
+	}
+	if err := writeProto(*protoOut, schemas); err != nil {
+		log.Fatalf("❌ Gagal menulis %s: %v", *protoOut, err)
+	}
+}
+
+func writeGo(path, pkg string, schemas []*schemaregistry.Schema) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by eventgen from schemas/*.avsc. DO NOT EDIT.\n\npackage %s\n", pkg)
+
+	for _, s := range schemas {
+		b.WriteString("\n")
+		if s.Doc != "" {
+			fmt.Fprintf(&b, "// %s %s\n", s.Name, s.Doc)
+		}
+		fmt.Fprintf(&b, "type %s struct {\n", s.Name)
+		for _, f := range s.Fields {
+			tag := f.Name
+			// bool tetap dikirim walau false supaya consumer bisa membedakan dengan field hilang
+			if f.Type != schemaregistry.TypeBoolean {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:%q`\n", goName(f.Name), goTypes[f.Type], tag)
+		}
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "// %sSchema adalah schema Avro %s yang terdaftar di registry\n", s.Name, s.FullName())
+		fmt.Fprintf(&b, "const %sSchema = `%s`\n", s.Name, s.Canonical())
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0o644)
+}
+
+func writeProto(path string, schemas []*schemaregistry.Schema) error {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by eventgen from schemas/*.avsc. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n")
+	if len(schemas) > 0 && schemas[0].Namespace != "" {
+		fmt.Fprintf(&b, "\npackage %s;\n", schemas[0].Namespace)
+	}
+
+	for _, s := range schemas {
+		b.WriteString("\n")
+		if s.Doc != "" {
+			fmt.Fprintf(&b, "// %s %s\n", s.Name, s.Doc)
+		}
+		fmt.Fprintf(&b, "message %s {\n", s.Name)
+		for _, f := range s.Fields {
+			fmt.Fprintf(&b, "  %s %s = %d;\n", protoTypes[f.Type], f.Name, f.FieldID)
+		}
+		b.WriteString("}\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// goName mengubah snake_case ke nama field Go, contoh ai_enabled -> AIEnabled
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, p := range parts {
+		if initialisms[p] {
+			parts[i] = strings.ToUpper(p)
+		} else if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
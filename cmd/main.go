@@ -2,29 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
-	"sync/atomic"
 	"syscall"
 	"time"
+	// Timezone preferensi notifikasi tetap bisa dibaca di image runtime tanpa paket tzdata
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 
-	"go-crud/config"
 	"go-crud/delivery"
+	"go-crud/internal/aireview"
+	cbreaker "go-crud/internal/circuitbreaker"
+	"go-crud/internal/eventbus"
+	"go-crud/internal/gitwebhook"
 	"go-crud/internal/kafka"
+	"go-crud/internal/notifier"
 	"go-crud/internal/repository"
+	"go-crud/internal/review"
 	"go-crud/internal/tracing"
 	"go-crud/internal/usecase"
+	"go-crud/internal/webhookclient"
 )
 
-var ongoingRequests int32
-var wg sync.WaitGroup
-
 func main() {
 	// Load .env file (penting kalau jalan secara lokal di luar Docker)
 	err := godotenv.Load()
@@ -32,90 +37,102 @@ func main() {
 		log.Println("⚠️  .env file not found, relying on system environment variables")
 	}
 
-	// Init tracer
-	shutdownTracer := tracing.InitTracer("go-crud")
-	defer shutdownTracer()
-
-	// Init PostgreSQL
-	config.InitDB()
-	defer config.CloseDB()
-
-	// Init Redis
-	config.InitRedis()
-	defer config.CloseRedis()
+	backendKind := flag.String("backend", backendPostgres, "backend penyimpanan: postgres (Postgres, Redis, MongoDB) atau memory")
+	flag.Parse()
 
-	kafkaBroker := os.Getenv("KAFKA_BROKER")
-	fmt.Println("🔥 Kafka broker dari env:", kafkaBroker)
-// Init Kafka Producer
-	kafkaProducer, err := kafka.NewKafkaProducer(kafkaBroker)
-	if err != nil {
-		log.Fatalf("❌ Failed to create Kafka producer: %v", err)
-	}
-
-// Kirim dummy event untuk memicu auto-create topik
-	dummyEvent := map[string]interface{}{
-		"event": "user.created",
-		"name":  "dummy",
-		"email": "dummy@example.com",
+	// Init tracer (backend memory tidak butuh Jaeger kecuali JAEGER_ENDPOINT di-set)
+	if *backendKind != backendMemory || os.Getenv("JAEGER_ENDPOINT") != "" {
+		shutdownTracer := tracing.InitTracer("go-crud")
+		defer shutdownTracer()
 	}
 
-	err = kafkaProducer.Publish("user-events", dummyEvent, "user.created")
+	// Init repository sesuai backend
+	store, err := newBackend(*backendKind)
 	if err != nil {
-		log.Fatalf("❌ Failed to publish dummy event: %v", err)
+		log.Fatalf("❌ Failed to init %s backend: %v", *backendKind, err)
 	}
+	defer store.cleanup()
 
-	log.Println("✅ Dummy message sent to Kafka topic: user-events")
-
-	defer kafkaProducer.Close()
+	// Init event bus (Kafka, atau in-memory jika backend memory / EVENT_BUS=memory)
+	bus := newEventBus(*backendKind)
+	defer bus.Close()
 
+	// Dedupe event yang dikirim ulang broker
+	processedEventRepo := repository.NewProcessedEventRepository(store.cache, 7*24*time.Hour)
 
+	userPublisher := eventbus.NewUserPublisher(bus)
 
-	// Ambil URI dan DB name dari env
-	mongoURI := os.Getenv("MONGO_URI")
-	mongoDBName := os.Getenv("MONGO_DB_NAME")
-
-	if mongoURI == "" || mongoDBName == "" {
-		log.Fatal("❌ MONGO_URI or MONGO_DB_NAME not set in environment variables")
+	// Channel notifikasi (Telegram, Slack, webhook, email) dan routing per topic dari env
+	notifications, err := notifier.RouterFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Gagal menyiapkan notifier: %v", err)
+	}
+	log.Println("📣 Channel notifikasi:", notifications.Channels())
+
+	// Alert circuit breaker lewat notifier, dengan dedupe, rate limit, digest flapping dan resolved
+	breakerAlerts := cbreaker.NewAlerter(notifications, cbreaker.AlertConfigFromEnv())
+
+	userUC := usecase.NewUserUsecase(store.userRepo, store.cache, userPublisher, breakerAlerts)
+	repoUC := usecase.NewRepositoryUsecase(store.repoRepo, store.userRepo, store.cache, breakerAlerts)
+	// Analyzer code review bawaan, repository memilih yang aktif lewat PUT /repositories/{id}/analyzers
+	analyzers := usecase.NewAnalyzerRegistry()
+	for _, a := range review.BuiltinAnalyzers() {
+		if err := analyzers.Register(a); err != nil {
+			log.Fatalf("❌ Gagal mendaftarkan analyzer: %v", err)
+		}
 	}
 
-	// Init MongoDB
-	mongoClient, mongoDB, mongoCleanup, err := config.InitMongoDB(mongoURI, mongoDBName)
-
+	// Reviewer AI untuk repository dengan ai_enabled, default stub lokal (AI_PROVIDER=openai untuk endpoint asli)
+	aiReviewer, err := aireview.NewFromEnv()
 	if err != nil {
-		log.Fatal("❌ Failed to connect to MongoDB:", err)
+		log.Fatalf("❌ Gagal menyiapkan AI reviewer: %v", err)
 	}
-	defer mongoCleanup()
+	log.Println("🤖 AI reviewer:", aiReviewer.Name())
 
-	// Inisialisasi repository dan usecase
-	_ = repository.NewAuditLogMongoRepository(mongoDB)
-	userRepo := repository.NewUserRepository(config.DBPool)
-	repoRepo := repository.NewRepositoryRepository(config.DBPool)
-	codeReviewRepo := repository.NewCodeReviewRepository(config.DBPool)
-	// auditRepo := repository.NewAuditLogMongoRepository(mongoDB)
+	// Subscription webhook keluar, menerima event user, repository dan review yang selesai
+	webhookCfg := usecase.WebhookWorkerConfigFromEnv()
+	webhookUC := usecase.NewWebhookUsecase(store.webhookRepo, webhookCfg.AllowPrivate)
+	// Notifikasi review selesai ke pemilik repository sesuai preferensinya
+	notificationUC := usecase.NewNotificationUsecase(store.notifyPrefs, store.userRepo, notifications, usecase.PublicBaseURLFromEnv())
 
-	userPublisher := kafka.NewKafkaUserPublisher(kafkaProducer.Producer)
+	codeReviewUC := usecase.NewCodeReviewUsecase(store.codeReviewRepo, store.repoRepo, store.reviewJobRepo, store.reviewEvents, store.analyzerRepo, review.NewGitCheckout(), review.NewEngine(), analyzers, aiReviewer, store.aiUsageRepo, usecase.AIReviewConfigFromEnv(), webhookUC, notificationUC)
+	// Webhook git dari GitHub, GitLab dan Gitea memicu review incremental
+	gitWebhookUC := usecase.NewGitWebhookUsecase(store.repoRepo, store.webhookSecrets, codeReviewUC, gitwebhook.Providers())
+	commandUC := usecase.NewCommandUsecase(store.commandRepo)
 
-	userUC := usecase.NewUserUsecase(userRepo, config.RedisClient, userPublisher)
-	repoUC := usecase.NewRepositoryUsecase(repoRepo, userRepo, config.RedisClient)
-	codeReviewUC := usecase.NewCodeReviewUsecase(codeReviewRepo, &wg)
-
-	// Init Kafka Consumer (user + repository events)
-	kafkaConsumer, err := kafka.NewKafkaConsumer(kafkaBroker, "crud-group", "user-events", userUC, repoUC)
-	if err != nil {
-		log.Fatalf("❌ Failed to start Kafka consumer: %v", err)
-	}
+	// Init event consumer (user + repository events)
+	eventConsumer := eventbus.NewConsumer(bus, []string{"user-events", "repository-events"}, userUC, repoUC, store.repoRepo, commandUC, store.deadLetterRepo, processedEventRepo, webhookUC)
 
 	// Context untuk shutdown consumer
 	ctxConsumer, cancelConsumer := context.WithCancel(context.Background())
 	defer cancelConsumer()
 
-	// Jalankan Kafka consumer di background
+	// Jalankan event consumer di background
+	go func() {
+		eventConsumer.Start(ctxConsumer)
+	}()
+
+	// Relay outbox -> event bus, berhenti bersama consumer
+	outboxRelay := eventbus.NewOutboxRelay(store.outboxRepo, bus)
+	go outboxRelay.Start(ctxConsumer)
+
+	// Digest flapping dan pesan resolved circuit breaker
+	go breakerAlerts.Start(ctxConsumer)
+
+	// Pengiriman webhook keluar dengan retry, berhenti bersama consumer
+	webhookWorker := usecase.NewWebhookDeliveryWorker(store.webhookRepo, webhookclient.NewHTTPSender(webhookCfg.Timeout, webhookCfg.AllowPrivate), webhookCfg)
+	go webhookWorker.Start(ctxConsumer)
+
+	// Worker pool code review, mengambil job dari antrean review_jobs
+	reviewPool := usecase.NewReviewWorkerPool(store.reviewJobRepo, codeReviewUC, usecase.ReviewWorkerConfigFromEnv())
+	reviewPoolDone := make(chan struct{})
 	go func() {
-		kafkaConsumer.Start(ctxConsumer)
+		reviewPool.Start(ctxConsumer)
+		close(reviewPoolDone)
 	}()
 
 	// Inisialisasi router
-	router := delivery.NewRouter(userUC, repoUC, codeReviewUC, config.DBPool, config.RedisClient, mongoClient)
+	router := delivery.NewRouter(userUC, repoUC, codeReviewUC, gitWebhookUC, webhookUC, notificationUC, commandUC, store.auditRepo, store.outboxRepo, store.deadLetterRepo, store.healthChecks)
 
 	// Jalankan server HTTP
 	port := "8080"
@@ -139,17 +156,19 @@ func main() {
 	fmt.Println("\n🛑 Menutup server...")
 
 	cancelConsumer()
-	fmt.Println("🛑 Kafka consumer dihentikan")
-
-	// Tunggu semua code review selesai
-	for atomic.LoadInt32(&ongoingRequests) > 0 {
-		fmt.Printf("⚠️  Menunggu %d proses code review selesai...\n", atomic.LoadInt32(&ongoingRequests))
-		time.Sleep(1 * time.Second)
+	fmt.Println("🛑 Event consumer dihentikan")
+
+	// Worker berhenti mengambil job baru, review yang sedang berjalan ditunggu selesai.
+	// Lewat batas waktu, review dihentikan dan job dikembalikan ke antrean dengan checkpoint-nya.
+	select {
+	case <-reviewPoolDone:
+		fmt.Println("✅ Semua code review selesai, melanjutkan shutdown server...")
+	case <-time.After(reviewPool.ShutdownTimeout()):
+		fmt.Println("⚠️  Code review belum selesai, menyimpan checkpoint dan mengembalikan job ke antrean...")
+		reviewPool.Halt()
+		<-reviewPoolDone
 	}
 
-	wg.Wait()
-	fmt.Println("✅ Semua code review selesai, melanjutkan shutdown server...")
-
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -159,3 +178,27 @@ func main() {
 
 	fmt.Println("✅ Server berhasil dimatikan")
 }
+
+// newEventBus memilih event bus dari EVENT_BUS (backend memory selalu memakai bus
+// in-memory). Bus in-memory hanya dipakai jika diminta eksplisit: relay outbox akan menandai
+// event terkirim ke bus di proses ini saja, instance lain dan consumer luar tidak menerimanya.
+// Jadi Kafka yang gagal diinisialisasi atau schema registry yang tidak cocok menghentikan startup.
+func newEventBus(backendKind string) eventbus.EventBus {
+	if backendKind == backendMemory || eventbus.KindFromEnv() == eventbus.KindMemory {
+		log.Println("🧠 Memakai in-memory event bus")
+		return eventbus.NewMemoryBus()
+	}
+
+	kafkaBroker := os.Getenv("KAFKA_BROKER")
+	fmt.Println("🔥 Kafka broker dari env:", kafkaBroker)
+
+	bus, err := kafka.NewKafkaBus(kafkaBroker, "crud-group")
+	if errors.Is(err, kafka.ErrSchemaRegistry) {
+		log.Fatalf("❌ %v", err)
+	}
+	if err != nil {
+		log.Fatalf("❌ Kafka tidak tersedia: %v (set EVENT_BUS=memory untuk jalan tanpa broker)", err)
+	}
+
+	return bus
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"go-crud/internal/tracing"
+	"go-crud/internal/usecase"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type CommandHandler struct {
+	CommandUC usecase.ICommandUsecase
+}
+
+func NewCommandHandler(commandUC usecase.ICommandUsecase) *CommandHandler {
+	return &CommandHandler{CommandUC: commandUC}
+}
+
+// Get Command status (GET /commands/{id})
+func (h *CommandHandler) GetCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := tracing.Tracer.Start(ctx, "CommandHandler.GetCommand")
+	defer span.End()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("command.id", id))
+
+	cmd, err := h.CommandUC.GetCommand(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, repository.ErrCommandNotFound) {
+			http.Error(w, "Command not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch command", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cmd)
+}
+
+// writeAccepted menulis response 202 beserta command ID dan header Location
+func writeAccepted(w http.ResponseWriter, message string, cmd *entity.Command) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/commands/"+cmd.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    message,
+		"command_id": cmd.ID,
+	})
+}
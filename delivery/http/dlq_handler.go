@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"go-crud/internal/repository"
+	"go-crud/internal/tracing"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type DeadLetterHandler struct {
+	DeadLetterRepo repository.DeadLetterRepository
+}
+
+func NewDeadLetterHandler(deadLetterRepo repository.DeadLetterRepository) *DeadLetterHandler {
+	return &DeadLetterHandler{DeadLetterRepo: deadLetterRepo}
+}
+
+type replayRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// List DLQ messages (GET /admin/dlq?status=pending&limit=50)
+func (h *DeadLetterHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := tracing.Tracer.Start(ctx, "DeadLetterHandler.GetDeadLetters")
+	defer span.End()
+
+	status := r.URL.Query().Get("status")
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	span.SetAttributes(
+		attribute.String("dlq.status", status),
+		attribute.Int("dlq.limit", limit),
+	)
+
+	letters, err := h.DeadLetterRepo.GetDeadLetters(ctx, status, limit)
+	if err != nil {
+		span.RecordError(err)
+		http.Error(w, "Failed to fetch dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letters)
+}
+
+// Replay DLQ messages ke topic asal (POST /admin/dlq/replay)
+func (h *DeadLetterHandler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := tracing.Tracer.Start(ctx, "DeadLetterHandler.ReplayDeadLetters")
+	defer span.End()
+
+	var req replayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	span.SetAttributes(attribute.Int("dlq.replay_count", len(req.IDs)))
+
+	// Hasil per ID supaya satu kegagalan tidak membatalkan yang lain
+	results := make(map[string]string, len(req.IDs))
+	for _, id := range req.IDs {
+		key := strconv.FormatInt(id, 10)
+		err := h.DeadLetterRepo.Replay(ctx, id)
+		switch {
+		case err == nil:
+			results[key] = "replayed"
+		case errors.Is(err, repository.ErrDeadLetterNotFound):
+			results[key] = "not found"
+		case errors.Is(err, repository.ErrDeadLetterReplayed):
+			results[key] = "already replayed"
+		case errors.Is(err, repository.ErrDeadLetterUndecodable):
+			results[key] = "undecodable payload"
+		default:
+			span.RecordError(err)
+			results[key] = "failed: " + err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+	})
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"go-crud/internal/tracing"
+	"go-crud/internal/usecase"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Payload webhook lebih besar dari ini ditolak (GitHub membatasi payload di 25 MB,
+// push biasa jauh di bawahnya)
+const maxGitWebhookBody = 10 << 20
+
+type GitWebhookHandler struct {
+	GitWebhookUC usecase.IGitWebhookUsecase
+}
+
+func NewGitWebhookHandler(gitWebhookUC usecase.IGitWebhookUsecase) *GitWebhookHandler {
+	return &GitWebhookHandler{GitWebhookUC: gitWebhookUC}
+}
+
+// Terima webhook push / pull request (POST /webhooks/git/{provider}, provider github|gitlab|gitea).
+// 202 jika review masuk antrean, 200 jika event diabaikan (ping, PR ditutup, branch dihapus).
+func (h *GitWebhookHandler) ReceiveGitWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer.Start(r.Context(), "GitWebhookHandler.ReceiveGitWebhook")
+	defer span.End()
+
+	provider := chi.URLParam(r, "provider")
+	span.SetAttributes(attribute.String("webhook.provider", provider))
+
+	// Signature dihitung dari byte body asli, jadi body dibaca utuh sebelum di-decode
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGitWebhookBody))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	result, err := h.GitWebhookUC.ReceiveGitWebhook(ctx, provider, r.Header, body)
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, usecase.ErrUnknownWebhookProvider):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, usecase.ErrInvalidWebhookPayload), errors.Is(err, usecase.ErrInvalidReviewRef):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, usecase.ErrWebhookSignature):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		default:
+			log.Printf("❌ Gagal memproses webhook %s: %v", provider, err)
+			http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	span.SetAttributes(attribute.String("webhook.event", result.Event), attribute.Int("webhook.jobs", len(result.Jobs)))
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Jobs) > 0 {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Atur secret webhook repository (PUT /repositories/{id}/webhook-secret).
+// Body {"secret": "..."} boleh kosong, server lalu membuat secret acak. Secret hanya
+// ditampilkan di response ini.
+func (h *GitWebhookHandler) SetWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid repository ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := h.GitWebhookUC.SetWebhookSecret(r.Context(), repoID, input.Secret)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrRepositoryNotFound):
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		case errors.Is(err, usecase.ErrInvalidWebhookSecret):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to update webhook secret", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"secret": secret})
+}
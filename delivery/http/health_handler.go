@@ -1,27 +1,31 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"go-crud/internal/tracing"
 	"net/http"
+	"strings"
 	"sync/atomic"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// HealthCheck adalah dependency yang dicek readiness, contoh DB atau Redis
+type HealthCheck struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
 type HealthHandler struct {
-	db    *pgxpool.Pool
-	redis *redis.Client 
-	ready atomic.Bool
+	checks []HealthCheck
+	ready  atomic.Bool
 }
 
-// NewHealthHandler menerima database pool dan Redis client
-func NewHealthHandler(db *pgxpool.Pool, redisClient *redis.Client) *HealthHandler {
+// NewHealthHandler menerima daftar dependency yang harus bisa di-ping
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
 	h := &HealthHandler{
-		db:    db,
-		redis: redisClient,
+		checks: checks,
 	}
 	h.ready.Store(false)
 	return h
@@ -43,23 +47,16 @@ func (h *HealthHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
 	ctx, span := tracing.Tracer.Start(ctx, "ReadinessCheck")
 	defer span.End()
 
-	// Cek koneksi ke database
-	if err := h.db.Ping(ctx); err != nil {
-		span.RecordError(err)
-		span.SetAttributes(attribute.String("component", "database"), attribute.String("status", "unreachable"))
-		http.Error(w, "Service not ready: DB unreachable", http.StatusServiceUnavailable)
-		return
-	}
-	span.SetAttributes(attribute.String("db.status", "ok"))
-
-	// Cek koneksi ke Redis
-	if err := h.redis.Ping(ctx).Err(); err != nil {
-		span.RecordError(err)
-		span.SetAttributes(attribute.String("component", "redis"), attribute.String("status", "unreachable"))
-		http.Error(w, "Service not ready: Redis unreachable", http.StatusServiceUnavailable)
-		return
+	// Cek koneksi ke setiap dependency
+	for _, check := range h.checks {
+		if err := check.Ping(ctx); err != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("component", strings.ToLower(check.Name)), attribute.String("status", "unreachable"))
+			http.Error(w, "Service not ready: "+check.Name+" unreachable", http.StatusServiceUnavailable)
+			return
+		}
+		span.SetAttributes(attribute.String(strings.ToLower(check.Name)+".status", "ok"))
 	}
-	span.SetAttributes(attribute.String("redis.status", "ok"))
 
 	span.SetAttributes(attribute.String("service.readiness", "ready"))
 
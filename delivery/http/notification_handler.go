@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"go-crud/internal/usecase"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	NotificationUC usecase.INotificationUsecase
+}
+
+func NewNotificationHandler(notificationUC usecase.INotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{NotificationUC: notificationUC}
+}
+
+// GetNotificationPreferences (GET /users/{id}/notification-preferences), user yang belum
+// menyimpan preferensi mendapat nilai default
+func (h *NotificationHandler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	prefs, err := h.NotificationUC.GetNotificationPreferences(r.Context(), userID)
+	if err != nil {
+		writeNotificationError(w, err, "Failed to get notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// UpdateNotificationPreferences (PUT /users/{id}/notification-preferences).
+// Body {"channels": ["email"], "min_severity": "warning", "quiet_hours_start": "22:00",
+// "quiet_hours_end": "07:00", "timezone": "Asia/Jakarta"}, seluruh preferensi diganti.
+// channels kosong berarti email ke alamat user; notifikasi non-critical di jam tenang dibuang.
+func (h *NotificationHandler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var input usecase.NotificationPreferencesInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	prefs, err := h.NotificationUC.UpdateNotificationPreferences(r.Context(), userID, input)
+	if err != nil {
+		writeNotificationError(w, err, "Failed to update notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+func writeNotificationError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidNotificationPreferences):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("❌ %s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"go-crud/internal/repository"
+	"go-crud/internal/tracing"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type OutboxHandler struct {
+	OutboxRepo repository.OutboxRepository
+}
+
+func NewOutboxHandler(outboxRepo repository.OutboxRepository) *OutboxHandler {
+	return &OutboxHandler{OutboxRepo: outboxRepo}
+}
+
+// Backlog dan lag outbox relay (GET /outbox/stats)
+func (h *OutboxHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := tracing.Tracer.Start(ctx, "OutboxHandler.GetStats")
+	defer span.End()
+
+	stats, err := h.OutboxRepo.GetStats(ctx)
+	if err != nil {
+		span.RecordError(err)
+		http.Error(w, "Failed to fetch outbox stats", http.StatusInternalServerError)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("outbox.pending", stats.Pending),
+		attribute.Float64("outbox.lag_seconds", stats.LagSeconds),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -2,28 +2,32 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go-crud/internal/entity"
+	"go-crud/internal/event"
 	"go-crud/internal/tracing"
 	"go-crud/internal/usecase"
 	"go-crud/internal/validator"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/trace"
 )
 
 type RepositoryHandler struct {
 	RepoUC usecase.IRepositoryUsecase
 	Validator *validator.CustomValidator
+	CommandUC usecase.ICommandUsecase
 }
 
-func NewRepositoryHandler(repoUC usecase.IRepositoryUsecase, v *validator.CustomValidator) *RepositoryHandler {
+func NewRepositoryHandler(repoUC usecase.IRepositoryUsecase, v *validator.CustomValidator, commandUC usecase.ICommandUsecase) *RepositoryHandler {
 	return &RepositoryHandler{
 		RepoUC: repoUC,
 		Validator: v,
+		CommandUC: commandUC,
 	}
 }
 
@@ -49,7 +53,8 @@ func (h *RepositoryHandler) CreateRepository(w http.ResponseWriter, r *http.Requ
 	}
 	span.SetAttributes(attribute.Int("repository.requested_count", len(repos)))
 
-	// Insert tiap repository ke database
+	// Validasi semua repository dan pemiliknya dulu, lalu semua event disimpan dalam satu
+	// transaksi supaya tidak ada event yang terkirim sebagian
 	for i := range repos {
 		repos[i].UserID = userID
 
@@ -58,21 +63,58 @@ func (h *RepositoryHandler) CreateRepository(w http.ResponseWriter, r *http.Requ
 			http.Error(w, fmt.Sprintf("Validation failed at index %d: %s", i, err.Error()), http.StatusBadRequest)
 			return
 		}
-		
-		err = h.RepoUC.CreateRepository(ctx, &repos[i])
-		if err != nil {
-			span.RecordError(err)
-			span.AddEvent("Failed to create one of the repositories", trace.WithAttributes(
-				attribute.String("repository.name", repos[i].Name),
-			))
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := h.RepoUC.ValidateOwner(ctx, userID); err != nil {
+		span.RecordError(err)
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
 			return
 		}
+		log.Printf("❌ Failed to validate owner %d of new repositories: %v", userID, err)
+		http.Error(w, "Failed to create repository", http.StatusInternalServerError)
+		return
 	}
 
-	span.AddEvent("All repositories successfully created")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(repos)
+	// ✅ Simpan event create ke outbox, penyimpanan repository dilakukan oleh consumer
+	reqs := make([]usecase.CommandRequest, 0, len(repos))
+	for i := range repos {
+		reqs = append(reqs, usecase.CommandRequest{
+			Type:    event.TypeRepositoryCreated,
+			Topic:   "repository-events",
+			Subject: fmt.Sprintf("users/%d", userID),
+			Data: event.RepositoryData{
+				UserID:    repos[i].UserID,
+				Name:      repos[i].Name,
+				URL:       repos[i].URL,
+				AIEnabled: repos[i].AIEnabled,
+			},
+		})
+	}
+
+	cmds, err := h.CommandUC.SubmitCommands(ctx, reqs)
+	if err != nil {
+		span.RecordError(err)
+		http.Error(w, "Failed to queue create repository event", http.StatusInternalServerError)
+		return
+	}
+	commandIDs := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		commandIDs = append(commandIDs, cmd.ID)
+	}
+
+	span.AddEvent("All repository create events queued")
+
+	// Location hanya bisa menunjuk satu command, jadi hanya diisi untuk single create
+	w.Header().Set("Content-Type", "application/json")
+	if len(commandIDs) == 1 {
+		w.Header().Set("Location", "/commands/"+commandIDs[0])
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     fmt.Sprintf("Create event for %d repositories queued for Kafka", len(repos)),
+		"command_ids": commandIDs,
+	})
 }
 
 func (h *RepositoryHandler) GetAllRepositories(w http.ResponseWriter, r *http.Request) {
@@ -134,9 +176,14 @@ func (h *RepositoryHandler) GetRepositoriesByUserID(w http.ResponseWriter, r *ht
 	span.SetAttributes(attribute.Int("user.id", userID))
 
 	repos, err := h.RepoUC.GetRepositoriesByUserID(ctx, userID)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("❌ Failed to fetch repositories for user %d: %v", userID, err)
+		http.Error(w, "Failed to fetch repositories", http.StatusInternalServerError)
 		return
 	}
 
@@ -176,14 +223,21 @@ func (h *RepositoryHandler) UpdateRepository(w http.ResponseWriter, r *http.Requ
 		attribute.Bool("repository.ai_enabled", repo.AIEnabled),
 	)
 
-	updatedRepo, err := h.RepoUC.UpdateRepository(ctx, id, repo)
+	eventData := event.RepositoryData{
+		ID:        id,
+		Name:      repo.Name,
+		URL:       repo.URL,
+		AIEnabled: repo.AIEnabled,
+	}
+
+	cmd, err := h.CommandUC.SubmitCommand(ctx, event.TypeRepositoryUpdated, "repository-events", fmt.Sprintf("repositories/%d", id), eventData)
 	if err != nil {
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to queue update event", http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(updatedRepo)
+	writeAccepted(w, "Update repository event queued for Kafka", cmd)
 }
 
 func (h *RepositoryHandler) DeleteRepository(w http.ResponseWriter, r *http.Request) {
@@ -207,12 +261,20 @@ func (h *RepositoryHandler) DeleteRepository(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	span.AddEvent("Repository successfully deleted")
+	eventData := event.RepositoryData{
+		ID: id,
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": fmt.Sprintf("Repository dengan ID %d berhasil dihapus", id),
-	})
+	cmd, err := h.CommandUC.SubmitCommand(ctx, event.TypeRepositoryDeleted, "repository-events", fmt.Sprintf("repositories/%d", id), eventData)
+	if err != nil {
+		span.RecordError(err)
+		http.Error(w, "Failed to queue delete event", http.StatusInternalServerError)
+		return
+	}
+
+	span.AddEvent("Repository delete event queued")
+
+	writeAccepted(w, fmt.Sprintf("Delete repository event for ID %d queued for Kafka", id), cmd)
 }
 
 // Create Repository (POST /repositories)
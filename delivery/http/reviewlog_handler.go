@@ -1,29 +1,43 @@
 package http
 
 import (
-	"context"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/report"
+	"go-crud/internal/repository"
 	"go-crud/internal/usecase"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 
 type CodeReviewHandler struct {
 	CodeReviewUC usecase.ICodeReviewUsecase
-	Ctx          context.Context // Tambahkan context global
 }
 
-func NewCodeReviewHandler(ctx context.Context, uc usecase.ICodeReviewUsecase) *CodeReviewHandler {
+func NewCodeReviewHandler(uc usecase.ICodeReviewUsecase) *CodeReviewHandler {
 	return &CodeReviewHandler{
 		CodeReviewUC: uc,
-		Ctx:          ctx, // Simpan context global
 	}
 }
 
-// Mulai code review (long-running task)
+// startCodeReviewRequest adalah body opsional POST /repositories/{id}/codereview.
+// Dengan base, hanya file dan baris yang berubah dari base ke head yang di-review.
+type startCodeReviewRequest struct {
+	Base string `json:"base"`
+	Head string `json:"head"`
+}
+
+// Masukkan code review ke antrean, dijalankan oleh worker pool
 func (h *CodeReviewHandler) StartCodeReview(w http.ResponseWriter, r *http.Request) {
 	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
@@ -31,18 +45,198 @@ func (h *CodeReviewHandler) StartCodeReview(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Tambahkan tracking ke goroutine
-	go func() {
-		_ = h.CodeReviewUC.RunCodeReview(h.Ctx, repoID) // Gunakan context dari main.go
-	}()
+	// Body boleh kosong untuk review seluruh repository di HEAD
+	var req startCodeReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
+	job, err := h.CodeReviewUC.EnqueueCodeReview(r.Context(), repoID, req.Base, req.Head)
+	if err != nil {
+		if errors.Is(err, usecase.ErrRepositoryNotFound) {
+			http.Error(w, "Repository not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidReviewRef) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to enqueue code review", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/codereview/jobs/"+job.ID)
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": "🚀 Code review is in progress",
+		"message": "🚀 Code review is queued",
+		"job_id":  job.ID,
+		"status":  job.Status,
 	})
 }
 
-// Ambil hasil code review berdasarkan repository ID
+// Status job code review (GET /codereview/jobs/{id})
+func (h *CodeReviewHandler) GetReviewJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if uuid.Validate(jobID) != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.CodeReviewUC.GetReviewJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReviewJobNotFound) {
+			http.Error(w, "Review job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch review job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// Batalkan job code review (DELETE /codereview/jobs/{id})
+func (h *CodeReviewHandler) CancelReviewJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if uuid.Validate(jobID) != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.CodeReviewUC.CancelReviewJob(r.Context(), jobID)
+	if err != nil {
+		writeReviewJobError(w, err)
+		return
+	}
+
+	// Job running baru berhenti setelah worker melihat cancel_requested
+	w.Header().Set("Content-Type", "application/json")
+	if job.Status != entity.ReviewJobCancelled {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(job)
+}
+
+// Jalankan ulang job failed/cancelled (POST /codereview/jobs/{id}/retry)
+func (h *CodeReviewHandler) RetryReviewJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if uuid.Validate(jobID) != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.CodeReviewUC.RetryReviewJob(r.Context(), jobID)
+	if err != nil {
+		writeReviewJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/codereview/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// Interval keep-alive stream SSE, sekaligus batas tunggu satu pembacaan event
+const reviewEventKeepAlive = 15 * time.Second
+
+// Stream progres job code review sebagai Server-Sent Events (GET /codereview/jobs/{id}/events).
+// Client yang reconnect mengirim Last-Event-ID (atau ?last_event_id=) dan melanjutkan dari event berikutnya.
+// Stream ditutup setelah event completed, failed atau cancelled.
+func (h *CodeReviewHandler) StreamReviewJobEvents(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if uuid.Validate(jobID) != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.CodeReviewUC.GetReviewJob(r.Context(), jobID); err != nil {
+		writeReviewJobError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		events, err := h.CodeReviewUC.ReviewJobEvents(ctx, jobID, lastID, reviewEventKeepAlive)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("⚠️ Gagal membaca event job %s: %v", jobID, err)
+			}
+			return
+		}
+
+		if len(events) == 0 {
+			// Event terakhir bisa sudah kedaluwarsa, jangan menunggu job yang sudah selesai selamanya
+			job, err := h.CodeReviewUC.GetReviewJob(ctx, jobID)
+			if err != nil || isFinishedReviewJob(job.Status) {
+				return
+			}
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+			continue
+		}
+
+		for _, ev := range events {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+				return
+			}
+			lastID = ev.ID
+			if ev.Terminal() {
+				flusher.Flush()
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func isFinishedReviewJob(status string) bool {
+	switch status {
+	case entity.ReviewJobSucceeded, entity.ReviewJobFailed, entity.ReviewJobCancelled:
+		return true
+	}
+	return false
+}
+
+func writeReviewJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReviewJobNotFound):
+		http.Error(w, "Review job not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrReviewJobFinished), errors.Is(err, repository.ErrReviewJobNotRetryable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Failed to update review job", http.StatusInternalServerError)
+	}
+}
+
+// Ambil daftar review run (beserta ringkasan temuan) berdasarkan repository ID
 func (h *CodeReviewHandler) GetReviewLogs(w http.ResponseWriter, r *http.Request) {
 	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
@@ -50,11 +244,220 @@ func (h *CodeReviewHandler) GetReviewLogs(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	logs, err := h.CodeReviewUC.GetReviewLogs(r.Context(), repoID)
+	runs, err := h.CodeReviewUC.GetReviewRuns(r.Context(), repoID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	json.NewEncoder(w).Encode(logs)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// Detail satu review run (GET /codereview/runs/{id})
+func (h *CodeReviewHandler) GetReviewRun(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.CodeReviewUC.GetReviewRun(r.Context(), runID)
+	if err != nil {
+		writeReviewRunError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// Temuan dari satu review run (GET /codereview/runs/{id}/findings)
+func (h *CodeReviewHandler) GetReviewFindings(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	findings, err := h.CodeReviewUC.GetReviewFindings(r.Context(), runID)
+	if err != nil {
+		writeReviewRunError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(findings)
+}
+
+// Semua analyzer yang terdaftar (GET /codereview/analyzers)
+func (h *CodeReviewHandler) ListAnalyzers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.CodeReviewUC.ListAnalyzers(r.Context()))
+}
+
+// Analyzer yang aktif untuk repository (GET /repositories/{id}/analyzers)
+func (h *CodeReviewHandler) GetRepositoryAnalyzers(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid repository ID", http.StatusBadRequest)
+		return
+	}
+
+	analyzers, err := h.CodeReviewUC.GetRepositoryAnalyzers(r.Context(), repoID)
+	if err != nil {
+		writeAnalyzerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analyzers)
+}
+
+// Atur analyzer yang aktif untuk repository (PUT /repositories/{id}/analyzers).
+// Body {"analyzers": ["gofmt", "todo"]}, daftar kosong mengaktifkan semua analyzer.
+func (h *CodeReviewHandler) SetRepositoryAnalyzers(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid repository ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Analyzers []string `json:"analyzers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	analyzers, err := h.CodeReviewUC.SetRepositoryAnalyzers(r.Context(), repoID, input.Analyzers)
+	if err != nil {
+		writeAnalyzerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analyzers)
+}
+
+// Profil review default repository (GET /repositories/{id}/review-config)
+func (h *CodeReviewHandler) GetRepositoryReviewConfig(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid repository ID", http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.CodeReviewUC.GetRepositoryReviewConfig(r.Context(), repoID)
+	if err != nil {
+		writeAnalyzerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// Atur profil review default repository (PUT /repositories/{id}/review-config).
+// Body memakai format yang sama dengan .gocrud-review.yml dalam JSON, file di repository
+// tetap didahulukan jika ada. Body {} mengembalikan review ke pengaturan bawaan.
+func (h *CodeReviewHandler) SetRepositoryReviewConfig(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid repository ID", http.StatusBadRequest)
+		return
+	}
+
+	var input entity.ReviewConfig
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.CodeReviewUC.SetRepositoryReviewConfig(r.Context(), repoID, &input)
+	if err != nil {
+		writeAnalyzerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// GetReviewReport mengekspor hasil run (GET /codereview/runs/{id}/report?format=sarif|markdown|html|junit)
+func (h *CodeReviewHandler) GetReviewReport(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	contentType, ext, err := report.ContentType(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rep, err := h.CodeReviewUC.GetReviewReport(r.Context(), runID)
+	if err != nil {
+		writeReviewRunError(w, err)
+		return
+	}
+
+	// Render ke buffer dulu supaya error masih bisa dikirim sebagai 500
+	var buf bytes.Buffer
+	if err := report.Render(&buf, format, rep); err != nil {
+		log.Printf("❌ Gagal membuat laporan %s run %d: %v", format, runID, err)
+		http.Error(w, "Failed to render report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="codereview-run-%d.%s"`, runID, ext))
+	w.Write(buf.Bytes())
+}
+
+// GetAIUsage menampilkan pemakaian token AI code review user bulan ini
+func (h *CodeReviewHandler) GetAIUsage(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	usage, err := h.CodeReviewUC.GetAIUsage(r.Context(), userID)
+	if errors.Is(err, usecase.ErrAIDisabled) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get AI usage", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+func writeAnalyzerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrRepositoryNotFound):
+		http.Error(w, "Repository not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrUnknownAnalyzer), errors.Is(err, usecase.ErrInvalidReviewConfig):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to update analyzers", http.StatusInternalServerError)
+	}
+}
+
+func writeReviewRunError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrReviewRunNotFound) {
+		http.Error(w, "Review run not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "Failed to fetch review run", http.StatusInternalServerError)
 }
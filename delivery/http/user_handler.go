@@ -3,7 +3,7 @@ package http
 import (
 	"encoding/json"
 	"go-crud/internal/entity"
-	"go-crud/internal/kafka"
+	"go-crud/internal/event"
 	"go-crud/internal/repository"
 	"go-crud/internal/tracing"
 	"go-crud/internal/usecase"
@@ -21,15 +21,15 @@ type UserHandler struct {
 	UserUC usecase.IUserUsecase
 	Validator *validator.CustomValidator
 	AuditRepo   repository.AuditLogMongoRepository
-	Producer   kafka.KafkaProducer
+	CommandUC  usecase.ICommandUsecase
 }
 
-func NewUserHandler(userUC usecase.IUserUsecase, validator *validator.CustomValidator, auditRepo repository.AuditLogMongoRepository, producer kafka.KafkaProducer) *UserHandler {
+func NewUserHandler(userUC usecase.IUserUsecase, validator *validator.CustomValidator, auditRepo repository.AuditLogMongoRepository, commandUC usecase.ICommandUsecase) *UserHandler {
 	return &UserHandler{
 		UserUC: userUC,
 		Validator: validator,
 		AuditRepo: auditRepo,
-		Producer:   producer,
+		CommandUC:  commandUC,
 	}
 }
 
@@ -71,24 +71,20 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		attribute.String("user.email", user.Email),
 	)
 
-	eventData := map[string]interface{}{
-		"name":  user.Name,
-		"email": user.Email,
+	eventData := event.UserData{
+		Name:  user.Name,
+		Email: user.Email,
 	}
 
-	// ✅ Kirim ke Kafka
-	err = h.Producer.Publish("user-events", eventData, "user.created")
-
+	// ✅ Simpan command + event ke outbox, relay yang kirim ke Kafka
+	cmd, err := h.CommandUC.SubmitCommand(ctx, event.TypeUserCreated, "user-events", "", eventData)
 	if err != nil {
 		span.RecordError(err)
-		http.Error(w, "Failed to send event to Kafka", http.StatusInternalServerError)
+		http.Error(w, "Failed to queue create user event", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Create user event sent to Kafka",
-	})
+	writeAccepted(w, "Create user event queued for Kafka", cmd)
 }
 
 
@@ -188,24 +184,21 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	
 	// ✅ Siapkan event data untuk Kafka
-	eventData := map[string]interface{}{
-		"id":    id,
-		"name":  input.Name,
-		"email": input.Email,
+	eventData := event.UserData{
+		ID:    id,
+		Name:  input.Name,
+		Email: input.Email,
 	}
 
-	// ✅ Kirim event ke Kafka
-	err = h.Producer.Publish("user-events", eventData, "user.updated")
+	// ✅ Simpan event ke outbox
+	cmd, err := h.CommandUC.SubmitCommand(ctx, event.TypeUserUpdated, "user-events", fmt.Sprintf("users/%d", id), eventData)
 	if err != nil {
 		span.RecordError(err)
-		http.Error(w, "Failed to send update event to Kafka", http.StatusInternalServerError)
+		http.Error(w, "Failed to queue update event", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Update user event sent to Kafka",
-	})
+	writeAccepted(w, "Update user event queued for Kafka", cmd)
 }
 
 
@@ -229,9 +222,9 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	// 	return
 	// }
 
-		// 📦 Buat event dan kirim ke Kafka
-		eventData := map[string]interface{}{
-			"id":    id,
+		// 📦 Buat event dan simpan ke outbox
+		eventData := event.UserData{
+			ID: id,
 		}
 		// payload, err := json.Marshal(eventData)
 		// if err != nil {
@@ -240,16 +233,14 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		// 	return
 		// }
 	
-		if err := h.Producer.Publish("user-events", eventData, "user.deleted"); err != nil {
+		cmd, err := h.CommandUC.SubmitCommand(ctx, event.TypeUserDeleted, "user-events", fmt.Sprintf("users/%d", id), eventData)
+		if err != nil {
 			span.RecordError(err)
-			http.Error(w, "Failed to publish delete event", http.StatusInternalServerError)
+			http.Error(w, "Failed to queue delete event", http.StatusInternalServerError)
 			return
 		}
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": fmt.Sprintf("Delete user event for ID %d sent to Kafka", id),
-	})
+	writeAccepted(w, fmt.Sprintf("Delete user event for ID %d queued for Kafka", id), cmd)
 }
 
 func (h *UserHandler) GetUserAuditLogs(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"go-crud/internal/usecase"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookHandler struct {
+	WebhookUC usecase.IWebhookUsecase
+}
+
+func NewWebhookHandler(webhookUC usecase.IWebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{WebhookUC: webhookUC}
+}
+
+// Buat subscription webhook (POST /webhooks).
+// Body {"url": "...", "events": ["repository.*", "review.completed"], "secret": "...", "description": "..."}.
+// Secret boleh kosong dan hanya ditampilkan di response ini.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input usecase.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.WebhookUC.CreateWebhook(r.Context(), input)
+	if err != nil {
+		writeWebhookError(w, err, "Failed to create webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.WebhookUC.ListWebhooks(r.Context())
+	if err != nil {
+		writeWebhookError(w, err, "Failed to list webhooks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.WebhookUC.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, err, "Failed to get webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+// Ubah subscription webhook (PUT /webhooks/{id}), body sama dengan create ditambah "active".
+// {"active": true} mengaktifkan kembali subscription yang dinonaktifkan karena terus gagal.
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	var input usecase.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.WebhookUC.UpdateWebhook(r.Context(), id, input)
+	if err != nil {
+		writeWebhookError(w, err, "Failed to update webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhookUC.DeleteWebhook(r.Context(), id); err != nil {
+		writeWebhookError(w, err, "Failed to delete webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Log pengiriman terbaru subscription (GET /webhooks/{id}/deliveries?limit=50)
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.WebhookUC.ListWebhookDeliveries(r.Context(), id, limit)
+	if err != nil {
+		writeWebhookError(w, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("❌ %s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -1,36 +1,35 @@
 package delivery
 
 import (
-	"context"
 	deliveryHTTP "go-crud/delivery/http"
-	"go-crud/internal/kafka"
 	"go-crud/internal/repository"
 	"go-crud/internal/usecase"
 	"go-crud/internal/validator"
-	"log"
-	"os"
-
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 )
 
-func NewRouter(userUC usecase.IUserUsecase, repoUC usecase.IRepositoryUsecase, codeReviewUC usecase.ICodeReviewUsecase,dbPool *pgxpool.Pool, redisClient *redis.Client, mongoClient *mongo.Client) *chi.Mux {
+// NewRouter hanya bergantung pada interface, jadi bisa dipakai backend Postgres maupun in-memory
+func NewRouter(
+	userUC usecase.IUserUsecase,
+	repoUC usecase.IRepositoryUsecase,
+	codeReviewUC usecase.ICodeReviewUsecase,
+	gitWebhookUC usecase.IGitWebhookUsecase,
+	webhookUC usecase.IWebhookUsecase,
+	notificationUC usecase.INotificationUsecase,
+	commandUC usecase.ICommandUsecase,
+	auditRepo repository.AuditLogMongoRepository,
+	outboxRepo repository.OutboxRepository,
+	deadLetterRepo repository.DeadLetterRepository,
+	healthChecks []deliveryHTTP.HealthCheck,
+) *chi.Mux {
 	r := chi.NewRouter()
 // ✅ Inisialisasi validator
 	validator := validator.NewValidator()
-	auditRepo := repository.NewAuditLogMongoRepository(mongoClient.Database("audit_log_db"))
-	broker := os.Getenv("KAFKA_BROKER")
-	kafkaProducer, err := kafka.NewKafkaProducer(broker)
-	if err != nil {
-		log.Fatalf("❌ Failed to initialize Kafka producer: %v", err)
-	}
 
 
 	// ✅ Inject ke handler
-	userHandler := deliveryHTTP.NewUserHandler(userUC, validator, auditRepo, *kafkaProducer)
+	userHandler := deliveryHTTP.NewUserHandler(userUC, validator, auditRepo, commandUC)
 
 	r.Post("/users", userHandler.CreateUser)
 	r.Get("/users", userHandler.GetAllUsers)
@@ -39,7 +38,7 @@ func NewRouter(userUC usecase.IUserUsecase, repoUC usecase.IRepositoryUsecase, c
 	r.Delete("/users/{id}", userHandler.DeleteUser)
 
 	// Repository handler
-	repoHandler := deliveryHTTP.NewRepositoryHandler(repoUC, validator)
+	repoHandler := deliveryHTTP.NewRepositoryHandler(repoUC, validator, commandUC)
 	r.Post("/users/{id}/repositories", repoHandler.CreateRepository)
 	r.Get("/users/{id}/repositories", repoHandler.GetRepositoriesByUserID)
 	r.Get("/repositories/{id}", repoHandler.GetRepositoryByID)
@@ -47,17 +46,62 @@ func NewRouter(userUC usecase.IUserUsecase, repoUC usecase.IRepositoryUsecase, c
 	r.Put("/repositories/{id}", repoHandler.UpdateRepository)
 	r.Delete("/repositories/{id}", repoHandler.DeleteRepository)
 
-	codeReviewHandler := deliveryHTTP.NewCodeReviewHandler(context.Background(),codeReviewUC)
+	// Status command async (hasil 202 dari write endpoint)
+	commandHandler := deliveryHTTP.NewCommandHandler(commandUC)
+	r.Get("/commands/{id}", commandHandler.GetCommand)
+
+	// Monitoring outbox relay
+	outboxHandler := deliveryHTTP.NewOutboxHandler(outboxRepo)
+	r.Get("/outbox/stats", outboxHandler.GetStats)
+
+	// Admin DLQ
+	dlqHandler := deliveryHTTP.NewDeadLetterHandler(deadLetterRepo)
+	r.Get("/admin/dlq", dlqHandler.GetDeadLetters)
+	r.Post("/admin/dlq/replay", dlqHandler.ReplayDeadLetters)
+
+	codeReviewHandler := deliveryHTTP.NewCodeReviewHandler(codeReviewUC)
 	r.Post("/repositories/{id}/codereview", codeReviewHandler.StartCodeReview)
+	r.Get("/codereview/jobs/{id}", codeReviewHandler.GetReviewJob)
+	r.Delete("/codereview/jobs/{id}", codeReviewHandler.CancelReviewJob)
+	r.Post("/codereview/jobs/{id}/retry", codeReviewHandler.RetryReviewJob)
+	r.Get("/codereview/jobs/{id}/events", codeReviewHandler.StreamReviewJobEvents)
 	r.Get("/repositories/{id}/codereview/logs", codeReviewHandler.GetReviewLogs)
+	r.Get("/codereview/runs/{id}", codeReviewHandler.GetReviewRun)
+	r.Get("/codereview/runs/{id}/findings", codeReviewHandler.GetReviewFindings)
+	r.Get("/codereview/runs/{id}/report", codeReviewHandler.GetReviewReport)
+	r.Get("/codereview/analyzers", codeReviewHandler.ListAnalyzers)
+	r.Get("/repositories/{id}/analyzers", codeReviewHandler.GetRepositoryAnalyzers)
+	r.Put("/repositories/{id}/analyzers", codeReviewHandler.SetRepositoryAnalyzers)
+	r.Get("/repositories/{id}/review-config", codeReviewHandler.GetRepositoryReviewConfig)
+	r.Put("/repositories/{id}/review-config", codeReviewHandler.SetRepositoryReviewConfig)
+	r.Get("/users/{id}/ai-usage", codeReviewHandler.GetAIUsage)
 
-	// Health Check Handler (Sekarang menerima dbPool & Redis)
-	healthHandler := deliveryHTTP.NewHealthHandler(dbPool, redisClient)
+	// Webhook git (push / pull request) memicu review incremental
+	gitWebhookHandler := deliveryHTTP.NewGitWebhookHandler(gitWebhookUC)
+	r.Post("/webhooks/git/{provider}", gitWebhookHandler.ReceiveGitWebhook)
+	r.Put("/repositories/{id}/webhook-secret", gitWebhookHandler.SetWebhookSecret)
+
+	// Subscription webhook keluar (user, repository dan review) beserta log pengirimannya
+	webhookHandler := deliveryHTTP.NewWebhookHandler(webhookUC)
+	r.Post("/webhooks", webhookHandler.CreateWebhook)
+	r.Get("/webhooks", webhookHandler.ListWebhooks)
+	r.Get("/webhooks/{id}", webhookHandler.GetWebhook)
+	r.Put("/webhooks/{id}", webhookHandler.UpdateWebhook)
+	r.Delete("/webhooks/{id}", webhookHandler.DeleteWebhook)
+	r.Get("/webhooks/{id}/deliveries", webhookHandler.ListWebhookDeliveries)
+
+	// Health Check Handler (dependency yang dicek tergantung backend)
+	healthHandler := deliveryHTTP.NewHealthHandler(healthChecks...)
 	r.Get("/health/liveness", healthHandler.LivenessCheck)
 	r.Get("/health/readiness", healthHandler.ReadinessCheck)
 
 	r.Get("/users/{id}/audit-logs", userHandler.GetUserAuditLogs)
 
+	// Preferensi notifikasi user (channel, severity minimum, jam tenang)
+	notificationHandler := deliveryHTTP.NewNotificationHandler(notificationUC)
+	r.Get("/users/{id}/notification-preferences", notificationHandler.GetNotificationPreferences)
+	r.Put("/users/{id}/notification-preferences", notificationHandler.UpdateNotificationPreferences)
+
 
 	return r
 }
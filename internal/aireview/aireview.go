@@ -0,0 +1,119 @@
+// Package aireview berisi implementasi port.AIReviewer: client endpoint chat completions
+// OpenAI-compatible dan stub deterministik supaya review AI tetap bisa jalan offline.
+package aireview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderStub   = "stub"
+	ProviderOpenAI = "openai"
+
+	// Perkiraan kasar jumlah karakter per token untuk kode dan teks Inggris
+	charsPerToken = 4
+
+	defaultBaseURL             = "https://api.openai.com/v1"
+	defaultModel               = "gpt-4o-mini"
+	defaultMaxCompletionTokens = 800
+	defaultTimeout             = 60 * time.Second
+)
+
+var ErrUnknownProvider = errors.New("unknown AI provider")
+
+// NewFromEnv memilih reviewer dari AI_PROVIDER: stub (default) atau openai.
+// Provider openai membaca AI_BASE_URL, AI_API_KEY, AI_MODEL, AI_MAX_COMPLETION_TOKENS dan AI_TIMEOUT.
+// Template prompt bisa diganti dengan file di AI_PROMPT_DIR.
+func NewFromEnv() (port.AIReviewer, error) {
+	prompts, err := LoadPrompts(os.Getenv("AI_PROMPT_DIR"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch provider := strings.ToLower(os.Getenv("AI_PROVIDER")); provider {
+	case "", ProviderStub:
+		return NewStubReviewer(prompts), nil
+	case ProviderOpenAI:
+		cfg := OpenAIConfig{
+			BaseURL:             os.Getenv("AI_BASE_URL"),
+			APIKey:              os.Getenv("AI_API_KEY"),
+			Model:               os.Getenv("AI_MODEL"),
+			MaxCompletionTokens: defaultMaxCompletionTokens,
+			Timeout:             defaultTimeout,
+		}
+		if n, err := strconv.Atoi(os.Getenv("AI_MAX_COMPLETION_TOKENS")); err == nil && n > 0 {
+			cfg.MaxCompletionTokens = n
+		}
+		if d, err := time.ParseDuration(os.Getenv("AI_TIMEOUT")); err == nil && d > 0 {
+			cfg.Timeout = d
+		}
+		return NewOpenAIReviewer(cfg, prompts), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, provider)
+	}
+}
+
+// EstimateTokens memperkirakan jumlah token teks tanpa tokenizer model
+func EstimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// commentsResponse adalah format JSON jawaban model yang diminta di system prompt
+type commentsResponse struct {
+	Comments []struct {
+		Line       int    `json:"line"`
+		EndLine    int    `json:"end_line"`
+		Severity   string `json:"severity"`
+		Message    string `json:"message"`
+		Suggestion string `json:"suggestion"`
+	} `json:"comments"`
+}
+
+// parseComments membaca jawaban model. Model sering membungkus JSON dengan ```json
+// atau kalimat pembuka, jadi yang dibaca adalah objek dari '{' pertama sampai '}' terakhir.
+func parseComments(content string) ([]port.AIComment, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("model response is not JSON: %.100q", content)
+	}
+
+	var resp commentsResponse
+	if err := json.Unmarshal([]byte(content[start:end+1]), &resp); err != nil {
+		return nil, fmt.Errorf("decode model response: %w", err)
+	}
+
+	comments := make([]port.AIComment, 0, len(resp.Comments))
+	for _, c := range resp.Comments {
+		if strings.TrimSpace(c.Message) == "" {
+			continue
+		}
+		comments = append(comments, port.AIComment{
+			Line:       c.Line,
+			EndLine:    c.EndLine,
+			Severity:   normalizeSeverity(c.Severity),
+			Message:    strings.TrimSpace(c.Message),
+			Suggestion: strings.TrimSpace(c.Suggestion),
+		})
+	}
+	return comments, nil
+}
+
+// normalizeSeverity memetakan severity bebas dari model ke severity temuan
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "error", "critical", "high", "bug", "security":
+		return entity.SeverityError
+	case "info", "low", "note", "nit", "suggestion":
+		return entity.SeverityInfo
+	default:
+		return entity.SeverityWarning
+	}
+}
@@ -0,0 +1,151 @@
+package aireview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/usecase/port"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Batas body error dari endpoint yang disalin ke pesan error
+const maxErrorBody = 512
+
+// OpenAIConfig mengatur endpoint chat completions OpenAI-compatible
+// (OpenAI, Azure OpenAI proxy, Ollama, vLLM, LM Studio, dll.)
+type OpenAIConfig struct {
+	// BaseURL tanpa /chat/completions, contoh http://localhost:11434/v1
+	BaseURL string
+	// APIKey boleh kosong untuk server lokal
+	APIKey              string
+	Model               string
+	MaxCompletionTokens int
+	Timeout             time.Duration
+}
+
+// OpenAIReviewer mengirim hunk ke POST {BaseURL}/chat/completions
+type OpenAIReviewer struct {
+	cfg     OpenAIConfig
+	prompts *Prompts
+	client  *http.Client
+}
+
+func NewOpenAIReviewer(cfg OpenAIConfig, prompts *Prompts) *OpenAIReviewer {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.Model == "" {
+		cfg.Model = defaultModel
+	}
+	if cfg.MaxCompletionTokens <= 0 {
+		cfg.MaxCompletionTokens = defaultMaxCompletionTokens
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &OpenAIReviewer{cfg: cfg, prompts: prompts, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (r *OpenAIReviewer) Name() string {
+	return ProviderOpenAI + ":" + r.cfg.Model
+}
+
+// EstimateTokens menghitung prompt ditambah batas token jawaban (kasus terburuk)
+func (r *OpenAIReviewer) EstimateTokens(hunk port.AIHunk) int {
+	system, user, err := r.prompts.Render(hunk)
+	if err != nil {
+		return r.cfg.MaxCompletionTokens
+	}
+	return EstimateTokens(system) + EstimateTokens(user) + r.cfg.MaxCompletionTokens
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (r *OpenAIReviewer) Review(ctx context.Context, hunk port.AIHunk) (*port.AIReviewResult, error) {
+	system, user, err := r.prompts.Render(hunk)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: r.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		MaxTokens: r.cfg.MaxCompletionTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.APIKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("AI endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("decode AI response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return nil, fmt.Errorf("AI response has no choices")
+	}
+
+	content := chat.Choices[0].Message.Content
+	comments, err := parseComments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Server yang tidak mengisi usage dihitung dengan perkiraan
+	tokens := chat.Usage.TotalTokens
+	if tokens == 0 {
+		tokens = chat.Usage.PromptTokens + chat.Usage.CompletionTokens
+	}
+	if tokens == 0 {
+		tokens = EstimateTokens(system) + EstimateTokens(user) + EstimateTokens(content)
+	}
+	return &port.AIReviewResult{Comments: comments, Tokens: tokens}, nil
+}
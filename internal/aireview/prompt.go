@@ -0,0 +1,94 @@
+package aireview
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go-crud/internal/usecase/port"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Template prompt bawaan, bisa diganti lewat AI_PROMPT_DIR
+//
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+// Prompts berisi template system prompt dan prompt per hunk
+type Prompts struct {
+	system *template.Template
+	review *template.Template
+}
+
+// promptData adalah data yang tersedia di template
+type promptData struct {
+	File      string
+	Language  string
+	StartLine int
+	EndLine   int
+	// Code berisi baris hunk dengan nomor baris di depannya
+	Code string
+}
+
+// LoadPrompts membaca system.tmpl dan review.tmpl dari dir. File yang tidak ada di dir
+// (atau dir kosong) memakai template bawaan.
+func LoadPrompts(dir string) (*Prompts, error) {
+	system, err := loadTemplate(dir, "system.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	review, err := loadTemplate(dir, "review.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &Prompts{system: system, review: review}, nil
+}
+
+func loadTemplate(dir, name string) (*template.Template, error) {
+	if dir != "" {
+		text, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return template.New(name).Option("missingkey=error").Parse(string(text))
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("read prompt %s: %w", name, err)
+		}
+	}
+	return template.New(name).Option("missingkey=error").ParseFS(defaultPrompts, "prompts/"+name)
+}
+
+// Render menghasilkan system prompt dan prompt untuk satu hunk
+func (p *Prompts) Render(hunk port.AIHunk) (system, user string, err error) {
+	data := promptData{
+		File:      hunk.File,
+		Language:  hunk.Language,
+		StartLine: hunk.StartLine,
+		EndLine:   hunk.EndLine(),
+		Code:      numberLines(hunk),
+	}
+
+	var sb strings.Builder
+	if err := p.system.Execute(&sb, data); err != nil {
+		return "", "", fmt.Errorf("render system prompt: %w", err)
+	}
+	system = sb.String()
+
+	sb.Reset()
+	if err := p.review.Execute(&sb, data); err != nil {
+		return "", "", fmt.Errorf("render review prompt: %w", err)
+	}
+	return system, sb.String(), nil
+}
+
+// numberLines menulis "  12 | kode" per baris supaya model bisa menyebut nomor baris file
+func numberLines(hunk port.AIHunk) string {
+	width := len(fmt.Sprint(hunk.EndLine()))
+	var sb strings.Builder
+	for i, line := range hunk.Lines {
+		fmt.Fprintf(&sb, "%*d | %s\n", width, hunk.StartLine+i, line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,134 @@
+package aireview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Perkiraan token jawaban stub dipakai untuk reservasi kuota sebelum hunk "dikirim"
+const stubCompletionTokens = 256
+
+// Baris lebih panjang dari ini dianggap sulit dibaca
+const stubMaxLineLength = 160
+
+// stubRule adalah satu heuristik stub: pola per baris dan komentar yang dihasilkan
+type stubRule struct {
+	pattern    *regexp.Regexp
+	languages  []string
+	severity   string
+	message    string
+	suggestion string
+}
+
+var stubRules = []stubRule{
+	{
+		pattern:    regexp.MustCompile(`(?i)\b\w*(password|passwd|secret|api_?key|token)\w*\s*(:=|=|:)\s*["'][^"']{4,}["']`),
+		severity:   entity.SeverityError,
+		message:    "possible hardcoded credential",
+		suggestion: "load the value from configuration or a secret store",
+	},
+	{
+		pattern:    regexp.MustCompile(`(?i)(Sprintf|\+)\s*\(?\s*["'](SELECT|INSERT|UPDATE|DELETE)\b`),
+		severity:   entity.SeverityError,
+		message:    "SQL query is built with string formatting, which allows SQL injection",
+		suggestion: "use query parameters instead of formatting values into the SQL string",
+	},
+	{
+		pattern:    regexp.MustCompile(`InsecureSkipVerify:\s*true`),
+		languages:  []string{port.LanguageGo},
+		severity:   entity.SeverityError,
+		message:    "TLS certificate verification is disabled",
+		suggestion: "remove InsecureSkipVerify or configure the expected root CA",
+	},
+	{
+		pattern:    regexp.MustCompile(`\bpanic\(`),
+		languages:  []string{port.LanguageGo},
+		severity:   entity.SeverityWarning,
+		message:    "panic crashes the whole process",
+		suggestion: "return an error to the caller instead of panicking",
+	},
+	{
+		pattern:    regexp.MustCompile(`\btime\.Sleep\(`),
+		languages:  []string{port.LanguageGo},
+		severity:   entity.SeverityInfo,
+		message:    "time.Sleep ignores context cancellation",
+		suggestion: "wait with select on ctx.Done() and a time.After or ticker",
+	},
+}
+
+// StubReviewer meniru model dengan heuristik sederhana. Hasilnya deterministik untuk
+// input yang sama dan tidak butuh jaringan, dipakai untuk development, demo dan CI.
+type StubReviewer struct {
+	prompts *Prompts
+}
+
+func NewStubReviewer(prompts *Prompts) *StubReviewer {
+	return &StubReviewer{prompts: prompts}
+}
+
+func (r *StubReviewer) Name() string {
+	return ProviderStub
+}
+
+func (r *StubReviewer) EstimateTokens(hunk port.AIHunk) int {
+	return r.promptTokens(hunk) + stubCompletionTokens
+}
+
+// promptTokens tetap me-render prompt supaya template yang rusak ketahuan tanpa endpoint asli
+func (r *StubReviewer) promptTokens(hunk port.AIHunk) int {
+	system, user, err := r.prompts.Render(hunk)
+	if err != nil {
+		return 0
+	}
+	return EstimateTokens(system) + EstimateTokens(user)
+}
+
+func (r *StubReviewer) Review(ctx context.Context, hunk port.AIHunk) (*port.AIReviewResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, _, err := r.prompts.Render(hunk); err != nil {
+		return nil, err
+	}
+
+	var comments []port.AIComment
+	for i, line := range hunk.Lines {
+		lineNo := hunk.StartLine + i
+		for _, rule := range stubRules {
+			if rule.applies(hunk.Language) && rule.pattern.MatchString(line) {
+				comments = append(comments, port.AIComment{Line: lineNo, EndLine: lineNo, Severity: rule.severity,
+					Message: rule.message, Suggestion: rule.suggestion})
+			}
+		}
+		if n := utf8.RuneCountInString(line); n > stubMaxLineLength {
+			comments = append(comments, port.AIComment{Line: lineNo, EndLine: lineNo, Severity: entity.SeverityInfo,
+				Message:    fmt.Sprintf("line is %d characters long and hard to read", n),
+				Suggestion: "split the expression or wrap the arguments over several lines"})
+		}
+	}
+
+	// Jawaban dihitung seolah model mengembalikan JSON komentar yang sama
+	answer, err := json.Marshal(comments)
+	if err != nil {
+		return nil, err
+	}
+	return &port.AIReviewResult{Comments: comments, Tokens: r.promptTokens(hunk) + EstimateTokens(string(answer))}, nil
+}
+
+func (rule stubRule) applies(language string) bool {
+	if len(rule.languages) == 0 {
+		return true
+	}
+	for _, lang := range rule.languages {
+		if strings.EqualFold(lang, language) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package cbreaker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-crud/internal/usecase/port"
+
+	"github.com/sony/gobreaker"
+)
+
+const (
+	defaultAlertDedupeWindow   = time.Minute
+	defaultAlertRateLimit      = 3
+	defaultAlertRatePeriod     = 10 * time.Minute
+	defaultAlertDigestInterval = 5 * time.Minute
+	defaultAlertResolveAfter   = 2 * time.Minute
+	alertTick                  = 5 * time.Second
+	// alertTimeout membatasi pengiriman satu alert
+	alertTimeout = 15 * time.Second
+)
+
+// AlertConfig mengatur seberapa sering perubahan state breaker boleh menjadi notifikasi
+type AlertConfig struct {
+	// DedupeWindow: alert OPEN breaker yang sama hanya dikirim sekali per window, dari state mana pun.
+	// Harus lebih pendek dari RatePeriod supaya RateLimit ikut berlaku
+	DedupeWindow time.Duration
+	// RateLimit adalah jumlah alert OPEN per breaker per RatePeriod
+	RateLimit  int
+	RatePeriod time.Duration
+	// DigestInterval: alert yang ditahan dalam satu interval dikirim sebagai satu ringkasan flapping
+	DigestInterval time.Duration
+	// ResolveAfter adalah lama breaker harus tetap CLOSED sebelum pesan resolved dikirim
+	ResolveAfter time.Duration
+}
+
+// AlertConfigFromEnv membaca BREAKER_ALERT_DEDUPE_WINDOW (default 1m), BREAKER_ALERT_RATE_LIMIT
+// (default 3) per BREAKER_ALERT_RATE_PERIOD (default 10m), BREAKER_ALERT_DIGEST_INTERVAL (default 5m)
+// dan BREAKER_ALERT_RESOLVE_AFTER (default 2m)
+func AlertConfigFromEnv() AlertConfig {
+	cfg := AlertConfig{
+		DedupeWindow:   defaultAlertDedupeWindow,
+		RateLimit:      defaultAlertRateLimit,
+		RatePeriod:     defaultAlertRatePeriod,
+		DigestInterval: defaultAlertDigestInterval,
+		ResolveAfter:   defaultAlertResolveAfter,
+	}
+	durations := map[string]*time.Duration{
+		"BREAKER_ALERT_DEDUPE_WINDOW":   &cfg.DedupeWindow,
+		"BREAKER_ALERT_RATE_PERIOD":     &cfg.RatePeriod,
+		"BREAKER_ALERT_DIGEST_INTERVAL": &cfg.DigestInterval,
+		"BREAKER_ALERT_RESOLVE_AFTER":   &cfg.ResolveAfter,
+	}
+	for env, target := range durations {
+		if d, err := time.ParseDuration(os.Getenv(env)); err == nil && d >= 0 {
+			*target = d
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("BREAKER_ALERT_RATE_LIMIT")); err == nil && n > 0 {
+		cfg.RateLimit = n
+	}
+	return cfg
+}
+
+// breakerAlertState adalah riwayat alert satu breaker
+type breakerAlertState struct {
+	state gobreaker.State
+	// lastSent untuk dedupe alert OPEN, sent untuk rate limit
+	lastSent time.Time
+	sent     []time.Time
+
+	// Jendela digest berjalan sejak perubahan pertama setelah digest terakhir
+	windowStart   time.Time
+	windowChanges int
+	windowOpens   int
+	suppressed    int
+
+	// Insiden dimulai saat breaker OPEN dan selesai (resolved) setelah CLOSED selama ResolveAfter
+	incidentStart   time.Time
+	incidentChanges int
+	closedSince     time.Time
+}
+
+// Alerter berada di antara circuit breaker dan notifier. Hanya perubahan ke OPEN yang langsung
+// dikirim, dengan dedupe dan rate limit per breaker; HALF-OPEN dan CLOSED cukup dicatat.
+// Alert yang ditahan diringkas menjadi digest flapping, dan pesan resolved dikirim setelah
+// breaker tetap CLOSED. Digest dan resolved diperiksa oleh Start.
+type Alerter struct {
+	notifier port.Notifier
+	cfg      AlertConfig
+
+	// now diganti di test supaya dedupe, rate limit, digest dan resolved bisa diuji tanpa menunggu
+	now func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*breakerAlertState
+}
+
+func NewAlerter(notifier port.Notifier, cfg AlertConfig) *Alerter {
+	return &Alerter{notifier: notifier, cfg: cfg, now: time.Now, breakers: make(map[string]*breakerAlertState)}
+}
+
+// Start memeriksa digest dan resolved secara berkala sampai ctx selesai
+func (a *Alerter) Start(ctx context.Context) {
+	ticker := time.NewTicker(alertTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, n := range a.due(a.now()) {
+				a.send(n)
+			}
+		}
+	}
+}
+
+// StateChange dipanggil dari OnStateChange gobreaker, yang memegang lock breaker,
+// jadi notifikasi dikirim di goroutine terpisah
+func (a *Alerter) StateChange(name string, from, to gobreaker.State) {
+	now := a.now()
+
+	a.mu.Lock()
+	st := a.breakers[name]
+	if st == nil {
+		st = &breakerAlertState{}
+		a.breakers[name] = st
+	}
+	st.state = to
+	if st.windowStart.IsZero() {
+		st.windowStart = now
+	}
+	st.windowChanges++
+	if !st.incidentStart.IsZero() {
+		st.incidentChanges++
+	}
+
+	var alert *port.Notification
+	switch to {
+	case gobreaker.StateClosed:
+		st.closedSince = now
+	case gobreaker.StateOpen:
+		st.closedSince = time.Time{}
+		st.windowOpens++
+		if st.incidentStart.IsZero() {
+			st.incidentStart = now
+			st.incidentChanges = 1
+		}
+		if a.allow(st, now) {
+			alert = &port.Notification{
+				Topic:    port.NotifyTopicBreaker,
+				Severity: port.NotifyCritical,
+				Title:    "Circuit breaker " + name + " OPEN",
+				Text:     "⚡ Circuit Breaker [" + name + "] berubah dari " + stateToStr[from] + " ke " + stateToStr[to],
+				Fields:   map[string]string{"breaker": name, "from": stateToStr[from], "to": stateToStr[to]},
+				Time:     now,
+			}
+		} else {
+			st.suppressed++
+		}
+	default:
+		st.closedSince = time.Time{}
+	}
+	a.mu.Unlock()
+
+	if alert != nil {
+		go a.send(*alert)
+	}
+}
+
+// allow menerapkan dedupe dan rate limit pada alert OPEN satu breaker, lalu mencatat alert yang lolos
+func (a *Alerter) allow(st *breakerAlertState, now time.Time) bool {
+	if !st.lastSent.IsZero() && now.Sub(st.lastSent) < a.cfg.DedupeWindow {
+		return false
+	}
+
+	recent := st.sent[:0]
+	for _, t := range st.sent {
+		if now.Sub(t) < a.cfg.RatePeriod {
+			recent = append(recent, t)
+		}
+	}
+	st.sent = recent
+	if len(st.sent) >= a.cfg.RateLimit {
+		return false
+	}
+
+	st.lastSent = now
+	st.sent = append(st.sent, now)
+	return true
+}
+
+// due mengumpulkan digest dan pesan resolved yang sudah jatuh tempo, digest lebih dulu
+// supaya flapping sebelum breaker pulih tetap terlaporkan
+func (a *Alerter) due(now time.Time) []port.Notification {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var out []port.Notification
+	for name, st := range a.breakers {
+		if !st.windowStart.IsZero() && now.Sub(st.windowStart) >= a.cfg.DigestInterval {
+			if st.suppressed > 0 {
+				out = append(out, port.Notification{
+					Topic:    port.NotifyTopicBreaker,
+					Severity: port.NotifyWarning,
+					Title:    "Circuit breaker " + name + " flapping",
+					Text: fmt.Sprintf("🔁 %s flapped %d times in %s (opened %d times, %d alerts suppressed), now %s",
+						name, st.windowChanges, shortDuration(a.cfg.DigestInterval), st.windowOpens, st.suppressed, stateToStr[st.state]),
+					Fields: map[string]string{
+						"breaker":       name,
+						"state":         stateToStr[st.state],
+						"state_changes": strconv.Itoa(st.windowChanges),
+						"opens":         strconv.Itoa(st.windowOpens),
+					},
+					Time: now,
+				})
+			}
+			st.resetWindow()
+		}
+
+		if !st.incidentStart.IsZero() && st.state == gobreaker.StateClosed && now.Sub(st.closedSince) >= a.cfg.ResolveAfter {
+			out = append(out, port.Notification{
+				Topic:    port.NotifyTopicBreaker,
+				Severity: port.NotifyInfo,
+				Title:    "Circuit breaker " + name + " resolved",
+				Text: fmt.Sprintf("✅ %s has been CLOSED for %s; the incident lasted %s with %d state changes",
+					name, shortDuration(now.Sub(st.closedSince)), shortDuration(st.closedSince.Sub(st.incidentStart)), st.incidentChanges),
+				Fields: map[string]string{"breaker": name, "state": "CLOSED", "state_changes": strconv.Itoa(st.incidentChanges)},
+				Time:   now,
+			})
+			st.incidentStart, st.incidentChanges = time.Time{}, 0
+		}
+	}
+	return out
+}
+
+func (st *breakerAlertState) resetWindow() {
+	st.windowStart = time.Time{}
+	st.windowChanges, st.windowOpens, st.suppressed = 0, 0, 0
+}
+
+func (a *Alerter) send(n port.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancel()
+	if err := a.notifier.Notify(ctx, n); err != nil {
+		log.Printf("❌ Gagal kirim alert circuit breaker %s: %v", n.Fields["breaker"], err)
+	}
+}
+
+// shortDuration membulatkan ke detik dan membuang satuan nol di belakang, contoh 5m0s -> 5m
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package cbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-crud/internal/usecase/port"
+
+	"github.com/sony/gobreaker"
+)
+
+// recordingNotifier meneruskan setiap alert ke channel supaya test bisa menunggu goroutine send
+type recordingNotifier struct {
+	sent chan port.Notification
+}
+
+func (n *recordingNotifier) Name() string { return "test" }
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg port.Notification) error {
+	n.sent <- msg
+	return nil
+}
+
+var testAlertConfig = AlertConfig{
+	DedupeWindow:   time.Minute,
+	RateLimit:      3,
+	RatePeriod:     10 * time.Minute,
+	DigestInterval: 5 * time.Minute,
+	ResolveAfter:   2 * time.Minute,
+}
+
+// newTestAlerter mengembalikan alerter dengan jam palsu yang digeser lewat *time.Time
+func newTestAlerter(t0 time.Time) (*Alerter, *recordingNotifier, *time.Time) {
+	n := &recordingNotifier{sent: make(chan port.Notification, 10)}
+	a := NewAlerter(n, testAlertConfig)
+	clock := t0
+	a.now = func() time.Time { return clock }
+	return a, n, &clock
+}
+
+func TestAlerterAllow(t *testing.T) {
+	a, _, _ := newTestAlerter(time.Time{})
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	st := &breakerAlertState{}
+
+	steps := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{30 * time.Second, false}, // dedupe: masih dalam DedupeWindow
+		{time.Minute, true},
+		{2 * time.Minute, true},
+		{3 * time.Minute, false}, // rate limit: sudah 3 alert dalam 10m
+		{9 * time.Minute, false},
+		{10 * time.Minute, true}, // alert pertama keluar dari RatePeriod
+	}
+	for _, s := range steps {
+		if got := a.allow(st, t0.Add(s.at)); got != s.want {
+			t.Errorf("allow at +%s = %v, want %v", s.at, got, s.want)
+		}
+	}
+}
+
+func TestAlerterDedupesOpenFromAnyState(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a, n, clock := newTestAlerter(t0)
+
+	a.StateChange("db", gobreaker.StateClosed, gobreaker.StateOpen)
+	*clock = t0.Add(10 * time.Second)
+	a.StateChange("db", gobreaker.StateOpen, gobreaker.StateHalfOpen)
+	*clock = t0.Add(20 * time.Second)
+	// OPEN lagi dari HALF-OPEN adalah alert yang sama, tetap kena dedupe
+	a.StateChange("db", gobreaker.StateHalfOpen, gobreaker.StateOpen)
+
+	select {
+	case got := <-n.sent:
+		if got.Severity != port.NotifyCritical || got.Title != "Circuit breaker db OPEN" || !got.Time.Equal(t0) {
+			t.Errorf("alert = %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no OPEN alert sent")
+	}
+	select {
+	case got := <-n.sent:
+		t.Errorf("unexpected second alert %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if st := a.breakers["db"]; st.suppressed != 1 {
+		t.Errorf("suppressed = %d, want 1", st.suppressed)
+	}
+}
+
+func TestAlerterDue(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a, n, clock := newTestAlerter(t0)
+
+	// Flapping: OPEN, HALF-OPEN, OPEN (ditahan), HALF-OPEN, lalu CLOSED di +40s
+	transitions := []struct{ from, to gobreaker.State }{
+		{gobreaker.StateClosed, gobreaker.StateOpen},
+		{gobreaker.StateOpen, gobreaker.StateHalfOpen},
+		{gobreaker.StateHalfOpen, gobreaker.StateOpen},
+		{gobreaker.StateOpen, gobreaker.StateHalfOpen},
+		{gobreaker.StateHalfOpen, gobreaker.StateClosed},
+	}
+	for i, tr := range transitions {
+		*clock = t0.Add(time.Duration(i) * 10 * time.Second)
+		a.StateChange("db", tr.from, tr.to)
+	}
+	<-n.sent
+
+	steps := []struct {
+		at   time.Duration
+		want []port.Notification
+	}{
+		{time.Minute, nil},
+		// CLOSED sejak +40s, belum genap ResolveAfter
+		{2*time.Minute + 39*time.Second, nil},
+		{2*time.Minute + 40*time.Second, []port.Notification{{
+			Severity: port.NotifyInfo,
+			Title:    "Circuit breaker db resolved",
+			Text:     "✅ db has been CLOSED for 2m; the incident lasted 40s with 5 state changes",
+		}}},
+		{4 * time.Minute, nil},
+		{5 * time.Minute, []port.Notification{{
+			Severity: port.NotifyWarning,
+			Title:    "Circuit breaker db flapping",
+			Text:     "🔁 db flapped 5 times in 5m (opened 2 times, 1 alerts suppressed), now CLOSED",
+		}}},
+		// Window digest dan insiden sudah direset
+		{15 * time.Minute, nil},
+	}
+	for _, s := range steps {
+		got := a.due(t0.Add(s.at))
+		if len(got) != len(s.want) {
+			t.Fatalf("due at +%s = %+v, want %d notifications", s.at, got, len(s.want))
+		}
+		for i, w := range s.want {
+			if got[i].Severity != w.Severity || got[i].Title != w.Title || got[i].Text != w.Text {
+				t.Errorf("due at +%s [%d] = %+v, want %+v", s.at, i, got[i], w)
+			}
+		}
+	}
+}
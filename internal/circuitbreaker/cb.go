@@ -3,13 +3,19 @@ package cbreaker
 import (
 	"log"
 	"time"
-	"go-crud/internal/notifier"
 
 	"github.com/sony/gobreaker"
 )
 
-// Fungsi reusable untuk membuat circuit breaker dengan konfigurasi default
-func NewBreaker(name string) *gobreaker.CircuitBreaker {
+var stateToStr = map[gobreaker.State]string{
+	gobreaker.StateClosed:   "CLOSED",
+	gobreaker.StateOpen:     "OPEN",
+	gobreaker.StateHalfOpen: "HALF-OPEN",
+}
+
+// Fungsi reusable untuk membuat circuit breaker dengan konfigurasi default.
+// Perubahan state diteruskan ke alerts (boleh nil, berarti hanya dicatat di log).
+func NewBreaker(name string, alerts *Alerter) *gobreaker.CircuitBreaker {
 	settings := gobreaker.Settings{
 		Name:        name,
 		MaxRequests: 3,
@@ -22,27 +28,18 @@ func NewBreaker(name string) *gobreaker.CircuitBreaker {
 		},
 
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			logStateChange(name, from, to)
+			logStateChange(name, from, to, alerts)
 		},
 	}
 
 	return gobreaker.NewCircuitBreaker(settings)
 }
 
-
-func logStateChange(name string, from, to gobreaker.State) {
-	stateToStr := map[gobreaker.State]string{
-		gobreaker.StateClosed:   "CLOSED",
-		gobreaker.StateOpen:     "OPEN",
-		gobreaker.StateHalfOpen: "HALF-OPEN",
-	}
-
+func logStateChange(name string, from, to gobreaker.State, alerts *Alerter) {
 	msg := "⚡ Circuit Breaker [" + name + "] berubah dari " + stateToStr[from] + " ke " + stateToStr[to]
 	log.Println(msg)
 
-	// ✅ Kirim alert ke Telegram
-	notifier := notifier.NewTelegramNotifier()
-	if err := notifier.SendMessage(msg); err != nil {
-		log.Printf("❌ Gagal kirim notifikasi Telegram: %v", err)
+	if alerts != nil {
+		alerts.StateChange(name, from, to)
 	}
 }
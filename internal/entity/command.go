@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Status command async yang dikirim lewat Kafka
+const (
+	CommandStatusPending   = "pending"
+	CommandStatusSucceeded = "succeeded"
+	CommandStatusFailed    = "failed"
+)
+
+type Command struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	EntityID  *int      `json:"entity_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
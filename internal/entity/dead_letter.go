@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status pesan yang masuk DLQ
+const (
+	DeadLetterStatusPending  = "pending"
+	DeadLetterStatusReplayed = "replayed"
+)
+
+// DeadLetter menyimpan pesan yang gagal diproses. Payload adalah value pesan apa adanya
+// (bisa biner, di JSON tampil sebagai base64) beserta header aslinya; Envelope adalah hasil
+// decode consumer dan kosong jika pesan tidak bisa di-decode.
+type DeadLetter struct {
+	ID                int64             `json:"id"`
+	Topic             string            `json:"topic"`
+	OriginalTopic     string            `json:"original_topic"`
+	OriginalPartition int               `json:"original_partition"`
+	OriginalOffset    int64             `json:"original_offset"`
+	EventType         string            `json:"event_type"`
+	Payload           []byte            `json:"payload"`
+	Envelope          json.RawMessage   `json:"envelope,omitempty"`
+	Headers           map[string]string `json:"headers"`
+	Attempts          int               `json:"attempts"`
+	Error             string            `json:"error"`
+	Status            string            `json:"status"`
+	CreatedAt         time.Time         `json:"created_at"`
+	ReplayedAt        *time.Time        `json:"replayed_at,omitempty"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeGitURL menyamakan bentuk URL repository supaya https, ssh dan URL halaman web
+// dari repository yang sama cocok: host huruf kecil tanpa port bawaan, tanpa user, tanpa
+// akhiran .git dan '/'. Contoh git@github.com:Acme/App.git -> github.com/acme/app.
+// Hasilnya disimpan di kolom repositories.url_key untuk mencari repository dari webhook git.
+func NormalizeGitURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	// Bentuk scp ssh: user@host:path
+	if !strings.Contains(s, "://") {
+		if at := strings.Index(s, "@"); at >= 0 {
+			if host, path, ok := strings.Cut(s[at+1:], ":"); ok {
+				s = "ssh://" + host + "/" + path
+			}
+		}
+	}
+
+	normalized := s
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		host := u.Hostname()
+		if port := u.Port(); port != "" && port != "22" && port != "80" && port != "443" {
+			host += ":" + port
+		}
+		normalized = host + "/" + strings.TrimPrefix(u.Path, "/")
+	}
+	normalized = strings.TrimSuffix(strings.TrimRight(normalized, "/"), ".git")
+	return strings.ToLower(normalized)
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// NotificationPreferences adalah preferensi notifikasi satu user, contoh notifikasi review selesai.
+// Channels kosong berarti email ke alamat user saja; channel bersama (Slack, Telegram, webhook)
+// hanya dipakai jika dipilih eksplisit. Selama jam tenang (QuietHoursStart - QuietHoursEnd,
+// "HH:MM" di Timezone, boleh melewati tengah malam) hanya notifikasi critical yang dikirim,
+// notifikasi lain dibuang dan tidak dikirim ulang setelah jam tenang selesai.
+type NotificationPreferences struct {
+	UserID          int        `json:"user_id"`
+	Channels        []string   `json:"channels"`
+	MinSeverity     string     `json:"min_severity"`
+	QuietHoursStart string     `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string     `json:"quiet_hours_end,omitempty"`
+	Timezone        string     `json:"timezone"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// Status pesan di tabel outbox
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+)
+
+type OutboxMessage struct {
+	ID            int64      `json:"id"`
+	Topic         string     `json:"topic"`
+	EventType     string     `json:"event_type"`
+	Payload       []byte     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// OutboxStats dipakai untuk memantau backlog relay
+type OutboxStats struct {
+	Pending         int        `json:"pending"`
+	Retrying        int        `json:"retrying"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds      float64    `json:"lag_seconds"`
+}
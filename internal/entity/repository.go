@@ -12,4 +12,7 @@ type Repository struct {
     AIEnabled bool      `json:"ai_enabled"` 
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
+    // EventID adalah ID event repository.created yang membuat repository ini. Insert dengan
+    // EventID yang sama tidak membuat baris baru (unique key), jadi redelivery aman.
+    EventID   string    `json:"-"`
 }
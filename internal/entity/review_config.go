@@ -0,0 +1,33 @@
+package entity
+
+// SeverityOff dipakai di ReviewConfig.Severity untuk mematikan satu rule
+const SeverityOff = "off"
+
+// ReviewConfig mengatur code review per repository. Dibaca dari .gocrud-review.yml di root
+// repository; jika file tidak ada dipakai profil default yang disimpan lewat
+// PUT /repositories/{id}/review-config.
+type ReviewConfig struct {
+	Analyzers ReviewAnalyzerConfig `json:"analyzers,omitempty" yaml:"analyzers,omitempty"`
+	// Severity mengganti severity temuan per rule ID, nilai "off" membuang temuan rule tersebut
+	Severity map[string]string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Include dan Exclude adalah glob path file (mendukung * ? dan **). Include kosong berarti semua file.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// MaxFindings membatasi jumlah temuan yang disimpan (yang paling parah didahulukan), 0 berarti tanpa batas
+	MaxFindings int `json:"max_findings,omitempty" yaml:"max_findings,omitempty"`
+	// FailOn menandai run gagal jika temuan melewati ambang, nil berarti run tidak pernah gagal karena temuan
+	FailOn *ReviewThreshold `json:"fail_on,omitempty" yaml:"fail_on,omitempty"`
+}
+
+// ReviewAnalyzerConfig mengaktifkan atau mematikan analyzer di atas analyzer yang aktif untuk repository
+type ReviewAnalyzerConfig struct {
+	Enable  []string `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Disable []string `json:"disable,omitempty" yaml:"disable,omitempty"`
+}
+
+// ReviewThreshold: run gagal jika temuan dengan severity Severity atau lebih parah berjumlah lebih dari Max
+type ReviewThreshold struct {
+	// Severity default error
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Max      int    `json:"max" yaml:"max"`
+}
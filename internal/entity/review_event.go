@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// Jenis event progres code review yang dikirim lewat SSE
+const (
+	ReviewEventQueued      = "queued"
+	ReviewEventStarted     = "started"
+	ReviewEventResumed     = "resumed"
+	ReviewEventCloning     = "cloning"
+	ReviewEventAnalyzing   = "analyzing"
+	ReviewEventProgress    = "progress"
+	ReviewEventFinding     = "finding"
+	ReviewEventInterrupted = "interrupted"
+	ReviewEventCompleted   = "completed"
+	ReviewEventFailed      = "failed"
+	ReviewEventCancelled   = "cancelled"
+)
+
+// ReviewEvent adalah satu langkah progres job code review.
+// ID diisi repository (Redis stream ID atau nomor urut ring buffer) dan dipakai sebagai Last-Event-ID.
+type ReviewEvent struct {
+	ID         string         `json:"-"`
+	JobID      string         `json:"job_id"`
+	Type       string         `json:"type"`
+	RunID      int64          `json:"run_id,omitempty"`
+	Package    string         `json:"package,omitempty"`
+	FilesDone  int            `json:"files_done,omitempty"`
+	FilesTotal int            `json:"files_total,omitempty"`
+	Finding    *ReviewFinding `json:"finding,omitempty"`
+	Summary    *ReviewSummary `json:"summary,omitempty"`
+	Message    string         `json:"message,omitempty"`
+	At         time.Time      `json:"at"`
+}
+
+// Terminal bernilai true untuk event terakhir sebuah job
+func (e ReviewEvent) Terminal() bool {
+	switch e.Type {
+	case ReviewEventCompleted, ReviewEventFailed, ReviewEventCancelled:
+		return true
+	}
+	return false
+}
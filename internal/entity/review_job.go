@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+// Status job code review di antrean
+const (
+	ReviewJobQueued    = "queued"
+	ReviewJobRunning   = "running"
+	ReviewJobSucceeded = "succeeded"
+	ReviewJobFailed    = "failed"
+	ReviewJobCancelled = "cancelled"
+)
+
+// ReviewJob adalah permintaan code review yang menunggu atau sedang diproses worker.
+// CancelRequested di-set saat job running diminta berhenti, worker yang menjalankannya membatalkan review.
+// Worker yang memegang job memperpanjang LeaseExpiresAt lewat heartbeat; job running dengan lease
+// yang sudah habis dianggap ditinggal instance yang mati dan dikembalikan ke antrean.
+// BaseRef dan HeadRef kosong berarti review seluruh repository di HEAD.
+type ReviewJob struct {
+	ID              string                `json:"id"`
+	RepositoryID    int                   `json:"repository_id"`
+	BaseRef         string                `json:"base_ref,omitempty"`
+	HeadRef         string                `json:"head_ref,omitempty"`
+	Status          string                `json:"status"`
+	RunID           *int64                `json:"run_id,omitempty"`
+	Attempts        int                   `json:"attempts"`
+	Error           string                `json:"error,omitempty"`
+	CancelRequested bool                  `json:"cancel_requested,omitempty"`
+	WorkerID        string                `json:"worker_id,omitempty"`
+	LeaseExpiresAt  *time.Time            `json:"lease_expires_at,omitempty"`
+	Checkpoint      *ReviewCheckpoint     `json:"-"`
+	Transitions     []ReviewJobTransition `json:"transitions"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	StartedAt       *time.Time            `json:"started_at,omitempty"`
+	FinishedAt      *time.Time            `json:"finished_at,omitempty"`
+}
+
+// ReviewCheckpoint adalah progres review yang disimpan berkala, dipakai untuk
+// melanjutkan run yang sama setelah worker mati di tengah jalan
+type ReviewCheckpoint struct {
+	RunID     int64           `json:"run_id"`
+	CommitSHA string          `json:"commit_sha,omitempty"`
+	BaseSHA   string          `json:"base_sha,omitempty"`
+	FilesDone []string        `json:"files_done,omitempty"`
+	Findings  []ReviewFinding `json:"findings,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ReviewJobTransition mencatat satu perpindahan status job
+type ReviewJobTransition struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
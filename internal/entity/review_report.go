@@ -0,0 +1,9 @@
+package entity
+
+// ReviewReport adalah data yang dibutuhkan untuk mengekspor hasil satu review run
+// (SARIF, Markdown, HTML, JUnit)
+type ReviewReport struct {
+	Repository Repository
+	Run        ReviewRun
+	Findings   []ReviewFinding
+}
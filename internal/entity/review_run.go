@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// Severity temuan code review
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Status review run
+const (
+	ReviewRunRunning   = "running"
+	ReviewRunCompleted = "completed"
+	ReviewRunFailed    = "failed"
+	ReviewRunCancelled = "cancelled"
+)
+
+// ReviewRun adalah satu eksekusi code review atas sebuah repository.
+// CommitSHA adalah commit (head) yang di-review; BaseSHA hanya diisi untuk review
+// incremental, temuannya terbatas pada baris yang berubah antara BaseSHA dan CommitSHA.
+type ReviewRun struct {
+	ID           int64         `json:"id"`
+	RepositoryID int           `json:"repository_id"`
+	Status       string        `json:"status"`
+	CommitSHA    string        `json:"commit_sha,omitempty"`
+	BaseSHA      string        `json:"base_sha,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Summary      ReviewSummary `json:"summary"`
+	DurationMs   int64         `json:"duration_ms"`
+	ResumeCount  int           `json:"resume_count,omitempty"`
+	ResumedAt    *time.Time    `json:"resumed_at,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   *time.Time    `json:"finished_at,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// ReviewSummary adalah jumlah temuan per severity
+type ReviewSummary struct {
+	Total    int `json:"total"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+// Add menambah hitungan sesuai severity temuan
+func (s *ReviewSummary) Add(severity string) {
+	s.Total++
+	switch severity {
+	case SeverityError:
+		s.Errors++
+	case SeverityWarning:
+		s.Warnings++
+	case SeverityInfo:
+		s.Infos++
+	}
+}
+
+// ReviewFinding adalah satu temuan analyzer pada rentang posisi tertentu di source
+type ReviewFinding struct {
+	ID           int64  `json:"id,omitempty"`
+	RunID        int64  `json:"run_id,omitempty"`
+	RuleID       string `json:"rule_id"`
+	Severity     string `json:"severity"`
+	File         string `json:"file"`
+	StartLine    int    `json:"start_line"`
+	StartColumn  int    `json:"start_column"`
+	EndLine      int    `json:"end_line"`
+	EndColumn    int    `json:"end_column"`
+	Message      string `json:"message"`
+	SuggestedFix string `json:"suggested_fix,omitempty"`
+	Fingerprint  string `json:"fingerprint"`
+}
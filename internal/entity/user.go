@@ -10,4 +10,7 @@ type User struct {
     Email     string    `json:"email" validate:"required,email"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
+    // EventID adalah ID event user.created yang membuat user ini. Insert dengan EventID
+    // yang sama mengembalikan user yang sudah ada (unique key), jadi redelivery aman.
+    EventID   string    `json:"-"`
 }
\ No newline at end of file
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryFailed: percobaan habis atau subscription dinonaktifkan
+	WebhookDeliveryFailed = "failed"
+)
+
+// Tipe event webhook untuk review run yang selesai. Event user dan repository memakai
+// tipe yang sama dengan event bus (user.created, repository.updated, ...).
+const (
+	WebhookEventReviewCompleted = "review.completed"
+	WebhookEventReviewFailed    = "review.failed"
+	WebhookEventReviewCancelled = "review.cancelled"
+)
+
+// WebhookSubscription adalah endpoint luar yang menerima event sesuai filter Events.
+// Secret hanya dikembalikan saat subscription dibuat.
+type WebhookSubscription struct {
+	ID int64 `json:"id"`
+	// URL tujuan POST, http atau https
+	URL string `json:"url"`
+	// Events berisi tipe event ("repository.created"), semua event satu kelompok ("review.*") atau "*"
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// Active false berarti tidak ada event baru yang dikirim, diisi otomatis setelah
+	// terlalu banyak pengiriman gagal berturut-turut
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery adalah satu event untuk satu subscription beserta hasil percobaan terakhirnya
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	// LastResponse adalah awal body response terakhir, untuk debugging endpoint
+	LastResponse string     `json:"last_response,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+}
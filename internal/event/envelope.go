@@ -0,0 +1,111 @@
+// Package event berisi envelope CloudEvents (spec 1.0) yang dipakai semua event
+// yang dikirim dan diterima aplikasi, terlepas dari broker yang dipakai.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	SpecVersion     = "1.0"
+	Source          = "/go-crud"
+	ContentTypeJSON = "application/json"
+)
+
+// Envelope mengikuti atribut CloudEvents. SchemaVersion, TraceParent, TraceState
+// dan CommandID adalah extension attribute. DataBase64 dipakai structured mode
+// jika data diserialisasi ke format biner (Avro/Protobuf).
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	SchemaVersion   int             `json:"schemaversion"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	TraceState      string          `json:"tracestate,omitempty"`
+	CommandID       string          `json:"commandid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+}
+
+// New membuat envelope baru dengan schema version terbaru untuk eventType
+// dan trace context dari ctx.
+func New(ctx context.Context, eventType, subject string, data interface{}) (*Envelope, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s data: %w", eventType, err)
+	}
+
+	version := CurrentSchemaVersion(eventType)
+	env := &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.NewString(),
+		Type:            eventType,
+		Source:          Source,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: ContentTypeJSON,
+		DataSchema:      DataSchemaURI(eventType, version),
+		SchemaVersion:   version,
+		Data:            raw,
+	}
+
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	env.TraceParent = carrier.Get("traceparent")
+	env.TraceState = carrier.Get("tracestate")
+
+	return env, nil
+}
+
+// DecodeData meng-unmarshal data envelope ke struct tujuan
+func (e *Envelope) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return fmt.Errorf("event %s has no data", e.ID)
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("decode %s v%d data: %w", e.Type, e.SchemaVersion, err)
+	}
+	return nil
+}
+
+// ContextWithTrace mengembalikan ctx yang membawa trace context dari producer
+func (e *Envelope) ContextWithTrace(ctx context.Context) context.Context {
+	if e.TraceParent == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{
+		"traceparent": e.TraceParent,
+		"tracestate":  e.TraceState,
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
+
+// Validate memastikan atribut wajib CloudEvents terisi
+func (e *Envelope) Validate() error {
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return fmt.Errorf("unsupported specversion %q", e.SpecVersion)
+	case e.ID == "":
+		return fmt.Errorf("event id is required")
+	case e.Type == "":
+		return fmt.Errorf("event type is required")
+	case e.Source == "":
+		return fmt.Errorf("event source is required")
+	}
+	return nil
+}
+
+func DataSchemaURI(eventType string, version int) string {
+	return fmt.Sprintf("%s/schemas/%s/v%d", Source, eventType, version)
+}
@@ -0,0 +1,40 @@
+package event
+
+//go:generate go run ../../cmd/eventgen -schemas ../../schemas -registry ../../schemas/registry -out types_gen.go -proto ../../schemas/proto/events.proto
+
+// Tipe event yang dikenal aplikasi
+const (
+	TypeUserCreated       = "user.created"
+	TypeUserUpdated       = "user.updated"
+	TypeUserDeleted       = "user.deleted"
+	TypeRepositoryCreated = "repository.created"
+	TypeRepositoryUpdated = "repository.updated"
+	TypeRepositoryDeleted = "repository.deleted"
+)
+
+// UserData dan RepositoryData di-generate dari schemas/*.avsc ke types_gen.go
+
+// dataSchemas memetakan tipe event ke schema Avro data-nya
+var dataSchemas = map[string]string{
+	TypeUserCreated:       UserDataSchema,
+	TypeUserUpdated:       UserDataSchema,
+	TypeUserDeleted:       UserDataSchema,
+	TypeRepositoryCreated: RepositoryDataSchema,
+	TypeRepositoryUpdated: RepositoryDataSchema,
+	TypeRepositoryDeleted: RepositoryDataSchema,
+}
+
+// DataSchemaFor mengembalikan schema Avro untuk data eventType
+func DataSchemaFor(eventType string) (string, bool) {
+	s, ok := dataSchemas[eventType]
+	return s, ok
+}
+
+// DataSchemas mengembalikan salinan peta tipe event ke schema data-nya
+func DataSchemas() map[string]string {
+	schemas := make(map[string]string, len(dataSchemas))
+	for eventType, s := range dataSchemas {
+		schemas[eventType] = s
+	}
+	return schemas
+}
@@ -0,0 +1,25 @@
+// Code generated by eventgen from schemas/*.avsc. DO NOT EDIT.
+
+package event
+
+// RepositoryData dipakai oleh repository.created, repository.updated dan repository.deleted
+type RepositoryData struct {
+	ID        int    `json:"id,omitempty"`
+	UserID    int    `json:"user_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	URL       string `json:"url,omitempty"`
+	AIEnabled bool   `json:"ai_enabled"`
+}
+
+// RepositoryDataSchema adalah schema Avro gocrud.events.RepositoryData yang terdaftar di registry
+const RepositoryDataSchema = `{"type":"record","name":"RepositoryData","namespace":"gocrud.events","fields":[{"name":"id","type":"int","default":0,"field-id":1},{"name":"user_id","type":"int","default":0,"field-id":2},{"name":"name","type":"string","default":"","field-id":3},{"name":"url","type":"string","default":"","field-id":4},{"name":"ai_enabled","type":"boolean","default":false,"field-id":5}]}`
+
+// UserData dipakai oleh user.created, user.updated dan user.deleted (cukup id)
+type UserData struct {
+	ID    int    `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// UserDataSchema adalah schema Avro gocrud.events.UserData yang terdaftar di registry
+const UserDataSchema = `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[{"name":"id","type":"int","default":0,"field-id":1},{"name":"name","type":"string","default":"","field-id":2},{"name":"email","type":"string","default":"","field-id":3}]}`
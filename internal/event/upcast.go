@@ -0,0 +1,120 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LegacySchemaVersion adalah payload lama: map JSON ad-hoc dengan field "event"
+// yang disisipkan producer versi lama, tanpa envelope.
+const LegacySchemaVersion = 0
+
+// currentSchemaVersions menyimpan versi terbaru tiap tipe event
+var currentSchemaVersions = map[string]int{
+	TypeUserCreated:       1,
+	TypeUserUpdated:       1,
+	TypeUserDeleted:       1,
+	TypeRepositoryCreated: 1,
+	TypeRepositoryUpdated: 1,
+	TypeRepositoryDeleted: 1,
+}
+
+// upcaster mengubah data dari versi N ke N+1
+type upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// upcasters[type][N] mengubah data versi N ke N+1
+var upcasters = map[string]map[int]upcaster{
+	TypeUserCreated:       {0: upcastLegacyUser},
+	TypeUserUpdated:       {0: upcastLegacyUser},
+	TypeUserDeleted:       {0: upcastLegacyUser},
+	TypeRepositoryCreated: {0: upcastLegacyRepository},
+	TypeRepositoryUpdated: {0: upcastLegacyRepository},
+	TypeRepositoryDeleted: {0: upcastLegacyRepository},
+}
+
+func CurrentSchemaVersion(eventType string) int {
+	if v, ok := currentSchemaVersions[eventType]; ok {
+		return v
+	}
+	return 1
+}
+
+// Upcast menaikkan data envelope sampai versi terbaru
+func Upcast(env *Envelope) error {
+	target := CurrentSchemaVersion(env.Type)
+	for env.SchemaVersion < target {
+		up, ok := upcasters[env.Type][env.SchemaVersion]
+		if !ok {
+			return fmt.Errorf("no upcaster for %s v%d", env.Type, env.SchemaVersion)
+		}
+		data, err := up(env.Data)
+		if err != nil {
+			return fmt.Errorf("upcast %s v%d: %w", env.Type, env.SchemaVersion, err)
+		}
+		env.Data = data
+		env.SchemaVersion++
+		env.DataSchema = DataSchemaURI(env.Type, env.SchemaVersion)
+	}
+	if env.SchemaVersion > target {
+		return fmt.Errorf("%s v%d is newer than supported v%d", env.Type, env.SchemaVersion, target)
+	}
+	return nil
+}
+
+// FromLegacy membungkus payload lama ke envelope versi 0. eventType diambil dari
+// header eventType, atau field "event" jika header kosong.
+func FromLegacy(eventType string, payload []byte) (*Envelope, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("decode legacy payload: %w", err)
+	}
+
+	if eventType == "" {
+		if raw, ok := fields["event"]; ok {
+			_ = json.Unmarshal(raw, &eventType)
+		}
+	}
+	if eventType == "" {
+		return nil, fmt.Errorf("legacy payload has no event type")
+	}
+
+	env := &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.NewString(),
+		Type:            eventType,
+		Source:          Source,
+		Time:            time.Now().UTC(),
+		DataContentType: ContentTypeJSON,
+		SchemaVersion:   LegacySchemaVersion,
+		Data:            payload,
+	}
+	// ID dan command lama ikut dipindah supaya dedupe tetap jalan
+	if raw, ok := fields["event_id"]; ok {
+		_ = json.Unmarshal(raw, &env.ID)
+	}
+	if raw, ok := fields["command_id"]; ok {
+		_ = json.Unmarshal(raw, &env.CommandID)
+	}
+	return env, nil
+}
+
+// upcastLegacyUser membuang field internal (event, command_id, event_id,
+// created_at, ...) dan menyisakan field UserData.
+func upcastLegacyUser(data json.RawMessage) (json.RawMessage, error) {
+	var user UserData
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	return json.Marshal(user)
+}
+
+func upcastLegacyRepository(data json.RawMessage) (json.RawMessage, error) {
+	var repo RepositoryData
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return nil, err
+	}
+	return json.Marshal(repo)
+}
@@ -0,0 +1,443 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Consumer memproses event user dan repository dari event bus mana pun
+type Consumer struct {
+	bus             EventBus
+	topics          []string
+	userUsecase     usecase.IUserUsecase
+	repoUsecase     usecase.IRepositoryUsecase
+	repoRepository  repository.RepositoryRepository
+	commandUsecase  usecase.ICommandUsecase
+	deadLetterRepo  repository.DeadLetterRepository
+	processedEvents repository.ProcessedEventRepository
+	webhooks        usecase.WebhookPublisher
+	retryPolicy     RetryPolicy
+}
+
+// redeliveryDelay adalah jeda sebelum pesan dibaca ulang jika gagal diteruskan ke retry/DLQ
+const redeliveryDelay = 5 * time.Second
+
+func NewConsumer(
+	bus EventBus,
+	topics []string,
+	userUC usecase.IUserUsecase,
+	repoUC usecase.IRepositoryUsecase,
+	repoRepo repository.RepositoryRepository,
+	commandUC usecase.ICommandUsecase,
+	deadLetterRepo repository.DeadLetterRepository,
+	processedEvents repository.ProcessedEventRepository,
+	webhooks usecase.WebhookPublisher,
+) *Consumer {
+	return &Consumer{
+		bus:             bus,
+		topics:          topics,
+		userUsecase:     userUC,
+		repoUsecase:     repoUC,
+		repoRepository:  repoRepo,
+		commandUsecase:  commandUC,
+		deadLetterRepo:  deadLetterRepo,
+		processedEvents: processedEvents,
+		webhooks:        webhooks,
+		retryPolicy:     NewRetryPolicyFromEnv(),
+	}
+}
+
+// Start subscribe ke topics beserta retry topic-nya (lihat RetryPolicy) sampai ctx selesai
+func (c *Consumer) Start(ctx context.Context) {
+	subscriptions := append([]string{}, c.topics...)
+	for _, topic := range c.topics {
+		subscriptions = append(subscriptions, c.retryPolicy.RetryTopics(topic)...)
+	}
+
+	log.Printf("🚀 Event consumer started, topics: %v\n", subscriptions)
+	if err := c.bus.Subscribe(ctx, subscriptions, c.handleMessage); err != nil {
+		log.Printf("❌ Event consumer stopped: %v\n", err)
+		return
+	}
+	log.Println("🛑 Event consumer stopped")
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, msg *Message) {
+	log.Printf("📨 Received message from topic: %s\n", msg.Topic)
+	log.Printf("📥 Message value: %s\n", string(msg.Value))
+
+	// At-least-once: ack hanya jika pesan sudah selesai ditangani
+	if c.process(ctx, msg) {
+		if err := c.bus.Ack(msg); err != nil {
+			log.Printf("⚠️ Failed to ack message: %v\n", err)
+		}
+	}
+}
+
+// process mengembalikan true jika pesan boleh di-ack (berhasil diproses, duplikat,
+// atau sudah diteruskan ke retry topic / DLQ). Jika false pesan sudah di-Nack.
+func (c *Consumer) process(ctx context.Context, msg *Message) bool {
+	baseTopic := BaseTopic(msg.Topic)
+
+	// Pesan dari retry topic baru diproses setelah jedanya lewat
+	if delay, ok := c.retryPolicy.DelayFor(msg.Topic); ok {
+		if wait := time.Until(msg.Timestamp.Add(delay)); wait > 0 {
+			c.nack(msg, wait)
+			return false
+		}
+	}
+
+	if msg.DecodeErr != nil {
+		// Payload rusak tidak akan berhasil walau diulang, langsung ke DLQ
+		log.Printf("⚠️ Failed to decode message: %v\n", msg.DecodeErr)
+		return c.redeliverOnError(msg, c.sendToDLQ(ctx, msg, baseTopic, nil, msg.DecodeErr))
+	}
+	env := msg.Envelope
+	ctx = env.ContextWithTrace(ctx)
+
+	if c.isDuplicate(ctx, env) {
+		log.Printf("♻️ Skipping already processed event %s\n", env.ID)
+		return true
+	}
+
+	log.Printf("📋 Routing event by topic: %s\n", baseTopic)
+	entityID, err := c.routeEventByTopic(ctx, baseTopic, env)
+	if err != nil && isPermanent(err) {
+		// Diulang pun tetap gagal: command langsung gagal, tanpa retry topic atau DLQ
+		log.Printf("🚫 Event %s rejected: %v\n", env.ID, err)
+		c.markProcessed(ctx, env)
+		c.recordCommandOutcome(ctx, env, 0, err)
+		return true
+	}
+	if err != nil {
+		return c.redeliverOnError(msg, c.handleFailure(ctx, msg, baseTopic, env, err))
+	}
+
+	c.markProcessed(ctx, env)
+	c.recordCommandOutcome(ctx, env, entityID, nil)
+	c.publishWebhook(ctx, env, entityID)
+	return true
+}
+
+// publishWebhook meneruskan event yang berhasil diproses ke subscription webhook. ID diisi
+// dari hasil proses karena event *.created belum membawa ID entity.
+func (c *Consumer) publishWebhook(ctx context.Context, env *event.Envelope, entityID int) {
+	var data any
+	switch {
+	case isUserEvent(env.Type):
+		var user event.UserData
+		if err := env.DecodeData(&user); err != nil {
+			return
+		}
+		user.ID = entityID
+		data = user
+	case isRepoEvent(env.Type):
+		var repo event.RepositoryData
+		if err := env.DecodeData(&repo); err != nil {
+			return
+		}
+		repo.ID = entityID
+		data = repo
+	default:
+		return
+	}
+	c.webhooks.PublishWebhookEvent(ctx, env.ID, env.Type, data)
+}
+
+// isPermanent menandai error bisnis yang tidak akan berubah walau event diulang:
+// email yang sudah dipakai, atau user/repository yang sudah tidak ada
+func isPermanent(err error) bool {
+	return errors.Is(err, usecase.ErrEmailExists) ||
+		errors.Is(err, usecase.ErrUserNotFound) ||
+		errors.Is(err, usecase.ErrRepositoryNotFound) ||
+		errors.Is(err, repository.ErrUserNotFound) ||
+		errors.Is(err, repository.ErrRepositoryNotFound)
+}
+
+func (c *Consumer) nack(msg *Message, wait time.Duration) {
+	log.Printf("⏳ Delaying %s[%d] for %s\n", msg.Topic, msg.Partition, wait.Round(time.Second))
+	if err := c.bus.Nack(msg, wait); err != nil {
+		log.Printf("⚠️ Failed to nack message from %s: %v\n", msg.Topic, err)
+	}
+}
+
+func (c *Consumer) redeliverOnError(msg *Message, err error) bool {
+	if err == nil {
+		return true
+	}
+	c.nack(msg, redeliveryDelay)
+	return false
+}
+
+// isDuplicate mengecek ID event di dedupe store, lalu status command-nya. Ini hanya jalan pintas:
+// dua redelivery bisa lolos bersamaan, atau proses berhenti sebelum markProcessed. Jaminan
+// idempoten ada di insert *.created (unique key event_id), update dan delete aman diulang.
+// Error dari store hanya di-log.
+func (c *Consumer) isDuplicate(ctx context.Context, env *event.Envelope) bool {
+	processed, err := c.processedEvents.IsProcessed(ctx, env.ID)
+	if err != nil {
+		log.Printf("⚠️ Failed to check processed event %s: %v\n", env.ID, err)
+	}
+	if processed {
+		return true
+	}
+
+	if env.CommandID != "" {
+		cmd, err := c.commandUsecase.GetCommand(ctx, env.CommandID)
+		if err == nil && cmd.Status == entity.CommandStatusSucceeded {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Consumer) markProcessed(ctx context.Context, env *event.Envelope) {
+	if err := c.processedEvents.MarkProcessed(ctx, env.ID); err != nil {
+		log.Printf("⚠️ Failed to mark event %s as processed: %v\n", env.ID, err)
+	}
+}
+
+// handleFailure mengirim pesan ke retry topic berikutnya, atau ke DLQ jika retry sudah habis
+func (c *Consumer) handleFailure(ctx context.Context, msg *Message, baseTopic string, env *event.Envelope, cause error) error {
+	attempt := attemptFromHeaders(msg.Headers) + 1
+	if attempt > len(c.retryPolicy.Delays) {
+		return c.sendToDLQ(ctx, msg, baseTopic, env, cause)
+	}
+
+	retryTopic := c.retryPolicy.RetryTopic(baseTopic, attempt)
+	if err := c.forward(ctx, msg, retryTopic, attempt, cause); err != nil {
+		log.Printf("❌ Failed to publish to retry topic %s: %v\n", retryTopic, err)
+		return err
+	}
+
+	log.Printf("🔁 Event sent to %s (attempt %d): %v\n", retryTopic, attempt, cause)
+	return nil
+}
+
+func (c *Consumer) sendToDLQ(ctx context.Context, msg *Message, baseTopic string, env *event.Envelope, cause error) error {
+	dlqTopic := DLQTopic(baseTopic)
+	attempt := attemptFromHeaders(msg.Headers) + 1
+
+	// Salinan di Postgres dipakai endpoint admin untuk list & replay, jadi ditulis lebih dulu
+	dl := &entity.DeadLetter{
+		Topic:             dlqTopic,
+		OriginalTopic:     baseTopic,
+		OriginalPartition: msg.Partition,
+		OriginalOffset:    msg.Offset,
+		EventType:         msg.Header(HeaderEventType),
+		Payload:           msg.Value,
+		Headers:           make(map[string]string, len(msg.Headers)),
+		Attempts:          attempt,
+		Error:             cause.Error(),
+	}
+	for key, value := range msg.Headers {
+		dl.Headers[key] = value
+	}
+	if p, err := strconv.Atoi(msg.Header(headerOriginalPartition)); err == nil {
+		dl.OriginalPartition = p
+	}
+	if o, err := strconv.ParseInt(msg.Header(headerOriginalOffset), 10, 64); err == nil {
+		dl.OriginalOffset = o
+	}
+	if env != nil {
+		// Envelope hasil decode disimpan terpisah supaya replay tetap bisa walau pesan dikirim binary mode
+		dl.EventType = env.Type
+		if raw, err := json.Marshal(env); err == nil {
+			dl.Envelope = raw
+		}
+	}
+
+	if err := c.deadLetterRepo.InsertDeadLetter(ctx, dl); err != nil {
+		// Pesan di-Nack dan dicoba lagi, tanpa baris ini pesan tidak bisa di-list atau di-replay
+		log.Printf("❌ Failed to store dead letter: %v\n", err)
+		return err
+	}
+
+	// Topic DLQ hanya salinan untuk consumer lain. Baris dead letter sudah tersimpan, jadi
+	// kegagalan di sini cukup di-log supaya redelivery tidak membuat baris ganda.
+	if err := c.forward(ctx, msg, dlqTopic, attempt, cause); err != nil {
+		log.Printf("❌ Failed to publish to DLQ %s: %v\n", dlqTopic, err)
+	}
+
+	log.Printf("☠️ Event sent to %s after %d attempts: %v\n", dlqTopic, attempt, cause)
+
+	if env != nil {
+		c.recordCommandOutcome(ctx, env, 0, cause)
+	}
+	return nil
+}
+
+// forward menyalin pesan ke topic lain dengan header retry yang diperbarui.
+// Header x-original-* hanya diisi sekali, saat pesan pertama kali gagal.
+func (c *Consumer) forward(ctx context.Context, msg *Message, target string, attempt int, cause error) error {
+	headers := map[string]string{
+		headerAttempt:  strconv.Itoa(attempt),
+		headerError:    cause.Error(),
+		headerFailedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if msg.Header(headerOriginalTopic) == "" {
+		headers[headerOriginalTopic] = msg.Topic
+		headers[headerOriginalPartition] = strconv.Itoa(msg.Partition)
+		headers[headerOriginalOffset] = strconv.FormatInt(msg.Offset, 10)
+	}
+	return c.bus.Forward(ctx, msg, target, headers)
+}
+
+func (c *Consumer) routeEventByTopic(ctx context.Context, topic string, env *event.Envelope) (int, error) {
+	log.Printf("📥 Processing %s v%d event %s from topic: %s\n", env.Type, env.SchemaVersion, env.ID, topic)
+	log.Printf("🧾 Event data received: %s\n", string(env.Data))
+
+	switch topic {
+	case "user-events":
+		return c.processUserEvent(ctx, env)
+
+	case "repository-events":
+		return c.processRepositoryEvent(ctx, env)
+
+	default:
+		log.Printf("⚠️ Unknown topic: %s\n", topic)
+		return 0, fmt.Errorf("unknown topic: %s", topic)
+	}
+}
+
+// recordCommandOutcome menyimpan hasil proses event ke command yang dibuat oleh handler
+func (c *Consumer) recordCommandOutcome(ctx context.Context, env *event.Envelope, entityID int, procErr error) {
+	if env.CommandID == "" {
+		return
+	}
+
+	var err error
+	if procErr != nil {
+		err = c.commandUsecase.MarkFailed(ctx, env.CommandID, procErr)
+	} else {
+		err = c.commandUsecase.MarkSucceeded(ctx, env.CommandID, entityID)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to record outcome for command %s: %v\n", env.CommandID, err)
+	}
+}
+
+func isUserEvent(eventType string) bool {
+	return eventType == event.TypeUserCreated || eventType == event.TypeUserUpdated || eventType == event.TypeUserDeleted
+}
+
+func isRepoEvent(eventType string) bool {
+	return eventType == event.TypeRepositoryCreated || eventType == event.TypeRepositoryUpdated || eventType == event.TypeRepositoryDeleted
+}
+
+// processUserEvent mengembalikan ID user yang terdampak
+func (c *Consumer) processUserEvent(ctx context.Context, env *event.Envelope) (int, error) {
+	if !isUserEvent(env.Type) {
+		log.Printf("⚠️ Unknown user event: %s\n", env.Type)
+		return 0, fmt.Errorf("unknown user event: %s", env.Type)
+	}
+
+	var data event.UserData
+	if err := env.DecodeData(&data); err != nil {
+		return 0, err
+	}
+	log.Printf("🔍 Handling user event type: %s | Data: %+v\n", env.Type, data)
+
+	switch env.Type {
+	case event.TypeUserCreated:
+		user := &entity.User{
+			Name:    data.Name,
+			Email:   data.Email,
+			EventID: env.ID,
+		}
+		err := c.userUsecase.CreateUser(ctx, user)
+		if err != nil {
+			log.Printf("❌ Failed to create user from event: %v\n", err)
+			return 0, err
+		}
+		return user.ID, nil
+
+	case event.TypeUserUpdated:
+		input := usecase.UserInput{
+			Name:  data.Name,
+			Email: data.Email,
+		}
+		_, err := c.userUsecase.UpdateUser(ctx, data.ID, input)
+		if err != nil {
+			log.Printf("❌ Failed to update user from event: %v\n", err)
+			return 0, err
+		}
+		return data.ID, nil
+
+	default: // event.TypeUserDeleted
+		err := c.userUsecase.DeleteUser(ctx, data.ID)
+		if err != nil {
+			log.Printf("❌ Failed to delete user from event: %v\n", err)
+			return 0, err
+		}
+		return data.ID, nil
+	}
+}
+
+
+// processRepositoryEvent mengembalikan ID repository yang terdampak
+func (c *Consumer) processRepositoryEvent(ctx context.Context, env *event.Envelope) (int, error) {
+	if !isRepoEvent(env.Type) {
+		log.Printf("⚠️ Unknown repository event: %s\n", env.Type)
+		return 0, fmt.Errorf("unknown repository event: %s", env.Type)
+	}
+
+	var data event.RepositoryData
+	if err := env.DecodeData(&data); err != nil {
+		return 0, err
+	}
+	log.Printf("🔍 Handling repository event type: %s | Data: %+v\n", env.Type, data)
+
+	switch env.Type {
+	case event.TypeRepositoryCreated:
+		repo := &entity.Repository{
+			Name:      data.Name,
+			URL:       data.URL,
+			AIEnabled: data.AIEnabled,
+			UserID:    data.UserID,
+			EventID:   env.ID,
+		}
+		// Validasi user masih ada sebelum insert
+		if err := c.repoUsecase.CreateRepository(ctx, repo); err != nil {
+			log.Printf("❌ Failed to validate repository from event: %v\n", err)
+			return 0, err
+		}
+		if err := c.repoRepository.CreateRepository(ctx, repo); err != nil {
+			log.Printf("❌ Failed to create repository from event: %v\n", err)
+			return 0, err
+		}
+		log.Printf("✅ Repository created with ID %d\n", repo.ID)
+		return repo.ID, nil
+
+	case event.TypeRepositoryUpdated:
+		repo, err := c.repoRepository.GetRepositoryByID(ctx, data.ID)
+		if err != nil {
+			log.Printf("❌ Repository not found for update: %v\n", err)
+			return 0, err
+		}
+		repo.Name = data.Name
+		repo.URL = data.URL
+		repo.AIEnabled = data.AIEnabled
+		if err := c.repoRepository.Update(ctx, repo); err != nil {
+			log.Printf("❌ Failed to update repository from event: %v\n", err)
+			return 0, err
+		}
+		return data.ID, nil
+
+	default: // event.TypeRepositoryDeleted
+		if err := c.repoRepository.Delete(ctx, data.ID); err != nil {
+			log.Printf("❌ Failed to delete repository from event: %v\n", err)
+			return 0, err
+		}
+		return data.ID, nil
+	}
+}
@@ -0,0 +1,269 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/repository"
+	"go-crud/internal/repository/memory"
+	"go-crud/internal/usecase"
+)
+
+// pipeline menyusun alur write async seperti cmd/main.go dengan backend memory:
+// command + outbox -> OutboxRelay -> MemoryBus -> Consumer -> repository
+type pipeline struct {
+	commands usecase.ICommandUsecase
+	users    usecase.IUserUsecase
+	repos    usecase.IRepositoryUsecase
+	relay    *OutboxRelay
+}
+
+func newPipeline(t *testing.T) *pipeline {
+	t.Helper()
+	// Retry topic dengan jeda pendek supaya event yang gagal sementara tidak menahan test
+	t.Setenv("KAFKA_RETRY_DELAYS", "10ms")
+
+	store := memory.NewStore()
+	cache := memory.NewCacheRepository()
+	bus := NewMemoryBus()
+	t.Cleanup(bus.Close)
+
+	userRepo := memory.NewUserRepository(store)
+	repoRepo := memory.NewRepositoryRepository(store)
+	p := &pipeline{
+		commands: usecase.NewCommandUsecase(memory.NewCommandRepository(store)),
+		users:    usecase.NewUserUsecase(userRepo, cache, NewUserPublisher(bus), nil),
+		repos:    usecase.NewRepositoryUsecase(repoRepo, userRepo, cache, nil),
+		relay:    NewOutboxRelay(memory.NewOutboxRepository(store), bus),
+	}
+	consumer := NewConsumer(bus, []string{"user-events", "repository-events"}, p.users, p.repos, repoRepo, p.commands,
+		memory.NewDeadLetterRepository(store), repository.NewProcessedEventRepository(cache, time.Hour),
+		usecase.NewWebhookUsecase(memory.NewWebhookRepository(store), false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		consumer.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return p
+}
+
+// submit mengirim command lewat outbox lalu menunggu consumer selesai memprosesnya
+func (p *pipeline) submit(t *testing.T, cmdType, topic, subject string, data any) *entity.Command {
+	t.Helper()
+	ctx := context.Background()
+	cmd, err := p.commands.SubmitCommand(ctx, cmdType, topic, subject, data)
+	if err != nil {
+		t.Fatalf("SubmitCommand(%s): %v", cmdType, err)
+	}
+	p.relay.relayBatch(ctx)
+	return p.wait(t, cmd.ID)
+}
+
+// wait menunggu sampai command tidak lagi pending
+func (p *pipeline) wait(t *testing.T, id string) *entity.Command {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := p.commands.GetCommand(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetCommand: %v", err)
+		}
+		if got.Status != entity.CommandStatusPending {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("command %s (%s) still pending", id, got.Type)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCommandPipeline(t *testing.T) {
+	p := newPipeline(t)
+	ctx := context.Background()
+
+	cmd := p.submit(t, event.TypeUserCreated, "user-events", "", event.UserData{Name: "Budi", Email: "budi@example.com"})
+	if cmd.Status != entity.CommandStatusSucceeded || cmd.EntityID == nil {
+		t.Fatalf("create user command = %+v, want succeeded with entity ID", cmd)
+	}
+	userID := *cmd.EntityID
+
+	user, err := p.users.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if user.Name != "Budi" || user.Email != "budi@example.com" {
+		t.Errorf("user = %+v", user)
+	}
+
+	// User tanpa repository mendapat slice kosong (JSON []), bukan error
+	if repos, err := p.repos.GetRepositoriesByUserID(ctx, userID); err != nil || repos == nil || len(repos) != 0 {
+		t.Errorf("repositories before create = %#v, %v, want empty slice", repos, err)
+	}
+
+	cmd = p.submit(t, event.TypeRepositoryCreated, "repository-events", fmt.Sprintf("users/%d", userID),
+		event.RepositoryData{UserID: userID, Name: "go-crud", URL: "https://github.com/acme/go-crud", AIEnabled: true})
+	if cmd.Status != entity.CommandStatusSucceeded || cmd.EntityID == nil {
+		t.Fatalf("create repository command = %+v, want succeeded with entity ID", cmd)
+	}
+
+	repos, err := p.repos.GetRepositoriesByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetRepositoriesByUserID: %v", err)
+	}
+	if len(repos) != 1 || repos[0].ID != *cmd.EntityID || repos[0].Name != "go-crud" || !repos[0].AIEnabled {
+		t.Errorf("repositories = %+v", repos)
+	}
+
+	// Batch dari POST /users/{id}/repositories tersimpan dalam satu transaksi
+	batch, err := p.commands.SubmitCommands(ctx, []usecase.CommandRequest{
+		{Type: event.TypeRepositoryCreated, Topic: "repository-events", Subject: fmt.Sprintf("users/%d", userID),
+			Data: event.RepositoryData{UserID: userID, Name: "go-cli", URL: "https://github.com/acme/go-cli"}},
+		{Type: event.TypeRepositoryCreated, Topic: "repository-events", Subject: fmt.Sprintf("users/%d", userID),
+			Data: event.RepositoryData{UserID: userID, Name: "go-web", URL: "https://github.com/acme/go-web"}},
+	})
+	if err != nil || len(batch) != 2 {
+		t.Fatalf("SubmitCommands = %v, %v", batch, err)
+	}
+	p.relay.relayBatch(ctx)
+	for _, c := range batch {
+		if got := p.wait(t, c.ID); got.Status != entity.CommandStatusSucceeded {
+			t.Errorf("batch command = %+v, want succeeded", got)
+		}
+	}
+	if repos, err := p.repos.GetRepositoriesByUserID(ctx, userID); err != nil || len(repos) != 3 {
+		t.Errorf("repositories after batch = %+v, %v, want 3", repos, err)
+	}
+
+	cmd = p.submit(t, event.TypeUserUpdated, "user-events", fmt.Sprintf("users/%d", userID),
+		event.UserData{ID: userID, Name: "Budi Santoso", Email: "budi.s@example.com"})
+	if cmd.Status != entity.CommandStatusSucceeded {
+		t.Fatalf("update user command = %+v, want succeeded", cmd)
+	}
+	if user, err := p.users.GetUserByID(ctx, userID); err != nil || user.Name != "Budi Santoso" {
+		t.Errorf("updated user = %+v, %v", user, err)
+	}
+}
+
+func TestCommandPipelineRecordsFailure(t *testing.T) {
+	p := newPipeline(t)
+	user := event.UserData{Name: "Budi", Email: "budi@example.com"}
+
+	if cmd := p.submit(t, event.TypeUserCreated, "user-events", "", user); cmd.Status != entity.CommandStatusSucceeded {
+		t.Fatalf("first create user command = %+v, want succeeded", cmd)
+	}
+	// Email yang sudah dipakai adalah error permanen, langsung tercatat di status command
+	cmd := p.submit(t, event.TypeUserCreated, "user-events", "", user)
+	if cmd.Status != entity.CommandStatusFailed || cmd.Error == "" {
+		t.Errorf("duplicate create user command = %+v, want failed with error", cmd)
+	}
+}
+
+func TestCommandPipelineRejectsMissingEntities(t *testing.T) {
+	p := newPipeline(t)
+
+	tests := []struct {
+		name    string
+		cmdType string
+		topic   string
+		data    any
+	}{
+		{"repository for deleted user", event.TypeRepositoryCreated, "repository-events",
+			event.RepositoryData{UserID: 99, Name: "go-crud", URL: "https://github.com/acme/go-crud"}},
+		{"update missing user", event.TypeUserUpdated, "user-events",
+			event.UserData{ID: 99, Name: "Budi", Email: "budi@example.com"}},
+		{"update missing repository", event.TypeRepositoryUpdated, "repository-events",
+			event.RepositoryData{ID: 99, Name: "go-crud", URL: "https://github.com/acme/go-crud"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := p.submit(t, tt.cmdType, tt.topic, "", tt.data)
+			if cmd.Status != entity.CommandStatusFailed || cmd.Error == "" {
+				t.Errorf("command = %+v, want failed with error", cmd)
+			}
+		})
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{usecase.ErrEmailExists, true},
+		{usecase.ErrUserNotFound, true},
+		{usecase.ErrRepositoryNotFound, true},
+		{fmt.Errorf("update user: %w", repository.ErrUserNotFound), true},
+		{fmt.Errorf("update repository: %w", repository.ErrRepositoryNotFound), true},
+		// Error infrastruktur tetap lewat retry topic
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := isPermanent(tt.err); got != tt.want {
+			t.Errorf("isPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// failingDeadLetters menolak setiap insert, seperti Postgres yang sedang mati
+type failingDeadLetters struct {
+	repository.DeadLetterRepository
+}
+
+func (failingDeadLetters) InsertDeadLetter(ctx context.Context, dl *entity.DeadLetter) error {
+	return errors.New("connection refused")
+}
+
+func TestSendToDLQStoresDeadLetterFirst(t *testing.T) {
+	undecodable := func() *Message {
+		return &Message{Topic: "user-events", Value: []byte("not json"), DecodeErr: errors.New("decode failed")}
+	}
+	dlqBacklog := func(bus *MemoryBus) int {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return len(bus.backlog[DLQTopic("user-events")])
+	}
+
+	t.Run("insert fails", func(t *testing.T) {
+		bus := NewMemoryBus()
+		t.Cleanup(bus.Close)
+		c := &Consumer{bus: bus, deadLetterRepo: failingDeadLetters{}}
+
+		// Pesan di-Nack supaya dicoba lagi, dan belum diteruskan ke topic DLQ
+		if c.process(context.Background(), undecodable()) {
+			t.Error("process acked the message although the dead letter was not stored")
+		}
+		if n := dlqBacklog(bus); n != 0 {
+			t.Errorf("DLQ topic has %d messages, want 0", n)
+		}
+	})
+
+	t.Run("insert succeeds", func(t *testing.T) {
+		bus := NewMemoryBus()
+		t.Cleanup(bus.Close)
+		deadLetters := memory.NewDeadLetterRepository(memory.NewStore())
+		c := &Consumer{bus: bus, deadLetterRepo: deadLetters}
+
+		if !c.process(context.Background(), undecodable()) {
+			t.Error("process did not ack the dead-lettered message")
+		}
+		stored, err := deadLetters.GetDeadLetters(context.Background(), "", 10)
+		if err != nil || len(stored) != 1 {
+			t.Errorf("dead letters = %+v, %v, want 1", stored, err)
+		}
+		if n := dlqBacklog(bus); n != 1 {
+			t.Errorf("DLQ topic has %d messages, want 1", n)
+		}
+	})
+}
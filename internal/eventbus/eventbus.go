@@ -0,0 +1,64 @@
+// Package eventbus memisahkan proses event (consumer, outbox relay, publisher)
+// dari broker yang dipakai. Implementasi Kafka ada di package kafka, implementasi
+// in-memory ada di memory.go.
+package eventbus
+
+import (
+	"context"
+	"go-crud/internal/event"
+	"os"
+	"strings"
+	"time"
+)
+
+// Jenis event bus, dipilih lewat EVENT_BUS
+const (
+	KindKafka  = "kafka"
+	KindMemory = "memory"
+)
+
+// KindFromEnv membaca EVENT_BUS, default kafka
+func KindFromEnv() string {
+	if strings.EqualFold(os.Getenv("EVENT_BUS"), KindMemory) {
+		return KindMemory
+	}
+	return KindKafka
+}
+
+// HeaderEventType dikirim semua bus supaya consumer lama tetap bisa membaca tipe event
+const HeaderEventType = "eventType"
+
+// Message adalah pesan yang diterima subscriber. Envelope sudah di-decode oleh bus,
+// DecodeErr terisi jika payload tidak bisa dibaca (pesan tetap bisa di-Forward ke DLQ).
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+	Envelope  *event.Envelope
+	DecodeErr error
+}
+
+func (m *Message) Header(key string) string {
+	return m.Headers[key]
+}
+
+// Handler dipanggil untuk setiap pesan dan wajib memanggil Ack atau Nack
+type Handler func(ctx context.Context, msg *Message)
+
+type EventBus interface {
+	// Publish mengirim envelope dan menunggu sampai broker menerimanya
+	Publish(ctx context.Context, topic string, env *event.Envelope) error
+	// Forward menyalin pesan apa adanya ke topic lain dengan header tambahan (retry topic, DLQ)
+	Forward(ctx context.Context, msg *Message, topic string, headers map[string]string) error
+	// Subscribe memanggil handler untuk setiap pesan di topics sampai ctx selesai
+	Subscribe(ctx context.Context, topics []string, handler Handler) error
+	// Ack menandai pesan selesai, pesan tidak akan dikirim ulang
+	Ack(msg *Message) error
+	// Nack meminta pesan dikirim ulang setelah delay
+	Nack(msg *Message, delay time.Duration) error
+	Close()
+}
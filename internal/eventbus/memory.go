@@ -0,0 +1,234 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/event"
+	"log"
+	"sync"
+	"time"
+)
+
+// MemoryBus adalah event bus in-process berbasis channel untuk jalan tanpa broker
+// (EVENT_BUS=memory). Semantiknya sama dengan Kafka: publish asinkron, pesan yang
+// di-Nack dikirim ulang setelah delay. Pesan ke topic yang belum punya subscriber
+// ditahan sampai ada yang subscribe. Semua pesan hilang saat proses berhenti.
+type MemoryBus struct {
+	mu      sync.Mutex
+	subs    map[string][]*memorySubscription
+	backlog map[string][]*Message
+	offsets map[string]int64
+	closed  bool
+}
+
+// memorySubscription menampung pesan tanpa batas supaya Publish dari dalam handler
+// (misalnya Forward ke retry topic) tidak pernah deadlock.
+type memorySubscription struct {
+	mu     sync.Mutex
+	queue  []*Message
+	notify chan struct{}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subs:    make(map[string][]*memorySubscription),
+		backlog: make(map[string][]*Message),
+		offsets: make(map[string]int64),
+	}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, topic string, env *event.Envelope) error {
+	value, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	key := []byte(nil)
+	if env.Subject != "" {
+		key = []byte(env.Subject)
+	}
+	return b.deliver(topic, key, value, map[string]string{HeaderEventType: env.Type})
+}
+
+func (b *MemoryBus) Forward(ctx context.Context, msg *Message, topic string, headers map[string]string) error {
+	merged := make(map[string]string, len(msg.Headers)+len(headers))
+	for k, v := range msg.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	return b.deliver(topic, msg.Key, msg.Value, merged)
+}
+
+func (b *MemoryBus) deliver(topic string, key, value []byte, headers map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return fmt.Errorf("memory bus is closed")
+	}
+
+	msg := &Message{
+		Topic:     topic,
+		Offset:    b.offsets[topic],
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+		Timestamp: time.Now(),
+	}
+	b.offsets[topic]++
+
+	subs := b.subs[topic]
+	if len(subs) == 0 {
+		b.backlog[topic] = append(b.backlog[topic], msg)
+		return nil
+	}
+	for _, sub := range subs {
+		sub.push(copyMessage(msg))
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, topics []string, handler Handler) error {
+	sub := &memorySubscription{notify: make(chan struct{}, 1)}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return fmt.Errorf("memory bus is closed")
+	}
+	for _, topic := range topics {
+		b.subs[topic] = append(b.subs[topic], sub)
+		for _, msg := range b.backlog[topic] {
+			sub.push(msg)
+		}
+		delete(b.backlog, topic)
+	}
+	b.mu.Unlock()
+
+	defer b.unsubscribe(topics, sub)
+
+	for {
+		msg, ok := sub.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-sub.notify:
+				continue
+			}
+		}
+
+		// Sama seperti Kafka, handler menerima envelope yang sudah di-decode dari payload
+		env := &event.Envelope{}
+		if err := json.Unmarshal(msg.Value, env); err != nil {
+			msg.DecodeErr = fmt.Errorf("decode memory bus message: %w", err)
+		} else if err := env.Validate(); err != nil {
+			msg.DecodeErr = err
+		} else if err := event.Upcast(env); err != nil {
+			msg.DecodeErr = err
+		} else {
+			msg.Envelope = env
+		}
+
+		handler(ctx, msg)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (b *MemoryBus) unsubscribe(topics []string, sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, topic := range topics {
+		subs := b.subs[topic]
+		for i, s := range subs {
+			if s == sub {
+				b.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Ack tidak perlu melakukan apa-apa, pesan sudah keluar dari antrean saat dibaca
+func (b *MemoryBus) Ack(msg *Message) error {
+	return nil
+}
+
+// Nack memasukkan kembali pesan ke antrean topic-nya setelah delay
+func (b *MemoryBus) Nack(msg *Message, delay time.Duration) error {
+	redelivered := copyMessage(msg)
+	time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.closed {
+			return
+		}
+		subs := b.subs[msg.Topic]
+		if len(subs) == 0 {
+			b.backlog[msg.Topic] = append(b.backlog[msg.Topic], redelivered)
+			return
+		}
+		for _, sub := range subs {
+			sub.push(copyMessage(redelivered))
+		}
+	})
+	return nil
+}
+
+func (b *MemoryBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for topic, pending := range b.backlog {
+		log.Printf("⚠️ Memory bus closed with %d undelivered messages on %s\n", len(pending), topic)
+	}
+}
+
+func (s *memorySubscription) push(msg *Message) {
+	s.mu.Lock()
+	s.queue = append(s.queue, msg)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *memorySubscription) pop() (*Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return nil, false
+	}
+	msg := s.queue[0]
+	s.queue = s.queue[1:]
+	return msg, true
+}
+
+// copyMessage membuat salinan tanpa hasil decode supaya setiap pengiriman di-decode ulang
+func copyMessage(msg *Message) *Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	return &Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Timestamp: msg.Timestamp,
+	}
+}
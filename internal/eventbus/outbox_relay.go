@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"go-crud/internal/event"
+	"go-crud/internal/repository"
+	"log"
+	"time"
+)
+
+const (
+	outboxPollInterval = 1 * time.Second
+	outboxBatchSize    = 100
+	outboxMaxBackoff   = 5 * time.Minute
+)
+
+// OutboxRelay memindahkan event dari tabel outbox ke event bus
+type OutboxRelay struct {
+	repo repository.OutboxRepository
+	bus  EventBus
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, bus EventBus) *OutboxRelay {
+	return &OutboxRelay{
+		repo: repo,
+		bus:  bus,
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	log.Println("🚀 Outbox relay started...")
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Outbox relay stopped")
+			return
+		case <-ticker.C:
+			r.relayBatch(ctx)
+		}
+	}
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context) {
+	messages, err := r.repo.ClaimPending(ctx, outboxBatchSize)
+	if err != nil {
+		log.Printf("⚠️ Failed to claim outbox messages: %v\n", err)
+		return
+	}
+
+	for _, msg := range messages {
+		env, err := outboxEnvelope(msg.EventType, msg.Payload)
+		if err == nil {
+			err = r.bus.Publish(ctx, msg.Topic, env)
+		}
+		if err != nil {
+			backoff := outboxBackoff(msg.Attempts + 1)
+			log.Printf("❌ Failed to deliver outbox message %d (attempt %d), retry in %s: %v\n", msg.ID, msg.Attempts+1, backoff, err)
+			if markErr := r.repo.MarkFailed(ctx, msg.ID, err.Error(), backoff); markErr != nil {
+				log.Printf("⚠️ Failed to mark outbox message %d as failed: %v\n", msg.ID, markErr)
+			}
+			continue
+		}
+
+		if err := r.repo.MarkDelivered(ctx, msg.ID); err != nil {
+			// Pesan akan dikirim ulang setelah claim habis, consumer harus tahan duplikat
+			log.Printf("⚠️ Failed to mark outbox message %d as delivered: %v\n", msg.ID, err)
+		}
+	}
+}
+
+// outboxEnvelope membaca payload outbox (envelope CloudEvents structured JSON).
+// Payload lama tanpa envelope dibungkus ulang lewat event.FromLegacy.
+func outboxEnvelope(eventType string, payload []byte) (*event.Envelope, error) {
+	var env event.Envelope
+	if err := json.Unmarshal(payload, &env); err == nil && env.Validate() == nil {
+		return &env, nil
+	}
+
+	legacy, err := event.FromLegacy(eventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.Upcast(legacy); err != nil {
+		return nil, err
+	}
+	return legacy, nil
+}
+
+// outboxBackoff: 2s, 4s, 8s, ... maksimal outboxMaxBackoff
+func outboxBackoff(attempt int) time.Duration {
+	if attempt > 16 {
+		return outboxMaxBackoff
+	}
+	backoff := time.Duration(1<<attempt) * time.Second
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header yang dibawa pesan selama proses retry / DLQ
+const (
+	headerOriginalTopic     = "x-original-topic"
+	headerOriginalPartition = "x-original-partition"
+	headerOriginalOffset    = "x-original-offset"
+	headerAttempt           = "x-attempt"
+	headerError             = "x-error"
+	headerFailedAt          = "x-failed-at"
+)
+
+const defaultRetryDelays = "1m,10m"
+
+// RetryPolicy menentukan jeda tiap retry topic, urut dari percobaan pertama.
+// Berlaku untuk semua event bus. Contoh KAFKA_RETRY_DELAYS=1m,10m menghasilkan <topic>.retry.1m dan <topic>.retry.10m.
+type RetryPolicy struct {
+	Delays []time.Duration
+}
+
+func NewRetryPolicyFromEnv() RetryPolicy {
+	raw := os.Getenv("KAFKA_RETRY_DELAYS")
+	if raw == "" {
+		raw = defaultRetryDelays
+	}
+
+	var delays []time.Duration
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			log.Printf("⚠️ Invalid retry delay %q ignored: %v\n", part, err)
+			continue
+		}
+		delays = append(delays, d)
+	}
+	return RetryPolicy{Delays: delays}
+}
+
+// RetryTopic mengembalikan nama retry topic untuk percobaan ke-attempt (mulai dari 1)
+func (p RetryPolicy) RetryTopic(baseTopic string, attempt int) string {
+	return baseTopic + ".retry." + formatDelay(p.Delays[attempt-1])
+}
+
+// RetryTopics mengembalikan semua retry topic untuk base topic yang diberikan
+func (p RetryPolicy) RetryTopics(baseTopic string) []string {
+	topics := make([]string, 0, len(p.Delays))
+	for i := range p.Delays {
+		topics = append(topics, p.RetryTopic(baseTopic, i+1))
+	}
+	return topics
+}
+
+// DelayFor mengembalikan jeda untuk sebuah retry topic
+func (p RetryPolicy) DelayFor(topic string) (time.Duration, bool) {
+	idx := strings.LastIndex(topic, ".retry.")
+	if idx < 0 {
+		return 0, false
+	}
+	label := topic[idx+len(".retry."):]
+	for _, d := range p.Delays {
+		if formatDelay(d) == label {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func DLQTopic(baseTopic string) string {
+	return baseTopic + ".dlq"
+}
+
+// BaseTopic menghapus suffix .retry.* / .dlq dari nama topic
+func BaseTopic(topic string) string {
+	if idx := strings.LastIndex(topic, ".retry."); idx >= 0 {
+		return topic[:idx]
+	}
+	return strings.TrimSuffix(topic, ".dlq")
+}
+
+// formatDelay: 1m0s -> 1m, 1h0m0s -> 1h, 30s -> 30s
+func formatDelay(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func attemptFromHeaders(headers map[string]string) int {
+	attempt, _ := strconv.Atoi(headers[headerAttempt])
+	return attempt
+}
@@ -0,0 +1,32 @@
+// internal/eventbus/user_pub.go
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/usecase/port"
+)
+
+type UserPublisher struct {
+	Bus EventBus
+}
+
+func NewUserPublisher(bus EventBus) port.EventPublisher {
+	return &UserPublisher{Bus: bus}
+}
+
+func (k *UserPublisher) PublishUserCreated(ctx context.Context, user *entity.User) error {
+	data := event.UserData{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+	}
+
+	env, err := event.New(ctx, event.TypeUserCreated, fmt.Sprintf("users/%d", user.ID), data)
+	if err != nil {
+		return err
+	}
+	return k.Bus.Publish(ctx, "user-events", env)
+}
@@ -0,0 +1,127 @@
+package gitwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-crud/internal/usecase/port"
+)
+
+// hubProvider membaca payload bergaya GitHub. Gitea memakai format yang sama dengan nama
+// header dan action pull request yang sedikit berbeda.
+type hubProvider struct {
+	name            string
+	eventHeader     string
+	signatureHeader string
+	// signaturePrefix ditulis sebelum hex HMAC, contoh "sha256=" di GitHub
+	signaturePrefix string
+	// prActions adalah action pull request yang menambah commit atau membuka PR
+	prActions map[string]bool
+}
+
+// NewGitHub memverifikasi X-Hub-Signature-256 dan membaca event push dan pull_request
+func NewGitHub() port.GitWebhookProvider {
+	return &hubProvider{
+		name:            "github",
+		eventHeader:     "X-GitHub-Event",
+		signatureHeader: "X-Hub-Signature-256",
+		signaturePrefix: "sha256=",
+		prActions:       map[string]bool{"opened": true, "synchronize": true, "reopened": true},
+	}
+}
+
+// NewGitea memverifikasi X-Gitea-Signature dan membaca event push dan pull_request
+func NewGitea() port.GitWebhookProvider {
+	return &hubProvider{
+		name:            "gitea",
+		eventHeader:     "X-Gitea-Event",
+		signatureHeader: "X-Gitea-Signature",
+		prActions:       map[string]bool{"opened": true, "synchronized": true, "reopened": true},
+	}
+}
+
+type hubRepository struct {
+	CloneURL string `json:"clone_url"`
+	HTMLURL  string `json:"html_url"`
+	SSHURL   string `json:"ssh_url"`
+}
+
+func (r hubRepository) urls() []string {
+	return nonEmpty(r.CloneURL, r.HTMLURL, r.SSHURL)
+}
+
+type hubPush struct {
+	Ref        string        `json:"ref"`
+	Before     string        `json:"before"`
+	After      string        `json:"after"`
+	Repository hubRepository `json:"repository"`
+}
+
+type hubPullRequest struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository hubRepository `json:"repository"`
+}
+
+func (p *hubProvider) Name() string {
+	return p.name
+}
+
+func (p *hubProvider) Verify(header http.Header, body []byte, secret string) bool {
+	signature, ok := strings.CutPrefix(header.Get(p.signatureHeader), p.signaturePrefix)
+	return ok && validHMAC(signature, body, secret)
+}
+
+func (p *hubProvider) Parse(header http.Header, body []byte) (*port.GitEvent, error) {
+	switch event := header.Get(p.eventHeader); event {
+	case "push":
+		var payload hubPush
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode %s push: %w", p.name, err)
+		}
+		return pushEvent(payload.Ref, payload.Before, payload.After, payload.Repository.urls()), nil
+
+	case "pull_request":
+		var payload hubPullRequest
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode %s pull_request: %w", p.name, err)
+		}
+		pr := payload.PullRequest
+		ev := &port.GitEvent{
+			Kind:      port.GitEventPullRequest,
+			CloneURLs: payload.Repository.urls(),
+			// base.sha adalah ujung branch tujuan, checkout menghitung diff dari merge-base
+			Base:    pr.Base.SHA,
+			Head:    pr.Head.SHA,
+			Summary: fmt.Sprintf("pull_request #%d %s", payload.Number, payload.Action),
+		}
+		if !p.prActions[payload.Action] {
+			ev.Ignore = "pull_request action " + payload.Action + " does not change commits"
+		}
+		return ev, nil
+
+	case "":
+		return nil, fmt.Errorf("missing %s header", p.eventHeader)
+	default:
+		// ping dan event lain tetap dijawab supaya provider tidak menandai webhook gagal
+		var payload struct {
+			Repository hubRepository `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode %s %s: %w", p.name, event, err)
+		}
+		return &port.GitEvent{Kind: event, CloneURLs: payload.Repository.urls(), Summary: event,
+			Ignore: "event " + event + " is not handled"}, nil
+	}
+}
@@ -0,0 +1,104 @@
+package gitwebhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go-crud/internal/usecase/port"
+)
+
+type gitlabProvider struct{}
+
+// NewGitLab membaca Push Hook dan Merge Request Hook. GitLab tidak menandatangani payload
+// dengan HMAC, secret webhook dikirim apa adanya di header X-Gitlab-Token.
+func NewGitLab() port.GitWebhookProvider {
+	return gitlabProvider{}
+}
+
+type gitlabProject struct {
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+	WebURL     string `json:"web_url"`
+}
+
+func (p gitlabProject) urls() []string {
+	return nonEmpty(p.GitHTTPURL, p.GitSSHURL, p.WebURL)
+}
+
+type gitlabPush struct {
+	Ref     string        `json:"ref"`
+	Before  string        `json:"before"`
+	After   string        `json:"after"`
+	Project gitlabProject `json:"project"`
+}
+
+type gitlabMergeRequest struct {
+	// Project adalah project tujuan merge request
+	Project          gitlabProject `json:"project"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Action       string `json:"action"`
+		TargetBranch string `json:"target_branch"`
+		// OldRev hanya diisi pada action update yang menambah commit
+		OldRev     string `json:"oldrev"`
+		LastCommit struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+func (gitlabProvider) Name() string {
+	return "gitlab"
+}
+
+func (gitlabProvider) Verify(header http.Header, body []byte, secret string) bool {
+	token := header.Get("X-Gitlab-Token")
+	return secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func (gitlabProvider) Parse(header http.Header, body []byte) (*port.GitEvent, error) {
+	switch event := header.Get("X-Gitlab-Event"); event {
+	case "Push Hook":
+		var payload gitlabPush
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode gitlab push: %w", err)
+		}
+		return pushEvent(payload.Ref, payload.Before, payload.After, payload.Project.urls()), nil
+
+	case "Merge Request Hook":
+		var payload gitlabMergeRequest
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode gitlab merge request: %w", err)
+		}
+		mr := payload.ObjectAttributes
+		ev := &port.GitEvent{
+			Kind:      port.GitEventPullRequest,
+			CloneURLs: payload.Project.urls(),
+			// Payload tidak berisi SHA branch tujuan, nama branch di-resolve saat checkout
+			Base:    mr.TargetBranch,
+			Head:    mr.LastCommit.ID,
+			Summary: fmt.Sprintf("merge_request !%d %s", mr.IID, mr.Action),
+		}
+		switch {
+		case mr.Action == "update" && mr.OldRev == "":
+			ev.Ignore = "merge request updated without new commits"
+		case mr.Action != "open" && mr.Action != "reopen" && mr.Action != "update":
+			ev.Ignore = "merge request action " + mr.Action + " does not change commits"
+		}
+		return ev, nil
+
+	case "":
+		return nil, fmt.Errorf("missing X-Gitlab-Event header")
+	default:
+		var payload struct {
+			Project gitlabProject `json:"project"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("decode gitlab %s: %w", event, err)
+		}
+		return &port.GitEvent{Kind: event, CloneURLs: payload.Project.urls(), Summary: event,
+			Ignore: "event " + event + " is not handled"}, nil
+	}
+}
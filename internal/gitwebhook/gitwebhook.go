@@ -0,0 +1,63 @@
+// Package gitwebhook membaca webhook push dan pull request dari GitHub, GitLab dan Gitea
+// menjadi port.GitEvent. Contoh payload untuk dicoba secara lokal ada di testdata/.
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"go-crud/internal/usecase/port"
+)
+
+// Providers mengembalikan semua provider yang didukung
+func Providers() []port.GitWebhookProvider {
+	return []port.GitWebhookProvider{NewGitHub(), NewGitLab(), NewGitea()}
+}
+
+// zeroSHA menandai branch yang baru dibuat (before) atau dihapus (after) pada event push
+func zeroSHA(sha string) bool {
+	return sha == "" || strings.Trim(sha, "0") == ""
+}
+
+// validHMAC membandingkan signature hex dengan HMAC-SHA256 body dalam waktu konstan
+func validHMAC(signature string, body []byte, secret string) bool {
+	got, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || secret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// branchRef memotong refs/heads/, false untuk ref selain branch (misalnya tag)
+func branchRef(ref string) (string, bool) {
+	return strings.CutPrefix(ref, "refs/heads/")
+}
+
+// pushEvent mengisi event push yang sama untuk semua provider
+func pushEvent(ref, before, after string, urls []string) *port.GitEvent {
+	ev := &port.GitEvent{Kind: port.GitEventPush, CloneURLs: urls, Base: before, Head: after, Summary: "push " + ref}
+	switch _, isBranch := branchRef(ref); {
+	case !isBranch:
+		ev.Ignore = "push to " + ref + " is not a branch"
+	case zeroSHA(after):
+		ev.Ignore = "branch deleted"
+	case zeroSHA(before):
+		// Branch baru tidak punya commit sebelumnya, seluruh isinya di-review
+		ev.Base = ""
+	}
+	return ev
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+const (
+	githubBefore = "6113728f27ae82c7b1a177c8d03f9e96e0adf246"
+	githubAfter  = "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"
+	giteaHead    = "1c3f6c7a04a49f7c2c1aaf1c5ae2b3e4f2c6a8d1"
+	gitlabBefore = "95790bf891e76fee5e1747ab589903a6a1f80f22"
+	gitlabAfter  = "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"
+)
+
+var (
+	githubURLs = []string{"https://github.com/acme/widgets.git", "https://github.com/acme/widgets", "git@github.com:acme/widgets.git"}
+	giteaURLs  = []string{"https://gitea.example.com/acme/widgets.git", "https://gitea.example.com/acme/widgets", "git@gitea.example.com:acme/widgets.git"}
+	gitlabURLs = []string{"https://gitlab.example.com/acme/widgets.git", "git@gitlab.example.com:acme/widgets.git", "https://gitlab.example.com/acme/widgets"}
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func hmacHex(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		provider port.GitWebhookProvider
+		header   string
+		event    string
+		want     port.GitEvent
+	}{
+		{
+			fixture: "github_push.json", provider: NewGitHub(), header: "X-GitHub-Event", event: "push",
+			want: port.GitEvent{Kind: port.GitEventPush, CloneURLs: githubURLs, Base: githubBefore, Head: githubAfter,
+				Summary: "push refs/heads/feature/login"},
+		},
+		{
+			fixture: "github_pull_request.json", provider: NewGitHub(), header: "X-GitHub-Event", event: "pull_request",
+			want: port.GitEvent{Kind: port.GitEventPullRequest, CloneURLs: githubURLs, Base: "9049f1265b7d61be4a8904a9a27120d2064dab3b",
+				Head: githubAfter, Summary: "pull_request #42 synchronize"},
+		},
+		{
+			fixture: "github_ping.json", provider: NewGitHub(), header: "X-GitHub-Event", event: "ping",
+			want: port.GitEvent{Kind: "ping", CloneURLs: githubURLs, Summary: "ping", Ignore: "event ping is not handled"},
+		},
+		{
+			// before berisi nol: branch baru di-review penuh
+			fixture: "gitea_push.json", provider: NewGitea(), header: "X-Gitea-Event", event: "push",
+			want: port.GitEvent{Kind: port.GitEventPush, CloneURLs: giteaURLs, Head: giteaHead, Summary: "push refs/heads/main"},
+		},
+		{
+			fixture: "gitea_pull_request.json", provider: NewGitea(), header: "X-Gitea-Event", event: "pull_request",
+			want: port.GitEvent{Kind: port.GitEventPullRequest, CloneURLs: giteaURLs, Base: giteaHead,
+				Head: "8b7e2f1c9d3a4e5f60718293a4b5c6d7e8f90a1b", Summary: "pull_request #5 opened"},
+		},
+		{
+			fixture: "gitlab_push.json", provider: NewGitLab(), header: "X-Gitlab-Event", event: "Push Hook",
+			want: port.GitEvent{Kind: port.GitEventPush, CloneURLs: gitlabURLs, Base: gitlabBefore, Head: gitlabAfter,
+				Summary: "push refs/heads/main"},
+		},
+		{
+			fixture: "gitlab_merge_request.json", provider: NewGitLab(), header: "X-Gitlab-Event", event: "Merge Request Hook",
+			want: port.GitEvent{Kind: port.GitEventPullRequest, CloneURLs: gitlabURLs, Base: "main", Head: gitlabAfter,
+				Summary: "merge_request !7 update"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(tc.header, tc.event)
+
+			ev, err := tc.provider.Parse(header, readFixture(t, tc.fixture))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*ev, tc.want) {
+				t.Errorf("Parse = %+v, want %+v", *ev, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoredEvents(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name     string
+		provider port.GitWebhookProvider
+		header   string
+		event    string
+		body     string
+		want     string
+	}{
+		{"tag push", NewGitHub(), "X-GitHub-Event", "push",
+			`{"ref":"refs/tags/v1.0.0","before":"` + zero + `","after":"` + githubAfter + `"}`, "push to refs/tags/v1.0.0 is not a branch"},
+		{"branch deleted", NewGitea(), "X-Gitea-Event", "push",
+			`{"ref":"refs/heads/old","before":"` + githubAfter + `","after":"` + zero + `"}`, "branch deleted"},
+		{"pull request closed", NewGitHub(), "X-GitHub-Event", "pull_request",
+			`{"action":"closed","number":42}`, "pull_request action closed does not change commits"},
+		{"gitea uses synchronized", NewGitea(), "X-Gitea-Event", "pull_request",
+			`{"action":"synchronize","number":5}`, "pull_request action synchronize does not change commits"},
+		{"merge request update without commits", NewGitLab(), "X-Gitlab-Event", "Merge Request Hook",
+			`{"object_attributes":{"iid":7,"action":"update"}}`, "merge request updated without new commits"},
+		{"merge request merged", NewGitLab(), "X-Gitlab-Event", "Merge Request Hook",
+			`{"object_attributes":{"iid":7,"action":"merge"}}`, "merge request action merge does not change commits"},
+		{"gitlab tag push", NewGitLab(), "X-Gitlab-Event", "Tag Push Hook",
+			`{"ref":"refs/tags/v1.0.0"}`, "event Tag Push Hook is not handled"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(tc.header, tc.event)
+
+			ev, err := tc.provider.Parse(header, []byte(tc.body))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if ev.Ignore != tc.want {
+				t.Errorf("Ignore = %q, want %q", ev.Ignore, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidRequests(t *testing.T) {
+	for _, p := range Providers() {
+		t.Run(p.Name()+"/missing event header", func(t *testing.T) {
+			if ev, err := p.Parse(http.Header{}, []byte(`{}`)); err == nil {
+				t.Errorf("Parse succeeded with %+v, want error", ev)
+			}
+		})
+	}
+
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	if _, err := NewGitHub().Parse(header, []byte(`{"ref":`)); err == nil {
+		t.Error("Parse of invalid JSON succeeded, want error")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	body := readFixture(t, "github_push.json")
+	signature := hmacHex(body, secret)
+
+	tests := []struct {
+		name     string
+		provider port.GitWebhookProvider
+		header   string
+		value    string
+		body     []byte
+		secret   string
+		want     bool
+	}{
+		{"github valid", NewGitHub(), "X-Hub-Signature-256", "sha256=" + signature, body, secret, true},
+		{"github wrong secret", NewGitHub(), "X-Hub-Signature-256", "sha256=" + signature, body, "other", false},
+		{"github tampered body", NewGitHub(), "X-Hub-Signature-256", "sha256=" + signature, append([]byte(" "), body...), secret, false},
+		{"github without prefix", NewGitHub(), "X-Hub-Signature-256", signature, body, secret, false},
+		{"github not hex", NewGitHub(), "X-Hub-Signature-256", "sha256=zz", body, secret, false},
+		{"github empty secret", NewGitHub(), "X-Hub-Signature-256", "sha256=" + hmacHex(body, ""), body, "", false},
+		{"github missing header", NewGitHub(), "X-Other", "sha256=" + signature, body, secret, false},
+		{"gitea valid", NewGitea(), "X-Gitea-Signature", signature, body, secret, true},
+		{"gitea wrong secret", NewGitea(), "X-Gitea-Signature", signature, body, "other", false},
+		{"gitea github header", NewGitea(), "X-Hub-Signature-256", "sha256=" + signature, body, secret, false},
+		{"gitlab valid", NewGitLab(), "X-Gitlab-Token", secret, body, secret, true},
+		{"gitlab wrong token", NewGitLab(), "X-Gitlab-Token", "other", body, secret, false},
+		{"gitlab empty secret", NewGitLab(), "X-Gitlab-Token", "", body, "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(tc.header, tc.value)
+			if got := tc.provider.Verify(header, tc.body, tc.secret); got != tc.want {
+				t.Errorf("Verify = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
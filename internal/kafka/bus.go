@@ -0,0 +1,213 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"go-crud/internal/event"
+	"go-crud/internal/eventbus"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// KafkaBus adalah implementasi eventbus.EventBus di atas confluent-kafka-go
+type KafkaBus struct {
+	Producer *KafkaProducer
+	broker   string
+	groupID  string
+
+	mu       sync.Mutex
+	consumer *kafka.Consumer
+}
+
+var _ eventbus.EventBus = (*KafkaBus)(nil)
+
+func NewKafkaBus(broker, groupID string) (*KafkaBus, error) {
+	producer, err := NewKafkaProducer(broker)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaBus{Producer: producer, broker: broker, groupID: groupID}, nil
+}
+
+func (b *KafkaBus) Publish(ctx context.Context, topic string, env *event.Envelope) error {
+	return b.Producer.PublishEnvelope(topic, env)
+}
+
+func (b *KafkaBus) Forward(ctx context.Context, msg *eventbus.Message, topic string, headers map[string]string) error {
+	merged := make(map[string]string, len(msg.Headers)+len(headers))
+	for k, v := range msg.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+
+	kafkaHeaders := make([]kafka.Header, 0, len(merged))
+	for k, v := range merged {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: k, Value: []byte(v)})
+	}
+
+	return b.Producer.produceAndWait(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: int32(kafka.PartitionAny),
+		},
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: kafkaHeaders,
+	})
+}
+
+// Subscribe membuat consumer group dan membaca pesan satu per satu. Offset di-commit
+// manual lewat Ack, jadi pesan yang belum di-Ack akan dibaca ulang setelah restart.
+func (b *KafkaBus) Subscribe(ctx context.Context, topics []string, handler eventbus.Handler) error {
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  b.broker,
+		"group.id":           b.groupID,
+		"auto.offset.reset":  "latest", // atau "latest" tergantung kebutuhan
+		"enable.auto.commit": false,    // ✅ Offset di-commit manual setelah event selesai diproses
+		// Topic yang belum ada dibuat saat subscribe (butuh auto.create.topics.enable di broker)
+		"allow.auto.create.topics": true,
+	})
+	if err != nil {
+		log.Printf("❌ Error creating consumer: %v\n", err)
+		return err
+	}
+
+	b.mu.Lock()
+	if b.consumer != nil {
+		b.mu.Unlock()
+		c.Close()
+		return fmt.Errorf("kafka bus already has a subscriber")
+	}
+	b.consumer = c
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.consumer = nil
+		b.mu.Unlock()
+		c.Close()
+	}()
+
+	if err := c.SubscribeTopics(topics, nil); err != nil {
+		log.Printf("❌ Error subscribing to topics: %v\n", err)
+		return err
+	}
+	log.Printf("✅ Kafka consumer subscribed to topics: %v\n", topics)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			msg, err := c.ReadMessage(time.Second)
+			if err != nil {
+				// Timeout biasa supaya ctx.Done() tetap dicek
+				if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.IsTimeout() {
+					continue
+				}
+				log.Printf("⚠️ Error reading message: %v\n", err)
+				continue
+			}
+
+			handler(ctx, b.toMessage(msg))
+		}
+	}
+}
+
+func (b *KafkaBus) toMessage(msg *kafka.Message) *eventbus.Message {
+	m := &eventbus.Message{
+		Topic:     *msg.TopicPartition.Topic,
+		Partition: int(msg.TopicPartition.Partition),
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   make(map[string]string, len(msg.Headers)),
+		Timestamp: msg.Timestamp,
+	}
+	for _, h := range msg.Headers {
+		m.Headers[h.Key] = string(h.Value)
+	}
+
+	env, err := decodeEnvelope(msg, b.Producer.serializers)
+	if err != nil {
+		m.DecodeErr = err
+	} else {
+		m.Envelope = env
+	}
+	return m
+}
+
+func (b *KafkaBus) Ack(msg *eventbus.Message) error {
+	c, err := b.activeConsumer()
+	if err != nil {
+		return err
+	}
+	_, err = c.CommitOffsets([]kafka.TopicPartition{{
+		Topic:     &msg.Topic,
+		Partition: int32(msg.Partition),
+		Offset:    kafka.Offset(msg.Offset + 1),
+	}})
+	return err
+}
+
+// Nack menahan partition lalu membaca ulang pesan yang sama setelah delay
+func (b *KafkaBus) Nack(msg *eventbus.Message, delay time.Duration) error {
+	c, err := b.activeConsumer()
+	if err != nil {
+		return err
+	}
+
+	tp := kafka.TopicPartition{
+		Topic:     &msg.Topic,
+		Partition: int32(msg.Partition),
+		Offset:    kafka.Offset(msg.Offset),
+	}
+	partitions := []kafka.TopicPartition{tp}
+	if err := c.Pause(partitions); err != nil {
+		return fmt.Errorf("pause %s: %w", msg.Topic, err)
+	}
+	// Baca ulang pesan yang sama setelah partition di-resume
+	if err := c.Seek(tp, 0); err != nil {
+		log.Printf("⚠️ Failed to seek %s: %v\n", msg.Topic, err)
+	}
+
+	time.AfterFunc(delay, func() {
+		if err := c.Resume(partitions); err != nil {
+			log.Printf("⚠️ Failed to resume %s: %v\n", msg.Topic, err)
+		}
+	})
+	return nil
+}
+
+func (b *KafkaBus) activeConsumer() (*kafka.Consumer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.consumer == nil {
+		return nil, fmt.Errorf("kafka bus has no active subscriber")
+	}
+	return b.consumer, nil
+}
+
+func (b *KafkaBus) Close() {
+	b.Producer.Close()
+}
+
+// ✨ Ambil nilai "eventType" dari headers
+func getEventTypeFromHeaders(headers []kafka.Header) string {
+	return getHeader(headers, eventbus.HeaderEventType)
+}
+
+func getHeader(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/event"
+	"go-crud/internal/eventbus"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Mode CloudEvents Kafka protocol binding
+const (
+	ModeStructured = "structured"
+	ModeBinary     = "binary"
+)
+
+const (
+	contentTypeHeader      = "content-type"
+	contentTypeCloudEvents = "application/cloudevents+json"
+	ceHeaderPrefix         = "ce_"
+)
+
+// cloudEventsModeFromEnv membaca KAFKA_CLOUDEVENTS_MODE, default structured
+func cloudEventsModeFromEnv() string {
+	if strings.EqualFold(os.Getenv("KAFKA_CLOUDEVENTS_MODE"), ModeBinary) {
+		return ModeBinary
+	}
+	return ModeStructured
+}
+
+// encodeEnvelope membentuk pesan Kafka sesuai mode dan serializer topic. Header
+// eventType tetap dikirim untuk consumer lama.
+func encodeEnvelope(topic string, env *event.Envelope, mode string, serializers *Serializers) (*kafka.Message, error) {
+	ser := serializers.ForTopic(topic)
+	data, err := ser.Serialize(env.Type, env.Data)
+	if err != nil {
+		return nil, fmt.Errorf("serialize %s data: %w", env.Type, err)
+	}
+
+	msg := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: int32(kafka.PartitionAny),
+		},
+		Headers: []kafka.Header{
+			{Key: eventbus.HeaderEventType, Value: []byte(env.Type)},
+		},
+	}
+	if env.Subject != "" {
+		msg.Key = []byte(env.Subject)
+	}
+
+	if mode == ModeBinary {
+		msg.Value = data
+		msg.Headers = append(msg.Headers, kafka.Header{Key: contentTypeHeader, Value: []byte(ser.ContentType())})
+		for key, value := range binaryAttributes(env) {
+			if value != "" {
+				msg.Headers = append(msg.Headers, kafka.Header{Key: ceHeaderPrefix + key, Value: []byte(value)})
+			}
+		}
+		return msg, nil
+	}
+
+	structured := *env
+	if ser.ContentType() != event.ContentTypeJSON {
+		structured.Data = nil
+		structured.DataBase64 = data
+		structured.DataContentType = ser.ContentType()
+	}
+
+	value, err := json.Marshal(&structured)
+	if err != nil {
+		return nil, err
+	}
+	msg.Value = value
+	msg.Headers = append(msg.Headers, kafka.Header{Key: contentTypeHeader, Value: []byte(contentTypeCloudEvents)})
+	return msg, nil
+}
+
+func binaryAttributes(env *event.Envelope) map[string]string {
+	return map[string]string{
+		"specversion":   env.SpecVersion,
+		"id":            env.ID,
+		"type":          env.Type,
+		"source":        env.Source,
+		"subject":       env.Subject,
+		"time":          env.Time.Format(time.RFC3339Nano),
+		"dataschema":    env.DataSchema,
+		"schemaversion": strconv.Itoa(env.SchemaVersion),
+		"traceparent":   env.TraceParent,
+		"tracestate":    env.TraceState,
+		"commandid":     env.CommandID,
+	}
+}
+
+// decodeEnvelope mengenali structured mode, binary mode, dan payload lama
+// (tanpa envelope), mengembalikan data ke JSON, lalu meng-upcast data ke
+// schema version terbaru.
+func decodeEnvelope(msg *kafka.Message, serializers *Serializers) (*event.Envelope, error) {
+	var env *event.Envelope
+
+	switch {
+	case strings.HasPrefix(getHeader(msg.Headers, contentTypeHeader), contentTypeCloudEvents):
+		env = &event.Envelope{}
+		if err := json.Unmarshal(msg.Value, env); err != nil {
+			return nil, fmt.Errorf("decode structured cloudevent: %w", err)
+		}
+		if len(env.DataBase64) > 0 {
+			if err := deserializeData(env, env.DataBase64, serializers); err != nil {
+				return nil, err
+			}
+		}
+
+	case getHeader(msg.Headers, ceHeaderPrefix+"specversion") != "":
+		decoded, err := decodeBinary(msg)
+		if err != nil {
+			return nil, err
+		}
+		if err := deserializeData(decoded, msg.Value, serializers); err != nil {
+			return nil, err
+		}
+		env = decoded
+
+	default:
+		legacy, err := event.FromLegacy(getEventTypeFromHeaders(msg.Headers), msg.Value)
+		if err != nil {
+			return nil, err
+		}
+		env = legacy
+	}
+
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+	if err := event.Upcast(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// deserializeData mengubah data sesuai DataContentType ke JSON
+func deserializeData(env *event.Envelope, payload []byte, serializers *Serializers) error {
+	ser, err := serializers.ForContentType(env.DataContentType)
+	if err != nil {
+		return err
+	}
+	data, err := ser.Deserialize(env.Type, payload)
+	if err != nil {
+		return fmt.Errorf("deserialize %s data: %w", env.Type, err)
+	}
+	env.Data = data
+	env.DataBase64 = nil
+	env.DataContentType = event.ContentTypeJSON
+	return nil
+}
+
+func decodeBinary(msg *kafka.Message) (*event.Envelope, error) {
+	h := func(key string) string {
+		return getHeader(msg.Headers, ceHeaderPrefix+key)
+	}
+
+	env := &event.Envelope{
+		SpecVersion:     h("specversion"),
+		ID:              h("id"),
+		Type:            h("type"),
+		Source:          h("source"),
+		Subject:         h("subject"),
+		DataContentType: getHeader(msg.Headers, contentTypeHeader),
+		DataSchema:      h("dataschema"),
+		TraceParent:     h("traceparent"),
+		TraceState:      h("tracestate"),
+		CommandID:       h("commandid"),
+		Data:            msg.Value,
+	}
+
+	if raw := h("time"); raw != "" {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ce_time: %w", err)
+		}
+		env.Time = t
+	}
+
+	version, err := strconv.Atoi(h("schemaversion"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ce_schemaversion: %w", err)
+	}
+	env.SchemaVersion = version
+
+	return env, nil
+}
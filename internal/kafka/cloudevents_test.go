@@ -0,0 +1,163 @@
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-crud/internal/event"
+	"go-crud/internal/eventbus"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+
+	for _, mode := range []string{ModeStructured, ModeBinary} {
+		for _, topic := range []string{"json-events", "avro-events", "protobuf-events"} {
+			for _, tc := range testEvents {
+				t.Run(mode+"/"+topic+"/"+tc.eventType, func(t *testing.T) {
+					env, err := event.New(context.Background(), tc.eventType, "users/7", tc.data)
+					if err != nil {
+						t.Fatal(err)
+					}
+					env.CommandID = "cmd-1"
+
+					msg, err := encodeEnvelope(topic, env, mode, s)
+					if err != nil {
+						t.Fatalf("encodeEnvelope: %v", err)
+					}
+					if got := getEventTypeFromHeaders(msg.Headers); got != tc.eventType {
+						t.Errorf("eventType header = %q, want %q", got, tc.eventType)
+					}
+					if string(msg.Key) != env.Subject {
+						t.Errorf("key = %q, want %q", msg.Key, env.Subject)
+					}
+
+					got, err := decodeEnvelope(msg, s)
+					if err != nil {
+						t.Fatalf("decodeEnvelope: %v", err)
+					}
+					if got.ID != env.ID || got.Type != env.Type || got.Subject != env.Subject || got.CommandID != env.CommandID {
+						t.Errorf("attributes = %+v, want %+v", got, env)
+					}
+					if got.SchemaVersion != event.CurrentSchemaVersion(tc.eventType) || got.DataSchema != env.DataSchema {
+						t.Errorf("schema = v%d %s, want v%d %s", got.SchemaVersion, got.DataSchema, env.SchemaVersion, env.DataSchema)
+					}
+					if !got.Time.Equal(env.Time) {
+						t.Errorf("time = %s, want %s", got.Time, env.Time)
+					}
+					if got.DataContentType != event.ContentTypeJSON || len(got.DataBase64) != 0 {
+						t.Errorf("data not converted back to JSON: %s %v", got.DataContentType, got.DataBase64)
+					}
+					if data := decodeAs(t, tc.data, got.Data); !reflect.DeepEqual(data, tc.data) {
+						t.Errorf("data = %+v, want %+v", data, tc.data)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestDecodeLegacyPayload(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+
+	tests := []struct {
+		name     string
+		header   string
+		payload  string
+		wantType string
+		wantID   string
+		wantCmd  string
+		want     any
+	}{
+		{
+			name:     "user with eventType header",
+			header:   event.TypeUserCreated,
+			payload:  `{"event":"user.created","event_id":"evt-1","command_id":"cmd-1","name":"Budi","email":"budi@example.com","created_at":"2024-01-01T00:00:00Z"}`,
+			wantType: event.TypeUserCreated,
+			wantID:   "evt-1",
+			wantCmd:  "cmd-1",
+			want:     event.UserData{Name: "Budi", Email: "budi@example.com"},
+		},
+		{
+			name:     "event type from payload",
+			payload:  `{"event":"repository.updated","event_id":"evt-2","id":3,"user_id":7,"name":"go-crud","url":"https://github.com/acme/go-crud","ai_enabled":true}`,
+			wantType: event.TypeRepositoryUpdated,
+			wantID:   "evt-2",
+			want:     event.RepositoryData{ID: 3, UserID: 7, Name: "go-crud", URL: "https://github.com/acme/go-crud", AIEnabled: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &kafka.Message{Value: []byte(tc.payload)}
+			if tc.header != "" {
+				msg.Headers = []kafka.Header{{Key: eventbus.HeaderEventType, Value: []byte(tc.header)}}
+			}
+
+			env, err := decodeEnvelope(msg, s)
+			if err != nil {
+				t.Fatalf("decodeEnvelope: %v", err)
+			}
+			if env.Type != tc.wantType || env.ID != tc.wantID || env.CommandID != tc.wantCmd {
+				t.Errorf("envelope = %s %s %s, want %s %s %s", env.Type, env.ID, env.CommandID, tc.wantType, tc.wantID, tc.wantCmd)
+			}
+			if env.SchemaVersion != event.CurrentSchemaVersion(tc.wantType) {
+				t.Errorf("schema version = %d, want upcast to %d", env.SchemaVersion, event.CurrentSchemaVersion(tc.wantType))
+			}
+			if data := decodeAs(t, tc.want, env.Data); !reflect.DeepEqual(data, tc.want) {
+				t.Errorf("data = %+v, want %+v", data, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEnvelopeRejectsInvalidMessages(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+	structured := []kafka.Header{{Key: contentTypeHeader, Value: []byte(contentTypeCloudEvents)}}
+
+	tests := map[string]*kafka.Message{
+		"legacy without event type": {Value: []byte(`{"name":"Budi"}`)},
+		"legacy not JSON":           {Value: []byte{0x0a, 0xff}},
+		"structured without id": {
+			Headers: structured,
+			Value:   []byte(`{"specversion":"1.0","type":"user.created","source":"/go-crud","schemaversion":1,"data":{}}`),
+		},
+		"structured newer schema version": {
+			Headers: structured,
+			Value:   []byte(`{"specversion":"1.0","id":"e1","type":"user.created","source":"/go-crud","schemaversion":99,"data":{}}`),
+		},
+		"binary with bad time": {
+			Headers: []kafka.Header{
+				{Key: ceHeaderPrefix + "specversion", Value: []byte("1.0")},
+				{Key: ceHeaderPrefix + "id", Value: []byte("e1")},
+				{Key: ceHeaderPrefix + "type", Value: []byte(event.TypeUserCreated)},
+				{Key: ceHeaderPrefix + "source", Value: []byte(event.Source)},
+				{Key: ceHeaderPrefix + "time", Value: []byte("yesterday")},
+				{Key: ceHeaderPrefix + "schemaversion", Value: []byte("1")},
+			},
+			Value: []byte(`{}`),
+		},
+		"binary with unknown content type": {
+			Headers: []kafka.Header{
+				{Key: contentTypeHeader, Value: []byte("application/xml")},
+				{Key: ceHeaderPrefix + "specversion", Value: []byte("1.0")},
+				{Key: ceHeaderPrefix + "id", Value: []byte("e1")},
+				{Key: ceHeaderPrefix + "type", Value: []byte(event.TypeUserCreated)},
+				{Key: ceHeaderPrefix + "source", Value: []byte(event.Source)},
+				{Key: ceHeaderPrefix + "time", Value: []byte(time.Now().Format(time.RFC3339Nano))},
+				{Key: ceHeaderPrefix + "schemaversion", Value: []byte("1")},
+			},
+			Value: []byte(`<user/>`),
+		},
+	}
+	for name, msg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if env, err := decodeEnvelope(msg, s); err == nil {
+				t.Errorf("decodeEnvelope succeeded with %+v, want error", env)
+			}
+		})
+	}
+}
@@ -1,18 +1,26 @@
 package kafka
 
 import (
-	"encoding/json"
-	"log"
+	"fmt"
+	"go-crud/internal/event"
 	"os"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
 type KafkaProducer struct {
-	Producer *kafka.Producer
+	Producer    *kafka.Producer
+	mode        string
+	serializers *Serializers
 }
 
 func NewKafkaProducer(broker string) (*KafkaProducer, error) {
+	// Schema dicek sebelum konek ke broker supaya perubahan yang tidak kompatibel langsung gagal
+	serializers, err := NewSerializersFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	compression := os.Getenv("KAFKA_COMPRESSION")
 	brokerKafka := os.Getenv("KAFKA_BROKER")
 	p, err := kafka.NewProducer(&kafka.ConfigMap{
@@ -23,79 +31,34 @@ func NewKafkaProducer(broker string) (*KafkaProducer, error) {
 		return nil, err
 	}
 
-	return &KafkaProducer{Producer: p}, nil
+	return &KafkaProducer{Producer: p, mode: cloudEventsModeFromEnv(), serializers: serializers}, nil
 }
 
-func (kp *KafkaProducer) Publish(topic string, message interface{}, eventType string) error {
-	payload := make(map[string]interface{})
-
-	// Merge isi message ke payload
-	if msgMap, ok := message.(map[string]interface{}); ok {
-		for k, v := range msgMap {
-			payload[k] = v
-		}
-	} else {
-		
-		messageBytes, _ := json.Marshal(message)
-	log.Printf("📦 Message JSON before Unmarshal: %s\n", string(messageBytes))
-
-		json.Unmarshal(messageBytes, &payload)
+// PublishEnvelope mengirim envelope dan menunggu delivery report dari broker. Semua pesan
+// keluar lewat sini atau Forward supaya kegagalan kirim selalu sampai ke pemanggil.
+func (kp *KafkaProducer) PublishEnvelope(topic string, env *event.Envelope) error {
+	msg, err := encodeEnvelope(topic, env, kp.mode, kp.serializers)
+	if err != nil {
+		return err
 	}
+	return kp.produceAndWait(msg)
+}
 
-	// Set eventType setelah merge (jadi tidak bisa ditimpa)
-	payload["event"] = eventType
+func (kp *KafkaProducer) produceAndWait(msg *kafka.Message) error {
+	deliveryChan := make(chan kafka.Event, 1)
 
-	finalBytes, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("❌ Failed to marshal final payload: %v", err)
+	if err := kp.Producer.Produce(msg, deliveryChan); err != nil {
 		return err
 	}
 
-	log.Printf("🧪 Final payload before sending to Kafka: %s\n", string(finalBytes))
-
-	return kp.Producer.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{
-			Topic:     &topic,
-			Partition: int32(kafka.PartitionAny),
-		},
-		Value: finalBytes,
-		Headers: []kafka.Header{
-			{Key: "eventType", Value: []byte(eventType)},
-		},
-	}, nil)
+	e := <-deliveryChan
+	m, ok := e.(*kafka.Message)
+	if !ok {
+		return fmt.Errorf("unexpected delivery event: %v", e)
+	}
+	return m.TopicPartition.Error
 }
 
 func (kp *KafkaProducer) Close() {
 	kp.Producer.Close()
 }
-// func (kp *KafkaProducer) Publish(topic string, message interface{}, eventType string) error {
-// 	// Pastikan eventType ikut dimasukkan ke dalam message
-// 	payload := map[string]interface{}{
-// 		"event": eventType,
-// 	}
-
-// 	// Ambil isi message dan merge ke payload
-// 	messageBytes, _ := json.Marshal(message)
-// 	json.Unmarshal(messageBytes, &payload)
-
-// 	finalBytes, err := json.Marshal(payload)
-// 	if err != nil {
-// 		log.Printf("❌ Failed to marshal final payload: %v", err)
-// 		return err
-// 	}
-
-// 	return kp.Producer.Produce(&kafka.Message{
-// 		TopicPartition: kafka.TopicPartition{
-// 			Topic:     &topic,
-// 			Partition: int32(kafka.PartitionAny),
-// 		},
-// 		Value: finalBytes,
-// 		Headers: []kafka.Header{
-// 			{Key: "eventType", Value: []byte(eventType)},
-// 		},
-// 	}, nil)
-// }
-
-// func (kp *KafkaProducer) Close() {
-// 	kp.Producer.Close()
-// }
\ No newline at end of file
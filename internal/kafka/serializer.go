@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/event"
+	"go-crud/internal/eventbus"
+	"go-crud/internal/schemaregistry"
+	"os"
+	"strings"
+)
+
+// Format serializer data event per topic
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeAvro     = "application/avro"
+)
+
+// ErrSchemaRegistry menandai schema data yang tidak cocok dengan registry
+var ErrSchemaRegistry = errors.New("schema registry check failed")
+
+// Serializer mengubah data envelope (JSON) ke format wire topic dan sebaliknya
+type Serializer interface {
+	ContentType() string
+	Serialize(eventType string, data json.RawMessage) ([]byte, error)
+	Deserialize(eventType string, payload []byte) (json.RawMessage, error)
+}
+
+// Serializers memilih serializer per topic (untuk produce) dan per content type (untuk consume)
+type Serializers struct {
+	topics        map[string]Serializer
+	byContentType map[string]Serializer
+	fallback      Serializer
+}
+
+// NewSerializersFromEnv membaca KAFKA_TOPIC_FORMATS, contoh
+// "user-events=protobuf,repository-events=avro". Topic yang tidak disebut memakai JSON.
+// Jika ada format biner, schema data yang dikompilasi ke binary diverifikasi terhadap
+// registry di SCHEMA_REGISTRY_DIR supaya perubahan yang tidak kompatibel gagal saat startup.
+func NewSerializersFromEnv() (*Serializers, error) {
+	formats, err := parseTopicFormats(os.Getenv("KAFKA_TOPIC_FORMATS"))
+	if err != nil {
+		return nil, err
+	}
+
+	registryDir := os.Getenv("SCHEMA_REGISTRY_DIR")
+	if registryDir == "" {
+		registryDir = "schemas/registry"
+	}
+	return NewSerializers(formats, registryDir)
+}
+
+func NewSerializers(topicFormats map[string]string, registryDir string) (*Serializers, error) {
+	s := &Serializers{
+		topics:        make(map[string]Serializer),
+		byContentType: make(map[string]Serializer),
+		fallback:      jsonSerializer{},
+	}
+	s.byContentType[event.ContentTypeJSON] = s.fallback
+
+	needsRegistry := false
+	for _, format := range topicFormats {
+		if format != FormatJSON {
+			needsRegistry = true
+		}
+	}
+	if needsRegistry {
+		schemas, err := loadDataSchemas(registryDir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSchemaRegistry, err)
+		}
+		s.byContentType[contentTypeAvro] = &avroSerializer{schemas: schemas}
+		s.byContentType[contentTypeProtobuf] = &protobufSerializer{schemas: schemas}
+	}
+
+	for topic, format := range topicFormats {
+		switch format {
+		case FormatJSON:
+			s.topics[topic] = s.fallback
+		case FormatAvro:
+			s.topics[topic] = s.byContentType[contentTypeAvro]
+		case FormatProtobuf:
+			s.topics[topic] = s.byContentType[contentTypeProtobuf]
+		}
+	}
+	return s, nil
+}
+
+func parseTopicFormats(raw string) (map[string]string, error) {
+	formats := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		topic, format, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid KAFKA_TOPIC_FORMATS entry %q, expected topic=format", pair)
+		}
+		format = strings.ToLower(strings.TrimSpace(format))
+		switch format {
+		case FormatJSON, FormatProtobuf, FormatAvro:
+		default:
+			return nil, fmt.Errorf("unknown serializer format %q for topic %s", format, topic)
+		}
+		formats[strings.TrimSpace(topic)] = format
+	}
+	return formats, nil
+}
+
+// ForTopic mengembalikan serializer topic. Retry topic dan DLQ mengikuti topic asalnya.
+func (s *Serializers) ForTopic(topic string) Serializer {
+	if ser, ok := s.topics[eventbus.BaseTopic(topic)]; ok {
+		return ser
+	}
+	return s.fallback
+}
+
+// ForContentType mengembalikan serializer untuk content type pesan yang diterima
+func (s *Serializers) ForContentType(contentType string) (Serializer, error) {
+	if contentType == "" {
+		return s.fallback, nil
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	if ser, ok := s.byContentType[strings.TrimSpace(mediaType)]; ok {
+		return ser, nil
+	}
+	return nil, fmt.Errorf("unsupported data content type %q", contentType)
+}
+
+// dataSchemas menyimpan schema data yang dikompilasi ke binary beserta registry-nya
+type dataSchemas struct {
+	registry *schemaregistry.Registry
+	byEvent  map[string]*schemaregistry.Schema
+}
+
+func loadDataSchemas(registryDir string) (*dataSchemas, error) {
+	registry, err := schemaregistry.Open(registryDir)
+	if err != nil {
+		return nil, fmt.Errorf("open schema registry: %w", err)
+	}
+
+	parsed := make(map[string]*schemaregistry.Schema)
+	byEvent := make(map[string]*schemaregistry.Schema)
+	for eventType, raw := range event.DataSchemas() {
+		schema, ok := parsed[raw]
+		if !ok {
+			schema, err = schemaregistry.Parse([]byte(raw))
+			if err != nil {
+				return nil, err
+			}
+			if err := registry.Verify(schema); err != nil {
+				return nil, err
+			}
+			parsed[raw] = schema
+		}
+		byEvent[eventType] = schema
+	}
+
+	return &dataSchemas{registry: registry, byEvent: byEvent}, nil
+}
+
+func (d *dataSchemas) forEvent(eventType string) (*schemaregistry.Schema, error) {
+	schema, ok := d.byEvent[eventType]
+	if !ok {
+		return nil, fmt.Errorf("no data schema for event type %s", eventType)
+	}
+	return schema, nil
+}
+
+// jsonSerializer tidak mengubah data, envelope sudah menyimpan data sebagai JSON
+type jsonSerializer struct{}
+
+func (jsonSerializer) ContentType() string { return event.ContentTypeJSON }
+
+func (jsonSerializer) Serialize(_ string, data json.RawMessage) ([]byte, error) {
+	return data, nil
+}
+
+func (jsonSerializer) Deserialize(_ string, payload []byte) (json.RawMessage, error) {
+	if !json.Valid(payload) {
+		return nil, fmt.Errorf("payload is not valid JSON")
+	}
+	return payload, nil
+}
+
+// recordFromJSON mengubah data JSON ke record sesuai schema. Field yang tidak ada
+// di schema dan nilai dengan tipe yang salah ditolak; field yang kosong diisi default.
+func recordFromJSON(schema *schemaregistry.Schema, data json.RawMessage) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode %s data: %w", schema.FullName(), err)
+	}
+
+	record := make(map[string]interface{}, len(schema.Fields))
+	for _, field := range schema.Fields {
+		value, ok := raw[field.Name]
+		delete(raw, field.Name)
+		if !ok || value == nil {
+			def, err := field.DefaultValue()
+			if err != nil {
+				return nil, err
+			}
+			record[field.Name] = def
+			continue
+		}
+
+		coerced, err := schemaregistry.Coerce(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", schema.FullName(), field.Name, err)
+		}
+		record[field.Name] = coerced
+	}
+
+	for name := range raw {
+		return nil, fmt.Errorf("%s has no field %s", schema.FullName(), name)
+	}
+	return record, nil
+}
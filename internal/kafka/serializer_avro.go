@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/schemaregistry"
+	"math"
+)
+
+// avroMagicByte mengawali payload Avro, diikuti 4 byte schema ID (fingerprint di registry)
+const avroMagicByte = 0x0
+
+// avroSerializer menulis data dengan schema yang dikompilasi ke binary dan membaca
+// data dengan schema penulis dari registry, jadi versi lama dan baru tetap bisa dibaca.
+type avroSerializer struct {
+	schemas *dataSchemas
+}
+
+func (s *avroSerializer) ContentType() string { return contentTypeAvro }
+
+func (s *avroSerializer) Serialize(eventType string, data json.RawMessage) ([]byte, error) {
+	schema, err := s.schemas.forEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+	record, err := recordFromJSON(schema, data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := []byte{avroMagicByte}
+	buf = binary.BigEndian.AppendUint32(buf, schema.Fingerprint())
+	for _, field := range schema.Fields {
+		buf = appendAvroValue(buf, field.Type, record[field.Name])
+	}
+	return buf, nil
+}
+
+func (s *avroSerializer) Deserialize(eventType string, payload []byte) (json.RawMessage, error) {
+	reader, err := s.schemas.forEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < 5 || payload[0] != avroMagicByte {
+		return nil, fmt.Errorf("avro payload has no schema header")
+	}
+
+	id := binary.BigEndian.Uint32(payload[1:5])
+	writer, ok := s.schemas.registry.ByID(id)
+	if !ok {
+		return nil, fmt.Errorf("avro schema %08x is not registered", id)
+	}
+	if writer.FullName() != reader.FullName() {
+		return nil, fmt.Errorf("avro payload written with %s, expected %s", writer.FullName(), reader.FullName())
+	}
+
+	written := make(map[string]interface{}, len(writer.Fields))
+	buf := payload[5:]
+	for _, field := range writer.Fields {
+		value, n, err := readAvroValue(buf, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", writer.FullName(), field.Name, err)
+		}
+		written[field.Name] = value
+		buf = buf[n:]
+	}
+	if len(buf) != 0 {
+		return nil, fmt.Errorf("avro payload has %d trailing bytes", len(buf))
+	}
+
+	// Resolusi schema: ambil field milik reader, field yang tidak ditulis diisi default
+	record := make(map[string]interface{}, len(reader.Fields))
+	for _, field := range reader.Fields {
+		if value, ok := written[field.Name]; ok {
+			record[field.Name] = value
+			continue
+		}
+		def, err := field.DefaultValue()
+		if err != nil {
+			return nil, err
+		}
+		record[field.Name] = def
+	}
+	return json.Marshal(record)
+}
+
+func appendAvroValue(buf []byte, fieldType string, value interface{}) []byte {
+	switch fieldType {
+	case schemaregistry.TypeInt, schemaregistry.TypeLong:
+		// Avro int/long memakai zig-zag varint, sama dengan binary.AppendVarint
+		return binary.AppendVarint(buf, value.(int64))
+	case schemaregistry.TypeBoolean:
+		if value.(bool) {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case schemaregistry.TypeDouble:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(value.(float64)))
+	default:
+		str := value.(string)
+		buf = binary.AppendVarint(buf, int64(len(str)))
+		return append(buf, str...)
+	}
+}
+
+func readAvroValue(buf []byte, fieldType string) (interface{}, int, error) {
+	switch fieldType {
+	case schemaregistry.TypeInt, schemaregistry.TypeLong:
+		v, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, 0, fmt.Errorf("invalid varint")
+		}
+		return v, n, nil
+	case schemaregistry.TypeBoolean:
+		if len(buf) < 1 {
+			return nil, 0, fmt.Errorf("unexpected end of payload")
+		}
+		return buf[0] == 1, 1, nil
+	case schemaregistry.TypeDouble:
+		if len(buf) < 8 {
+			return nil, 0, fmt.Errorf("unexpected end of payload")
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf)), 8, nil
+	default:
+		size, n := binary.Varint(buf)
+		if n <= 0 || size < 0 || int64(len(buf)-n) < size {
+			return nil, 0, fmt.Errorf("invalid string length")
+		}
+		end := n + int(size)
+		return string(buf[n:end]), end, nil
+	}
+}
@@ -0,0 +1,166 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/schemaregistry"
+	"math"
+)
+
+// Wire type Protobuf yang dipakai field primitif
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// protobufSerializer menulis data sebagai pesan proto3 (lihat schemas/proto/events.proto).
+// Nomor field diambil dari field-id di schema Avro, field yang tidak dikenal dilewati.
+type protobufSerializer struct {
+	schemas *dataSchemas
+}
+
+func (s *protobufSerializer) ContentType() string { return contentTypeProtobuf }
+
+func (s *protobufSerializer) Serialize(eventType string, data json.RawMessage) ([]byte, error) {
+	schema, err := s.schemas.forEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+	record, err := recordFromJSON(schema, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	for _, field := range schema.Fields {
+		value := record[field.Name]
+		// proto3 tidak menulis zero value
+		if value == schemaregistry.ZeroValue(field.Type) {
+			continue
+		}
+		buf = appendProtoField(buf, field, value)
+	}
+	return buf, nil
+}
+
+func (s *protobufSerializer) Deserialize(eventType string, payload []byte) (json.RawMessage, error) {
+	schema, err := s.schemas.forEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*schemaregistry.Field, len(schema.Fields))
+	record := make(map[string]interface{}, len(schema.Fields))
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		byID[field.FieldID] = field
+		record[field.Name] = schemaregistry.ZeroValue(field.Type)
+	}
+
+	buf := payload
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid protobuf tag")
+		}
+		buf = buf[n:]
+
+		fieldID, wireType := int(tag>>3), int(tag&7)
+		raw, n, err := readProtoValue(buf, wireType)
+		if err != nil {
+			return nil, fmt.Errorf("%s field %d: %w", schema.FullName(), fieldID, err)
+		}
+		buf = buf[n:]
+
+		field, ok := byID[fieldID]
+		if !ok {
+			continue
+		}
+		value, err := protoFieldValue(field, wireType, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", schema.FullName(), field.Name, err)
+		}
+		record[field.Name] = value
+	}
+	return json.Marshal(record)
+}
+
+func appendProtoField(buf []byte, field schemaregistry.Field, value interface{}) []byte {
+	tag := func(wireType int) []byte {
+		return binary.AppendUvarint(buf, uint64(field.FieldID)<<3|uint64(wireType))
+	}
+
+	switch field.Type {
+	case schemaregistry.TypeInt, schemaregistry.TypeLong:
+		return binary.AppendUvarint(tag(protoWireVarint), uint64(value.(int64)))
+	case schemaregistry.TypeBoolean:
+		return binary.AppendUvarint(tag(protoWireVarint), 1)
+	case schemaregistry.TypeDouble:
+		return binary.LittleEndian.AppendUint64(tag(protoWireFixed64), math.Float64bits(value.(float64)))
+	default:
+		str := value.(string)
+		buf = binary.AppendUvarint(tag(protoWireBytes), uint64(len(str)))
+		return append(buf, str...)
+	}
+}
+
+// readProtoValue membaca satu nilai sesuai wire type. Untuk varint dan fixed nilainya
+// dikembalikan sebagai uint64, untuk bytes sebagai []byte.
+func readProtoValue(buf []byte, wireType int) (interface{}, int, error) {
+	switch wireType {
+	case protoWireVarint:
+		v, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, 0, fmt.Errorf("invalid varint")
+		}
+		return v, n, nil
+	case protoWireFixed64:
+		if len(buf) < 8 {
+			return nil, 0, fmt.Errorf("unexpected end of payload")
+		}
+		return binary.LittleEndian.Uint64(buf), 8, nil
+	case protoWireFixed32:
+		if len(buf) < 4 {
+			return nil, 0, fmt.Errorf("unexpected end of payload")
+		}
+		return uint64(binary.LittleEndian.Uint32(buf)), 4, nil
+	case protoWireBytes:
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return nil, 0, fmt.Errorf("invalid length")
+		}
+		end := n + int(size)
+		return buf[n:end], end, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported wire type %d", wireType)
+	}
+}
+
+func protoFieldValue(field *schemaregistry.Field, wireType int, raw interface{}) (interface{}, error) {
+	expected := protoWireVarint
+	switch field.Type {
+	case schemaregistry.TypeDouble:
+		expected = protoWireFixed64
+	case schemaregistry.TypeString:
+		expected = protoWireBytes
+	}
+	if wireType != expected {
+		return nil, fmt.Errorf("wire type %d does not match %s", wireType, field.Type)
+	}
+
+	switch field.Type {
+	case schemaregistry.TypeInt:
+		return int64(int32(raw.(uint64))), nil
+	case schemaregistry.TypeLong:
+		return int64(raw.(uint64)), nil
+	case schemaregistry.TypeBoolean:
+		return raw.(uint64) != 0, nil
+	case schemaregistry.TypeDouble:
+		return math.Float64frombits(raw.(uint64)), nil
+	default:
+		return string(raw.([]byte)), nil
+	}
+}
@@ -0,0 +1,243 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"go-crud/internal/event"
+	"go-crud/internal/schemaregistry"
+)
+
+const testRegistryDir = "../../schemas/registry"
+
+// testEvents berisi satu data contoh untuk setiap tipe event
+var testEvents = []struct {
+	eventType string
+	data      any
+}{
+	{event.TypeUserCreated, event.UserData{Name: "Budi", Email: "budi@example.com"}},
+	{event.TypeUserUpdated, event.UserData{ID: 7, Name: "Budi Santoso", Email: "budi.s@example.com"}},
+	{event.TypeUserDeleted, event.UserData{ID: 7}},
+	{event.TypeRepositoryCreated, event.RepositoryData{UserID: 7, Name: "go-crud", URL: "https://github.com/acme/go-crud", AIEnabled: true}},
+	{event.TypeRepositoryUpdated, event.RepositoryData{ID: 3, UserID: 7, Name: "go-crud-v2", URL: "https://gitlab.com/acme/go-crud"}},
+	{event.TypeRepositoryDeleted, event.RepositoryData{ID: 3}},
+}
+
+func newTestSerializers(t *testing.T, registryDir string) *Serializers {
+	t.Helper()
+	s, err := NewSerializers(map[string]string{
+		"json-events":     FormatJSON,
+		"avro-events":     FormatAvro,
+		"protobuf-events": FormatProtobuf,
+	}, registryDir)
+	if err != nil {
+		t.Fatalf("NewSerializers: %v", err)
+	}
+	return s
+}
+
+// decodeAs meng-unmarshal data ke tipe yang sama dengan want supaya bisa dibandingkan
+func decodeAs(t *testing.T, want any, data []byte) any {
+	t.Helper()
+	got := reflect.New(reflect.TypeOf(want))
+	if err := json.Unmarshal(data, got.Interface()); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	return got.Elem().Interface()
+}
+
+func TestSerializersRoundTrip(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+
+	for _, topic := range []string{"json-events", "avro-events", "protobuf-events"} {
+		for _, tc := range testEvents {
+			t.Run(topic+"/"+tc.eventType, func(t *testing.T) {
+				data, err := json.Marshal(tc.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ser := s.ForTopic(topic)
+				payload, err := ser.Serialize(tc.eventType, data)
+				if err != nil {
+					t.Fatalf("Serialize: %v", err)
+				}
+				back, err := s.byContentType[ser.ContentType()].Deserialize(tc.eventType, payload)
+				if err != nil {
+					t.Fatalf("Deserialize: %v", err)
+				}
+
+				if got := decodeAs(t, tc.data, back); !reflect.DeepEqual(got, tc.data) {
+					t.Errorf("round trip = %+v, want %+v", got, tc.data)
+				}
+			})
+		}
+	}
+}
+
+func TestSerializersForTopic(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{"avro-events", contentTypeAvro},
+		{"avro-events.retry.1m", contentTypeAvro},
+		{"protobuf-events.dlq", contentTypeProtobuf},
+		{"unknown-events", event.ContentTypeJSON},
+	}
+	for _, tc := range tests {
+		if got := s.ForTopic(tc.topic).ContentType(); got != tc.want {
+			t.Errorf("ForTopic(%q) = %s, want %s", tc.topic, got, tc.want)
+		}
+	}
+
+	if _, err := s.ForContentType("application/xml"); err == nil {
+		t.Error("ForContentType(application/xml) succeeded, want error")
+	}
+	if ser, err := s.ForContentType(contentTypeAvro + "; charset=binary"); err != nil || ser.ContentType() != contentTypeAvro {
+		t.Errorf("ForContentType with parameters = %v, %v", ser, err)
+	}
+}
+
+func TestRecordFromJSONRejectsInvalidData(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+	avro := s.ForTopic("avro-events")
+
+	tests := map[string]string{
+		"unknown field": `{"name":"Budi","phone":"0812"}`,
+		"wrong type":    `{"id":"seven"}`,
+		"not an object": `[1,2,3]`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := avro.Serialize(event.TypeUserCreated, json.RawMessage(data)); err == nil {
+				t.Errorf("Serialize(%s) succeeded, want error", data)
+			}
+		})
+	}
+}
+
+// writeRegistry membuat registry sementara dengan versi schema berurutan untuk satu subject
+func writeRegistry(t *testing.T, subject string, versions ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, subject), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, raw := range versions {
+		file := filepath.Join(dir, subject, strconv.Itoa(i+1)+".avsc")
+		if err := os.WriteFile(file, []byte(raw), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// RepositoryData tetap memakai schema terdaftar supaya NewSerializers bisa diverifikasi
+	if subject != "gocrud.events.RepositoryData" {
+		repoDir := filepath.Join(dir, "gocrud.events.RepositoryData")
+		if err := os.MkdirAll(repoDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoDir, "1.avsc"), []byte(event.RepositoryDataSchema), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// userDataV0 adalah versi UserData sebelum email ditambahkan
+const userDataV0 = `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[{"name":"id","type":"int","default":0,"field-id":1},{"name":"name","type":"string","default":"","field-id":2}]}`
+
+func TestAvroReadsOlderWriterSchema(t *testing.T) {
+	dir := writeRegistry(t, "gocrud.events.UserData", userDataV0, event.UserDataSchema)
+	s := newTestSerializers(t, dir)
+
+	old, err := schemaregistry.Parse([]byte(userDataV0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte{avroMagicByte}
+	payload = binary.BigEndian.AppendUint32(payload, old.Fingerprint())
+	payload = appendAvroValue(payload, schemaregistry.TypeInt, int64(7))
+	payload = appendAvroValue(payload, schemaregistry.TypeString, "Budi")
+
+	data, err := s.ForTopic("avro-events").Deserialize(event.TypeUserUpdated, payload)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	want := event.UserData{ID: 7, Name: "Budi"}
+	if got := decodeAs(t, want, data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Deserialize = %+v, want %+v", got, want)
+	}
+}
+
+func TestAvroRejectsUnknownWriterSchema(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+
+	payload := []byte{avroMagicByte, 0xde, 0xad, 0xbe, 0xef}
+	if _, err := s.ForTopic("avro-events").Deserialize(event.TypeUserCreated, payload); err == nil {
+		t.Error("Deserialize with unregistered schema id succeeded, want error")
+	}
+	if _, err := s.ForTopic("avro-events").Deserialize(event.TypeUserCreated, []byte(`{"name":"Budi"}`)); err == nil {
+		t.Error("Deserialize without schema header succeeded, want error")
+	}
+}
+
+func TestProtobufSkipsUnknownFields(t *testing.T) {
+	s := newTestSerializers(t, testRegistryDir)
+	ser := s.ForTopic("protobuf-events")
+
+	payload, err := ser.Serialize(event.TypeUserCreated, json.RawMessage(`{"name":"Budi"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Field 9 (string) dari producer yang lebih baru
+	payload = binary.AppendUvarint(payload, 9<<3|protoWireBytes)
+	payload = binary.AppendUvarint(payload, 3)
+	payload = append(payload, "new"...)
+
+	data, err := ser.Deserialize(event.TypeUserCreated, payload)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	want := event.UserData{Name: "Budi"}
+	if got := decodeAs(t, want, data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Deserialize = %+v, want %+v", got, want)
+	}
+
+	// Wire type yang tidak cocok dengan tipe field ditolak
+	bad := binary.AppendUvarint(nil, 2<<3|protoWireVarint)
+	bad = binary.AppendUvarint(bad, 1)
+	if _, err := ser.Deserialize(event.TypeUserCreated, bad); err == nil {
+		t.Error("Deserialize with mismatched wire type succeeded, want error")
+	}
+}
+
+func TestNewSerializersRejectsIncompatibleRegistry(t *testing.T) {
+	// Versi terakhir di registry mengubah tipe email, schema yang dikompilasi tidak lagi kompatibel
+	changedType := `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[{"name":"id","type":"int","default":0,"field-id":1},{"name":"name","type":"string","default":"","field-id":2},{"name":"email","type":"long","default":0,"field-id":3}]}`
+
+	tests := map[string]string{
+		"incompatible latest version":    writeRegistry(t, "gocrud.events.UserData", event.UserDataSchema, changedType),
+		"compiled schema not registered": writeRegistry(t, "gocrud.events.UserData", userDataV0),
+	}
+	for name, dir := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSerializers(map[string]string{"user-events": FormatAvro}, dir)
+			if !errors.Is(err, ErrSchemaRegistry) {
+				t.Fatalf("NewSerializers error = %v, want ErrSchemaRegistry", err)
+			}
+		})
+	}
+
+	// Topic JSON tidak butuh registry
+	if _, err := NewSerializers(map[string]string{"user-events": FormatJSON}, t.TempDir()); err != nil {
+		t.Errorf("NewSerializers with JSON only: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-crud/internal/usecase/port"
+)
+
+const defaultNotifyTimeout = 10 * time.Second
+
+// RouterFromEnv menyiapkan channel yang dikonfigurasi di env dan aturan routing-nya:
+//
+//   - telegram: TELEGRAM_BOT_TOKEN, TELEGRAM_CHAT_ID, TELEGRAM_API_URL (opsional)
+//   - slack: SLACK_WEBHOOK_URL
+//   - webhook: NOTIFY_WEBHOOK_URL, NOTIFY_WEBHOOK_SECRET (opsional)
+//   - email: SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD, SMTP_FROM, SMTP_TO
+//
+// NOTIFY_ROUTES berisi "<topic>=<channel>,<channel>;..." contoh
+// "breaker=slack,telegram;*=email". Jika kosong semua topic dikirim ke semua channel.
+// NOTIFY_TIMEOUT (default 10s) membatasi satu pengiriman.
+func RouterFromEnv() (*Router, error) {
+	timeout := defaultNotifyTimeout
+	if d, err := time.ParseDuration(os.Getenv("NOTIFY_TIMEOUT")); err == nil && d > 0 {
+		timeout = d
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var channels []port.Notifier
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		channels = append(channels, NewTelegramNotifier(TelegramConfig{
+			Token:  token,
+			ChatID: os.Getenv("TELEGRAM_CHAT_ID"),
+			APIURL: os.Getenv("TELEGRAM_API_URL"),
+		}, client))
+	}
+	if url := os.Getenv("SLACK_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewSlackNotifier(url, client))
+	}
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewWebhookNotifier(url, os.Getenv("NOTIFY_WEBHOOK_SECRET"), client))
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		cfg := SMTPConfig{
+			Host:     host,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			To:       splitList(os.Getenv("SMTP_TO"), ","),
+			Timeout:  timeout,
+		}
+		if raw := os.Getenv("SMTP_PORT"); raw != "" {
+			port, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", raw)
+			}
+			cfg.Port = port
+		}
+		if cfg.From == "" {
+			return nil, fmt.Errorf("SMTP_FROM is required when SMTP_HOST is set")
+		}
+		channels = append(channels, NewEmailNotifier(cfg))
+	}
+
+	routes, err := ParseRoutes(os.Getenv("NOTIFY_ROUTES"))
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		all := Route{Topic: "*"}
+		for _, c := range channels {
+			all.Channels = append(all.Channels, c.Name())
+		}
+		routes = []Route{all}
+	}
+	return NewRouter(channels, routes)
+}
+
+// ParseRoutes membaca format NOTIFY_ROUTES "<topic>=<channel>,<channel>;<topic>=..."
+func ParseRoutes(raw string) ([]Route, error) {
+	var routes []Route
+	for _, part := range splitList(raw, ";") {
+		topic, list, ok := strings.Cut(part, "=")
+		topic = strings.TrimSpace(topic)
+		channels := splitList(list, ",")
+		if !ok || topic == "" || len(channels) == 0 {
+			return nil, fmt.Errorf("invalid NOTIFY_ROUTES entry %q, expected <topic>=<channel>[,<channel>]", part)
+		}
+		routes = append(routes, Route{Topic: topic, Channels: channels})
+	}
+	return routes, nil
+}
+
+// splitList memecah s dengan sep, bagian kosong dibuang
+func splitList(s, sep string) []string {
+	var out []string
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
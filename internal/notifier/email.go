@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-crud/internal/usecase/port"
+)
+
+// SMTPConfig berisi server SMTP dan penerima bawaan. Username kosong berarti tanpa AUTH;
+// STARTTLS dipakai jika server menawarkannya.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// To dipakai jika notifikasi tidak menyebut penerima sendiri
+	To      []string
+	Timeout time.Duration
+}
+
+// EmailNotifier mengirim notifikasi sebagai email teks biasa lewat SMTP
+type EmailNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewEmailNotifier(cfg SMTPConfig) *EmailNotifier {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n port.Notification) error {
+	to := n.To
+	if len(to) == 0 {
+		to = e.cfg.To
+	}
+	if len(to) == 0 {
+		return errors.New("email: no recipients")
+	}
+	for _, addr := range to {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("email: invalid recipient %q: %w", addr, err)
+		}
+	}
+
+	msg := e.message(n, to)
+	if err := e.send(ctx, to, msg); err != nil {
+		return fmt.Errorf("email via %s:%d: %w", e.cfg.Host, e.cfg.Port, err)
+	}
+	return nil
+}
+
+// send menjalankan satu sesi SMTP. net/smtp tidak menerima context, jadi batas waktunya
+// dipasang sebagai deadline koneksi.
+func (e *EmailNotifier) send(ctx context.Context, to []string, msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	dialer := net.Dialer{Timeout: e.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	var deadline time.Time
+	if e.cfg.Timeout > 0 {
+		deadline = time.Now().Add(e.cfg.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message menyusun email RFC 5322 teks biasa UTF-8, subject di-encode untuk karakter non-ASCII
+func (e *EmailNotifier) message(n port.Notification, to []string) []byte {
+	subject := n.Title
+	if subject == "" {
+		subject = n.Topic
+	}
+	date := n.Time
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := n
+	body.Title = ""
+	b.WriteString(strings.ReplaceAll(plainText(body), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,205 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-crud/internal/usecase/port"
+)
+
+// smtpStub adalah server SMTP minimal tanpa STARTTLS dan AUTH. RCPT ke alamat di reject
+// dijawab 550.
+type smtpStub struct {
+	addr   *net.TCPAddr
+	reject map[string]bool
+
+	mu       sync.Mutex
+	rcpts    []string
+	messages []string
+}
+
+func newSMTPStub(t *testing.T, reject ...string) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpStub{addr: ln.Addr().(*net.TCPAddr), reject: map[string]bool{}}
+	for _, addr := range reject {
+		s.reject["<"+addr+">"] = true
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			reply("250-stub")
+			reply("250 8BITMIME")
+		case verb == "MAIL":
+			reply("250 OK")
+		case verb == "RCPT":
+			addr := strings.TrimSpace(strings.SplitN(cmd, ":", 2)[1])
+			if s.reject[addr] {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(addr, "<>"))
+			s.mu.Unlock()
+			reply("250 OK")
+		case verb == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case verb == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStub) received() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.rcpts...), append([]string(nil), s.messages...)
+}
+
+func newTestEmailNotifier(s *smtpStub) *EmailNotifier {
+	return NewEmailNotifier(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    s.addr.Port,
+		From:    "go-crud@example.com",
+		To:      []string{"ops@example.com", "dev@example.com"},
+		Timeout: 5 * time.Second,
+	})
+}
+
+func TestEmailNotify(t *testing.T) {
+	stub := newSMTPStub(t)
+	email := newTestEmailNotifier(stub)
+
+	n := port.Notification{
+		Topic:  "review.failed",
+		Title:  "Review gagal ✗",
+		Text:   "go-crud #12\nlangkah checkout",
+		Fields: map[string]string{"status": "failed"},
+		Time:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := email.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	rcpts, messages := stub.received()
+	if strings.Join(rcpts, ",") != "ops@example.com,dev@example.com" {
+		t.Errorf("recipients = %v, want the default SMTP_TO list", rcpts)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("received %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	for _, want := range []string{
+		"From: go-crud@example.com\r\n",
+		"To: ops@example.com, dev@example.com\r\n",
+		"Subject: =?utf-8?q?Review_gagal_=E2=9C=97?=\r\n",
+		"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\ngo-crud #12\r\nlangkah checkout\r\nstatus: failed\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message does not contain %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestEmailNotifyEscapesSubject(t *testing.T) {
+	stub := newSMTPStub(t)
+	email := newTestEmailNotifier(stub)
+
+	// CRLF di judul tidak boleh menambah header baru
+	n := port.Notification{Title: "Gagal\r\nBcc: evil@example.com", To: []string{"budi@example.com"}}
+	if err := email.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	rcpts, messages := stub.received()
+	if strings.Join(rcpts, ",") != "budi@example.com" {
+		t.Errorf("recipients = %v, want only the notification recipient", rcpts)
+	}
+	if len(messages) != 1 || strings.Contains(messages[0], "\r\nBcc:") {
+		t.Errorf("subject was not encoded:\n%s", messages)
+	}
+}
+
+func TestEmailNotifyErrors(t *testing.T) {
+	stub := newSMTPStub(t, "ops@example.com")
+
+	tests := []struct {
+		name string
+		to   []string
+		cfg  func(*SMTPConfig)
+	}{
+		{name: "rejected recipient"},
+		{name: "invalid recipient", to: []string{"not an address"}},
+		{name: "no recipients", cfg: func(c *SMTPConfig) { c.To = nil }},
+		{name: "server down", cfg: func(c *SMTPConfig) { c.Port = 1 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			email := newTestEmailNotifier(stub)
+			if tc.cfg != nil {
+				tc.cfg(&email.cfg)
+			}
+			if err := email.Notify(context.Background(), port.Notification{Title: "test", To: tc.to}); err == nil {
+				t.Error("Notify succeeded, want error")
+			}
+		})
+	}
+	if _, messages := stub.received(); len(messages) != 0 {
+		t.Errorf("stub received %d messages, want none", len(messages))
+	}
+}
@@ -0,0 +1,80 @@
+// Package notifier berisi channel notifikasi (Telegram, Slack incoming webhook, webhook JSON
+// dan email SMTP) serta Router yang meneruskan notifikasi ke channel sesuai topic-nya.
+// Semua endpoint bisa diatur lewat env sehingga bisa diarahkan ke server stub lokal.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go-crud/internal/usecase/port"
+)
+
+// Body response error yang ikut di pesan error
+const maxErrorBody = 512
+
+// postJSON mengirim body JSON dan mengembalikan body response. Status selain 2xx menjadi error.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(respBody))
+		if len(msg) > maxErrorBody {
+			msg = msg[:maxErrorBody]
+		}
+		return respBody, fmt.Errorf("%s responded %s: %s", req.URL.Host, resp.Status, msg)
+	}
+	return respBody, nil
+}
+
+// plainText menyusun notifikasi sebagai teks biasa: judul, isi, field lalu link
+func plainText(n port.Notification) string {
+	var b strings.Builder
+	if n.Title != "" {
+		b.WriteString(n.Title)
+		b.WriteString("\n")
+	}
+	if n.Text != "" {
+		b.WriteString(n.Text)
+		b.WriteString("\n")
+	}
+	for _, k := range sortedKeys(n.Fields) {
+		fmt.Fprintf(&b, "%s: %s\n", k, n.Fields[k])
+	}
+	if n.URL != "" {
+		b.WriteString(n.URL)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+// capturedRequest adalah request terakhir yang diterima server stub
+type capturedRequest struct {
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// newStubServer menjawab setiap request dengan status dan body yang diberikan, lalu
+// mengirim request yang diterima ke channel
+func newStubServer(t *testing.T, status int, body string) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{Path: r.URL.Path, Header: r.Header.Clone(), Body: b}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func TestPostJSONErrorStatus(t *testing.T) {
+	long := strings.Repeat("x", 2*maxErrorBody)
+	srv, _ := newStubServer(t, http.StatusBadGateway, long)
+
+	respBody, err := postJSON(context.Background(), srv.Client(), srv.URL, []byte(`{}`), nil)
+	if err == nil {
+		t.Fatal("postJSON succeeded on 502, want error")
+	}
+	if !strings.Contains(err.Error(), "502") {
+		t.Errorf("error %q does not mention the status", err)
+	}
+	// Body response dipotong di pesan error tapi tetap dikembalikan utuh
+	if len(err.Error()) > maxErrorBody+100 {
+		t.Errorf("error message is %d bytes, want the body truncated to %d", len(err.Error()), maxErrorBody)
+	}
+	if string(respBody) != long {
+		t.Errorf("response body is %d bytes, want %d", len(respBody), len(long))
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := plainText(port.Notification{
+		Title:  "Review gagal",
+		Text:   "go-crud #12",
+		Fields: map[string]string{"status": "failed", "repository": "go-crud"},
+		URL:    "https://example.com/runs/12",
+	})
+	want := "Review gagal\ngo-crud #12\nrepository: go-crud\nstatus: failed\nhttps://example.com/runs/12"
+	if got != want {
+		t.Errorf("plainText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-crud/internal/usecase/port"
+)
+
+// Route mengirim notifikasi dengan Topic yang cocok ke Channels. Topic bisa persis
+// ("review.failed"), satu grup ("review.*") atau semua topic ("*").
+type Route struct {
+	Topic    string
+	Channels []string
+}
+
+// Router meneruskan setiap notifikasi ke semua channel dari route yang cocok, paralel.
+// Satu channel yang gagal tidak menghentikan channel lain, error semua channel digabung.
+type Router struct {
+	channels map[string]port.Notifier
+	routes   []Route
+}
+
+// NewRouter memeriksa bahwa setiap channel di routes terdaftar
+func NewRouter(channels []port.Notifier, routes []Route) (*Router, error) {
+	byName := make(map[string]port.Notifier, len(channels))
+	for _, c := range channels {
+		byName[c.Name()] = c
+	}
+	for _, r := range routes {
+		for _, name := range r.Channels {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("route %q uses channel %q which is not configured", r.Topic, name)
+			}
+		}
+	}
+	return &Router{channels: byName, routes: routes}, nil
+}
+
+func (r *Router) Name() string {
+	return "router"
+}
+
+// Channels mengembalikan nama channel yang terdaftar, urut abjad
+func (r *Router) Channels() []string {
+	names := make([]string, 0, len(r.channels))
+	for name := range r.channels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Notify mengirim ke channel sesuai routing, tanpa route yang cocok notifikasi dilewati
+func (r *Router) Notify(ctx context.Context, n port.Notification) error {
+	return r.send(ctx, r.targets(n.Topic), n)
+}
+
+// NotifyVia mengirim langsung ke channels, melewati routing. Channel yang tidak terdaftar
+// dianggap error, channel lain tetap dikirimi.
+func (r *Router) NotifyVia(ctx context.Context, channels []string, n port.Notification) error {
+	var unknown []error
+	var names []string
+	for _, name := range channels {
+		if _, ok := r.channels[name]; !ok {
+			unknown = append(unknown, fmt.Errorf("channel %q is not configured", name))
+			continue
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return errors.Join(append(unknown, r.send(ctx, names, n))...)
+}
+
+func (r *Router) targets(topic string) []string {
+	var names []string
+	for _, route := range r.routes {
+		if !topicMatches(route.Topic, topic) {
+			continue
+		}
+		for _, name := range route.Channels {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func (r *Router) send(ctx context.Context, names []string, n port.Notification) error {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, c port.Notifier) {
+			defer wg.Done()
+			errs[i] = c.Notify(ctx, n)
+		}(i, r.channels[name])
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func topicMatches(pattern, topic string) bool {
+	if pattern == "*" || pattern == topic {
+		return true
+	}
+	group, ok := strings.CutSuffix(pattern, ".*")
+	return ok && strings.HasPrefix(topic, group+".")
+}
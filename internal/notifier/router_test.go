@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+// fakeNotifier mencatat notifikasi yang diterima, err dikembalikan setiap Notify
+type fakeNotifier struct {
+	name string
+	err  error
+
+	mu  sync.Mutex
+	got []port.Notification
+}
+
+func (f *fakeNotifier) Name() string {
+	return f.name
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n port.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.got = append(f.got, n)
+	return f.err
+}
+
+func (f *fakeNotifier) topics() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var topics []string
+	for _, n := range f.got {
+		topics = append(topics, n.Topic)
+	}
+	return topics
+}
+
+func newTestRouter(t *testing.T, routes []Route) (*Router, map[string]*fakeNotifier) {
+	t.Helper()
+	fakes := map[string]*fakeNotifier{}
+	var channels []port.Notifier
+	for _, name := range []string{"email", "slack", "telegram", "webhook"} {
+		fakes[name] = &fakeNotifier{name: name}
+		channels = append(channels, fakes[name])
+	}
+	r, err := NewRouter(channels, routes)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	return r, fakes
+}
+
+func TestRouterFanOut(t *testing.T) {
+	r, fakes := newTestRouter(t, []Route{
+		{Topic: "breaker", Channels: []string{"slack", "telegram"}},
+		{Topic: "review.*", Channels: []string{"email", "slack"}},
+		{Topic: "review.failed", Channels: []string{"webhook", "slack"}},
+	})
+
+	for _, topic := range []string{"breaker", "review.completed", "review.failed", "reviewer", "outbox"} {
+		if err := r.Notify(context.Background(), port.Notification{Topic: topic}); err != nil {
+			t.Fatalf("Notify(%s): %v", topic, err)
+		}
+	}
+
+	// Channel yang muncul di beberapa route yang cocok hanya dikirimi sekali
+	want := map[string][]string{
+		"email":    {"review.completed", "review.failed"},
+		"slack":    {"breaker", "review.completed", "review.failed"},
+		"telegram": {"breaker"},
+		"webhook":  {"review.failed"},
+	}
+	for name, f := range fakes {
+		got := f.topics()
+		slices.Sort(got)
+		if !reflect.DeepEqual(got, want[name]) {
+			t.Errorf("%s received %v, want %v", name, got, want[name])
+		}
+	}
+}
+
+func TestRouterWildcardAndTime(t *testing.T) {
+	r, fakes := newTestRouter(t, []Route{{Topic: "*", Channels: []string{"webhook"}}})
+
+	if err := r.Notify(context.Background(), port.Notification{Topic: "anything"}); err != nil {
+		t.Fatal(err)
+	}
+	got := fakes["webhook"].got
+	if len(got) != 1 || got[0].Time.IsZero() {
+		t.Errorf("webhook received %+v, want one notification with time set", got)
+	}
+}
+
+func TestRouterFailingChannelDoesNotStopOthers(t *testing.T) {
+	r, fakes := newTestRouter(t, []Route{{Topic: "*", Channels: []string{"slack", "telegram", "email"}}})
+	slackErr := errors.New("slack down")
+	emailErr := errors.New("smtp down")
+	fakes["slack"].err = slackErr
+	fakes["email"].err = emailErr
+
+	err := r.Notify(context.Background(), port.Notification{Topic: "breaker"})
+	if !errors.Is(err, slackErr) || !errors.Is(err, emailErr) {
+		t.Errorf("Notify error = %v, want both channel errors", err)
+	}
+	if len(fakes["telegram"].got) != 1 {
+		t.Errorf("telegram received %d notifications, want 1", len(fakes["telegram"].got))
+	}
+}
+
+func TestRouterNotifyVia(t *testing.T) {
+	r, fakes := newTestRouter(t, nil)
+
+	err := r.NotifyVia(context.Background(), []string{"email", "sms", "email"}, port.Notification{Topic: "review.completed"})
+	if err == nil {
+		t.Error("NotifyVia with unknown channel succeeded, want error")
+	}
+	if n := len(fakes["email"].got); n != 1 {
+		t.Errorf("email received %d notifications, want 1", n)
+	}
+	if n := len(fakes["slack"].got); n != 0 {
+		t.Errorf("slack received %d notifications, want 0", n)
+	}
+}
+
+func TestNewRouterRejectsUnknownChannel(t *testing.T) {
+	_, err := NewRouter([]port.Notifier{&fakeNotifier{name: "slack"}}, []Route{{Topic: "*", Channels: []string{"slack", "telegram"}}})
+	if err == nil {
+		t.Error("NewRouter with unconfigured channel succeeded, want error")
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes(" breaker = slack, telegram ;*=email;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Route{
+		{Topic: "breaker", Channels: []string{"slack", "telegram"}},
+		{Topic: "*", Channels: []string{"email"}},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("ParseRoutes = %+v, want %+v", routes, want)
+	}
+
+	for _, raw := range []string{"breaker", "=slack", "breaker=", "breaker=,"} {
+		if _, err := ParseRoutes(raw); err == nil {
+			t.Errorf("ParseRoutes(%q) succeeded, want error", raw)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-crud/internal/usecase/port"
+)
+
+var slackSeverityEmoji = map[string]string{
+	port.NotifyInfo:     ":information_source:",
+	port.NotifyWarning:  ":warning:",
+	port.NotifyCritical: ":rotating_light:",
+}
+
+// SlackNotifier mengirim pesan lewat Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func NewSlackNotifier(webhookURL string, client *http.Client) *SlackNotifier {
+	return &SlackNotifier{webhookURL: webhookURL, client: client}
+}
+
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, n port.Notification) error {
+	body, err := json.Marshal(map[string]string{"text": slackText(n)})
+	if err != nil {
+		return err
+	}
+	if _, err := postJSON(ctx, s.client, s.webhookURL, body, nil); err != nil {
+		return fmt.Errorf("slack webhook: %w", err)
+	}
+	return nil
+}
+
+// slackText memakai format mrkdwn Slack: judul tebal, field sebagai daftar dan link <url|teks>
+func slackText(n port.Notification) string {
+	var b strings.Builder
+	if emoji := slackSeverityEmoji[n.Severity]; emoji != "" {
+		b.WriteString(emoji + " ")
+	}
+	if n.Title != "" {
+		fmt.Fprintf(&b, "*%s*\n", slackEscape(n.Title))
+	}
+	if n.Text != "" {
+		b.WriteString(slackEscape(n.Text) + "\n")
+	}
+	for _, k := range sortedKeys(n.Fields) {
+		fmt.Fprintf(&b, "• %s: %s\n", slackEscape(k), slackEscape(n.Fields[k]))
+	}
+	if n.URL != "" {
+		fmt.Fprintf(&b, "<%s|Open>\n", n.URL)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// slackEscape meng-escape karakter kontrol mrkdwn Slack
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
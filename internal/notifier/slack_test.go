@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+func TestSlackNotify(t *testing.T) {
+	srv, requests := newStubServer(t, http.StatusOK, "ok")
+	slack := NewSlackNotifier(srv.URL+"/services/T000/B000/XXX", srv.Client())
+
+	n := port.Notification{
+		Severity: port.NotifyCritical,
+		Title:    "Breaker <kafka> open",
+		Text:     "Tom & Jerry <!channel>",
+		Fields:   map[string]string{"b<key>": "1 > 0", "a": "x"},
+		URL:      "https://example.com/runs/12",
+	}
+	if err := slack.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := <-requests
+	if req.Path != "/services/T000/B000/XXX" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s", req.Path, req.Header.Get("Content-Type"))
+	}
+	var body struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	// &, < dan > di-escape supaya teks tidak bisa membuat mention atau link
+	want := ":rotating_light: *Breaker &lt;kafka&gt; open*\n" +
+		"Tom &amp; Jerry &lt;!channel&gt;\n" +
+		"• a: x\n" +
+		"• b&lt;key&gt;: 1 &gt; 0\n" +
+		"<https://example.com/runs/12|Open>"
+	if body.Text != want {
+		t.Errorf("text = %q, want %q", body.Text, want)
+	}
+}
+
+func TestSlackNotifyErrorStatus(t *testing.T) {
+	srv, _ := newStubServer(t, http.StatusForbidden, "invalid_token")
+	slack := NewSlackNotifier(srv.URL, srv.Client())
+
+	if err := slack.Notify(context.Background(), port.Notification{Title: "test"}); err == nil {
+		t.Error("Notify succeeded on 403, want error")
+	}
+}
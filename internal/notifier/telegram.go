@@ -1,33 +1,67 @@
 package notifier
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
+
+	"go-crud/internal/usecase/port"
 )
 
-type TelegramNotifier struct {
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramConfig berisi token bot dan chat tujuan. APIURL bisa diarahkan ke server stub.
+type TelegramConfig struct {
 	Token  string
 	ChatID string
+	APIURL string
 }
 
-func NewTelegramNotifier() *TelegramNotifier {
-	return &TelegramNotifier{
-		Token:  os.Getenv("TELEGRAM_BOT_TOKEN"),
-		ChatID: os.Getenv("TELEGRAM_CHAT_ID"),
+type TelegramNotifier struct {
+	cfg    TelegramConfig
+	client *http.Client
+}
+
+func NewTelegramNotifier(cfg TelegramConfig, client *http.Client) *TelegramNotifier {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultTelegramAPIURL
 	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &TelegramNotifier{cfg: cfg, client: client}
 }
 
-func (t *TelegramNotifier) SendMessage(message string) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.Token)
-	body := []byte(fmt.Sprintf(`{"chat_id":"%s","text":"%s"}`, t.ChatID, message))
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+func (t *TelegramNotifier) Notify(ctx context.Context, n port.Notification) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.cfg.ChatID,
+		"text":                     plainText(n),
+		"disable_web_page_preview": true,
+	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.cfg.APIURL, t.cfg.Token)
+	respBody, err := postJSON(ctx, t.client, url, body, nil)
+	if err != nil {
+		// URL berisi token bot, jangan sampai ikut di log
+		return fmt.Errorf("telegram sendMessage: %s", strings.ReplaceAll(err.Error(), t.cfg.Token, "***"))
+	}
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("telegram sendMessage: decode response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram sendMessage: %s", result.Description)
+	}
 	return nil
 }
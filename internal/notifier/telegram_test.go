@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+const testBotToken = "123456:SECRET-token"
+
+func TestTelegramNotify(t *testing.T) {
+	srv, requests := newStubServer(t, http.StatusOK, `{"ok":true,"result":{}}`)
+	tg := NewTelegramNotifier(TelegramConfig{Token: testBotToken, ChatID: "-1001", APIURL: srv.URL + "/"}, srv.Client())
+
+	n := port.Notification{Title: "Breaker <open>", Text: "kafka *down* & retrying", Fields: map[string]string{"topic": "user-events"}}
+	if err := tg.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := <-requests
+	if req.Path != "/bot"+testBotToken+"/sendMessage" {
+		t.Errorf("path = %s", req.Path)
+	}
+	var body struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	// Tanpa parse_mode teks dikirim apa adanya, karakter markup tidak di-escape
+	if body.ChatID != "-1001" || body.Text != "Breaker <open>\nkafka *down* & retrying\ntopic: user-events" {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestTelegramNotifyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"non-2xx", http.StatusUnauthorized, `{"ok":false,"description":"Unauthorized"}`, "401"},
+		{"ok false", http.StatusOK, `{"ok":false,"description":"Bad Request: chat not found"}`, "chat not found"},
+		{"invalid response", http.StatusOK, `<html>`, "decode response"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := newStubServer(t, tc.status, tc.body)
+			tg := NewTelegramNotifier(TelegramConfig{Token: testBotToken, ChatID: "-1001", APIURL: srv.URL}, srv.Client())
+
+			err := tg.Notify(context.Background(), port.Notification{Title: "test"})
+			if err == nil {
+				t.Fatal("Notify succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q does not contain %q", err, tc.want)
+			}
+			if strings.Contains(err.Error(), testBotToken) {
+				t.Errorf("error %q leaks the bot token", err)
+			}
+		})
+	}
+}
+
+func TestTelegramNotifyHidesTokenOnConnectionError(t *testing.T) {
+	srv, _ := newStubServer(t, http.StatusOK, `{"ok":true}`)
+	srv.Close()
+	tg := NewTelegramNotifier(TelegramConfig{Token: testBotToken, ChatID: "-1001", APIURL: srv.URL}, srv.Client())
+
+	err := tg.Notify(context.Background(), port.Notification{Title: "test"})
+	if err == nil {
+		t.Fatal("Notify to a closed server succeeded, want error")
+	}
+	if strings.Contains(err.Error(), testBotToken) {
+		t.Errorf("error %q leaks the bot token", err)
+	}
+}
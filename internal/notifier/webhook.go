@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-crud/internal/usecase/port"
+	"go-crud/internal/webhookclient"
+)
+
+// WebhookNotifier mengirim notifikasi apa adanya sebagai JSON ke URL mana pun. Jika secret
+// diisi, request ditandatangani dengan header yang sama seperti webhook subscription.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url, secret string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{url: url, secret: secret, client: client}
+}
+
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n port.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if w.secret != "" {
+		timestamp := time.Now().Unix()
+		header.Set(webhookclient.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		header.Set(webhookclient.HeaderSignature, webhookclient.Sign(w.secret, timestamp, body))
+	}
+	if _, err := postJSON(ctx, w.client, w.url, body, header); err != nil {
+		return fmt.Errorf("notification webhook: %w", err)
+	}
+	return nil
+}
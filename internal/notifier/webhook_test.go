@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-crud/internal/usecase/port"
+	"go-crud/internal/webhookclient"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	srv, requests := newStubServer(t, http.StatusNoContent, "")
+	hook := NewWebhookNotifier(srv.URL+"/hook", "s3cret", srv.Client())
+
+	n := port.Notification{
+		Topic:    "review.failed",
+		Severity: port.NotifyWarning,
+		Title:    "Review <gagal>",
+		Fields:   map[string]string{"repository": "go-crud"},
+		To:       []string{"budi@example.com"},
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := hook.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := <-requests
+	var got port.Notification
+	if err := json.Unmarshal(req.Body, &got); err != nil {
+		t.Fatal(err)
+	}
+	// Notifikasi dikirim apa adanya, To tidak ikut di payload
+	want := n
+	want.To = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhookclient.HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if sig := req.Header.Get(webhookclient.HeaderSignature); sig != webhookclient.Sign("s3cret", timestamp, req.Body) {
+		t.Errorf("signature %q does not match the body", sig)
+	}
+}
+
+func TestWebhookNotifyWithoutSecret(t *testing.T) {
+	srv, requests := newStubServer(t, http.StatusOK, "")
+	hook := NewWebhookNotifier(srv.URL, "", srv.Client())
+
+	if err := hook.Notify(context.Background(), port.Notification{Topic: "breaker"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if req := <-requests; req.Header.Get(webhookclient.HeaderSignature) != "" {
+		t.Error("request without secret is signed")
+	}
+}
+
+func TestWebhookNotifyErrorStatus(t *testing.T) {
+	srv, _ := newStubServer(t, http.StatusInternalServerError, "boom")
+	hook := NewWebhookNotifier(srv.URL, "", srv.Client())
+
+	if err := hook.Notify(context.Background(), port.Notification{Topic: "breaker"}); err == nil {
+		t.Error("Notify succeeded on 500, want error")
+	}
+}
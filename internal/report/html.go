@@ -0,0 +1,87 @@
+package report
+
+import (
+	"go-crud/internal/entity"
+	"html/template"
+	"io"
+	"time"
+)
+
+// Laporan HTML mandiri: CSS inline, tanpa JavaScript atau resource eksternal,
+// jadi bisa dibuka langsung dari file atau lampiran
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"short": shortSHA,
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).String()
+	},
+	"utc": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Code review: {{.Repository.Name}} (run #{{.Run.ID}})</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem auto; max-width: 1100px; padding: 0 1rem; color: #1f2328; }
+  h1 { font-size: 1.5rem; margin-bottom: .25rem; }
+  .meta { color: #59636e; margin-bottom: 1.5rem; }
+  .meta code { background: #f6f8fa; padding: .1rem .3rem; border-radius: 4px; }
+  .alert { background: #fff8c5; border: 1px solid #d4a72c; padding: .75rem 1rem; border-radius: 6px; margin-bottom: 1rem; }
+  .cards { display: flex; gap: 1rem; margin-bottom: 1.5rem; }
+  .card { flex: 1; border: 1px solid #d1d9e0; border-radius: 6px; padding: .75rem 1rem; }
+  .card b { display: block; font-size: 1.75rem; }
+  table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+  th, td { text-align: left; padding: .5rem; border-bottom: 1px solid #d1d9e0; vertical-align: top; }
+  th { background: #f6f8fa; }
+  td.loc, td.rule { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; white-space: nowrap; }
+  .badge { display: inline-block; padding: .1rem .5rem; border-radius: 1rem; font-size: .8rem; color: #fff; }
+  .error { background: #cf222e; } .warning { background: #bf8700; } .info { background: #0969da; }
+  .fix { color: #59636e; margin-top: .25rem; }
+  .empty { padding: 2rem; text-align: center; color: #1a7f37; }
+  footer { color: #59636e; font-size: .8rem; margin-top: 2rem; }
+</style>
+</head>
+<body>
+<h1>Code review: {{.Repository.Name}}</h1>
+<div class="meta">
+  Run #{{.Run.ID}} · {{.Run.Status}}
+  {{- if .Run.CommitSHA}} · commit <code>{{short .Run.CommitSHA}}</code>{{end}}
+  {{- if .Run.BaseSHA}} · base <code>{{short .Run.BaseSHA}}</code>{{end}}
+  {{- if .Run.FinishedAt}} · {{duration .Run.DurationMs}}{{end}}
+  · started {{utc .Run.StartedAt}}
+  {{- if .Repository.URL}}<br>{{.Repository.URL}}{{end}}
+</div>
+{{if .Run.Error}}<div class="alert">{{.Run.Error}}</div>{{end}}
+<div class="cards">
+  <div class="card"><b>{{.Run.Summary.Errors}}</b>errors</div>
+  <div class="card"><b>{{.Run.Summary.Warnings}}</b>warnings</div>
+  <div class="card"><b>{{.Run.Summary.Infos}}</b>info</div>
+  <div class="card"><b>{{.Run.Summary.Total}}</b>total</div>
+</div>
+{{if .Findings}}
+<table>
+  <thead><tr><th>Severity</th><th>Location</th><th>Rule</th><th>Message</th></tr></thead>
+  <tbody>
+  {{- range .Findings}}
+    <tr>
+      <td><span class="badge {{.Severity}}">{{.Severity}}</span></td>
+      <td class="loc">{{.File}}:{{.StartLine}}:{{.StartColumn}}</td>
+      <td class="rule">{{.RuleID}}</td>
+      <td>{{.Message}}{{if .SuggestedFix}}<div class="fix">💡 {{.SuggestedFix}}</div>{{end}}</td>
+    </tr>
+  {{- end}}
+  </tbody>
+</table>
+{{else}}
+<div class="empty">✅ No findings.</div>
+{{end}}
+<footer>Generated by ` + toolName + `</footer>
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, r *entity.ReviewReport) error {
+	return htmlTemplate.Execute(w, r)
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go-crud/internal/entity"
+	"io"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnit menulis satu testsuite per file dan satu testcase gagal per temuan.
+// Run tanpa temuan tetap punya satu testcase lulus supaya CI tidak menganggap laporannya kosong;
+// run yang gagal dilaporkan sebagai <error>.
+func renderJUnit(w io.Writer, r *entity.ReviewReport) error {
+	doc := junitTestSuites{
+		Name: fmt.Sprintf("%s: %s (run %d)", toolName, r.Repository.Name, r.Run.ID),
+		Time: fmt.Sprintf("%.3f", (time.Duration(r.Run.DurationMs) * time.Millisecond).Seconds()),
+	}
+	timestamp := r.Run.StartedAt.UTC().Format("2006-01-02T15:04:05")
+
+	index := map[string]int{}
+	for _, f := range r.Findings {
+		i, ok := index[f.File]
+		if !ok {
+			i = len(doc.Suites)
+			index[f.File] = i
+			doc.Suites = append(doc.Suites, junitTestSuite{Name: f.File, Timestamp: timestamp})
+		}
+
+		details := fmt.Sprintf("%s:%d:%d: %s [%s]", f.File, f.StartLine, f.StartColumn, f.Message, f.RuleID)
+		if f.SuggestedFix != "" {
+			details += "\nSuggested fix: " + f.SuggestedFix
+		}
+		suite := &doc.Suites[i]
+		suite.Tests++
+		suite.Failures++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s at line %d", f.RuleID, f.StartLine),
+			ClassName: f.File,
+			Failure:   &junitFailure{Message: f.Message, Type: f.Severity, Text: details},
+		})
+	}
+
+	switch {
+	case r.Run.Status == entity.ReviewRunFailed || r.Run.Status == entity.ReviewRunCancelled:
+		doc.Suites = append(doc.Suites, junitTestSuite{Name: "codereview", Tests: 1, Errors: 1, Timestamp: timestamp,
+			Cases: []junitTestCase{{Name: "review run", ClassName: "codereview",
+				Error: &junitFailure{Message: "review run " + r.Run.Status, Type: r.Run.Status, Text: r.Run.Error}}}})
+	case len(r.Findings) == 0:
+		doc.Suites = append(doc.Suites, junitTestSuite{Name: "codereview", Tests: 1, Timestamp: timestamp,
+			Cases: []junitTestCase{{Name: "no findings", ClassName: "codereview"}}})
+	}
+
+	for _, suite := range doc.Suites {
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"go-crud/internal/entity"
+	"io"
+	"strings"
+	"time"
+)
+
+// Komentar PR dibatasi ukurannya (GitHub 65536 karakter), sisa temuan hanya dihitung
+const maxMarkdownFindings = 200
+
+var severityIcon = map[string]string{
+	entity.SeverityError:   "🔴",
+	entity.SeverityWarning: "🟠",
+	entity.SeverityInfo:    "🔵",
+}
+
+func renderMarkdown(w io.Writer, r *entity.ReviewReport) error {
+	bw := bufio.NewWriter(w)
+	run := r.Run
+
+	fmt.Fprintf(bw, "## Code review: %s (run #%d)\n\n", markdownText(r.Repository.Name), run.ID)
+
+	meta := []string{"**Status:** " + run.Status}
+	if run.CommitSHA != "" {
+		meta = append(meta, "**Commit:** `"+shortSHA(run.CommitSHA)+"`")
+	}
+	if run.BaseSHA != "" {
+		meta = append(meta, "**Base:** `"+shortSHA(run.BaseSHA)+"`")
+	}
+	if run.FinishedAt != nil {
+		meta = append(meta, "**Duration:** "+(time.Duration(run.DurationMs)*time.Millisecond).String())
+	}
+	fmt.Fprintf(bw, "%s\n\n", strings.Join(meta, " · "))
+	if run.Error != "" {
+		fmt.Fprintf(bw, "> ⚠️ %s\n\n", markdownText(run.Error))
+	}
+
+	s := run.Summary
+	fmt.Fprintf(bw, "| 🔴 Errors | 🟠 Warnings | 🔵 Info | Total |\n|---:|---:|---:|---:|\n| %d | %d | %d | %d |\n\n",
+		s.Errors, s.Warnings, s.Infos, s.Total)
+
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(bw, "✅ No findings.")
+		return bw.Flush()
+	}
+
+	fmt.Fprintln(bw, "| Severity | Location | Rule | Message |")
+	fmt.Fprintln(bw, "|---|---|---|---|")
+	for i, f := range r.Findings {
+		if i == maxMarkdownFindings {
+			fmt.Fprintf(bw, "\n_…and %d more findings._\n", len(r.Findings)-maxMarkdownFindings)
+			break
+		}
+		message := markdownText(f.Message)
+		if f.SuggestedFix != "" {
+			message += "<br>💡 " + markdownText(f.SuggestedFix)
+		}
+		fmt.Fprintf(bw, "| %s %s | `%s:%d` | `%s` | %s |\n",
+			severityIcon[f.Severity], f.Severity, markdownCode(f.File), f.StartLine, markdownCode(f.RuleID), message)
+	}
+	return bw.Flush()
+}
+
+// markdownText meng-escape teks supaya tidak merusak tabel atau dibaca sebagai markup
+func markdownText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '|', '\\', '`', '*', '_', '[', ']', '<', '>', '#':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// markdownCode menyiapkan teks di dalam `code`: backtick dan pipe tidak bisa di-escape di sana
+func markdownCode(s string) string {
+	return strings.NewReplacer("`", "'", "|", "¦", "\n", " ").Replace(s)
+}
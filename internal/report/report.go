@@ -0,0 +1,115 @@
+// Package report mengekspor hasil review run ke format yang bisa dibaca tool lain:
+// SARIF 2.1.0 untuk code scanning, Markdown untuk komentar PR, HTML mandiri untuk
+// dibagikan dan JUnit XML supaya CI menampilkan temuan sebagai test yang gagal.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"io"
+	"strings"
+)
+
+const (
+	FormatSARIF    = "sarif"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJUnit    = "junit"
+)
+
+// Nama tool yang tercantum di laporan
+const toolName = "go-crud code review"
+
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// format adalah satu jenis laporan beserta metadata HTTP-nya
+type format struct {
+	contentType string
+	extension   string
+	render      func(w io.Writer, r *entity.ReviewReport) error
+}
+
+var formats = map[string]format{
+	FormatSARIF:    {contentType: "application/sarif+json", extension: "sarif", render: renderSARIF},
+	FormatMarkdown: {contentType: "text/markdown; charset=utf-8", extension: "md", render: renderMarkdown},
+	FormatHTML:     {contentType: "text/html; charset=utf-8", extension: "html", render: renderHTML},
+	FormatJUnit:    {contentType: "application/xml; charset=utf-8", extension: "xml", render: renderJUnit},
+}
+
+// Formats mengembalikan nama format yang didukung
+func Formats() []string {
+	return []string{FormatSARIF, FormatMarkdown, FormatHTML, FormatJUnit}
+}
+
+func lookup(name string) (format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return format{}, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownFormat, name, strings.Join(Formats(), ", "))
+	}
+	return f, nil
+}
+
+// ContentType mengembalikan Content-Type dan ekstensi file untuk format
+func ContentType(name string) (contentType, extension string, err error) {
+	f, err := lookup(name)
+	if err != nil {
+		return "", "", err
+	}
+	return f.contentType, f.extension, nil
+}
+
+// Render menulis laporan run dalam format yang diminta
+func Render(w io.Writer, name string, r *entity.ReviewReport) error {
+	f, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	return f.render(w, r)
+}
+
+// ruleSummary adalah aturan yang muncul di laporan beserta severity tertingginya
+type ruleSummary struct {
+	ID       string
+	Severity string
+	Count    int
+}
+
+// rules mengumpulkan rule dari temuan, urut sesuai kemunculan pertama
+func rules(findings []entity.ReviewFinding) []ruleSummary {
+	var list []ruleSummary
+	index := map[string]int{}
+	for _, f := range findings {
+		i, ok := index[f.RuleID]
+		if !ok {
+			index[f.RuleID] = len(list)
+			list = append(list, ruleSummary{ID: f.RuleID, Severity: f.Severity, Count: 1})
+			continue
+		}
+		list[i].Count++
+		if severityRank(f.Severity) > severityRank(list[i].Severity) {
+			list[i].Severity = f.Severity
+		}
+	}
+	return list
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case entity.SeverityError:
+		return 3
+	case entity.SeverityWarning:
+		return 2
+	case entity.SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// shortSHA memendekkan commit SHA untuk tampilan
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
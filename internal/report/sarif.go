@@ -0,0 +1,186 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-crud/internal/entity"
+	"io"
+	"time"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool                     sarifTool                 `json:"tool"`
+	AutomationDetails        *sarifAutomationDetails   `json:"automationDetails,omitempty"`
+	VersionControlProvenance []sarifVersionControl     `json:"versionControlProvenance,omitempty"`
+	Invocations              []sarifInvocation         `json:"invocations"`
+	Results                  []sarifResult             `json:"results"`
+	OriginalURIBaseIDs       map[string]sarifURIBaseID `json:"originalUriBaseIds,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifVersionControl struct {
+	RepositoryURI string `json:"repositoryUri"`
+	RevisionID    string `json:"revisionId,omitempty"`
+}
+
+type sarifURIBaseID struct {
+	Description sarifMessage `json:"description"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	StartTimeUTC               string              `json:"startTimeUtc,omitempty"`
+	EndTimeUTC                 string              `json:"endTimeUtc,omitempty"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifLevel memetakan severity temuan ke level SARIF
+func sarifLevel(severity string) string {
+	switch severity {
+	case entity.SeverityError:
+		return "error"
+	case entity.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func renderSARIF(w io.Writer, r *entity.ReviewReport) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: toolName, Rules: []sarifRule{}}},
+		// Code scanning memakai id ini untuk membedakan hasil dari kategori analisis yang berbeda
+		AutomationDetails: &sarifAutomationDetails{ID: fmt.Sprintf("codereview/repository-%d/", r.Repository.ID)},
+		OriginalURIBaseIDs: map[string]sarifURIBaseID{
+			"%SRCROOT%": {Description: sarifMessage{Text: "Repository root"}},
+		},
+		Results: []sarifResult{},
+	}
+	if r.Repository.URL != "" {
+		run.VersionControlProvenance = []sarifVersionControl{{RepositoryURI: r.Repository.URL, RevisionID: r.Run.CommitSHA}}
+	}
+
+	invocation := sarifInvocation{
+		ExecutionSuccessful: r.Run.Status == entity.ReviewRunCompleted,
+		StartTimeUTC:        r.Run.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if r.Run.FinishedAt != nil {
+		invocation.EndTimeUTC = r.Run.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	if r.Run.Error != "" {
+		invocation.ToolExecutionNotifications = []sarifNotification{{Level: "error", Message: sarifMessage{Text: r.Run.Error}}}
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	ruleIndex := map[string]int{}
+	for i, rule := range rules(r.Findings) {
+		ruleIndex[rule.ID] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.ID},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	for _, f := range r.Findings {
+		result := sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: ruleIndex[f.RuleID],
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File, URIBaseID: "%SRCROOT%"},
+				Region: sarifRegion{
+					StartLine:   max(f.StartLine, 1),
+					StartColumn: f.StartColumn,
+					EndLine:     f.EndLine,
+					EndColumn:   f.EndColumn,
+				},
+			}}},
+		}
+		if f.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{"codereviewFingerprint/v1": f.Fingerprint}
+		}
+		if f.SuggestedFix != "" {
+			result.Properties = map[string]string{"suggestedFix": f.SuggestedFix}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
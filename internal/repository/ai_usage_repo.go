@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AIUsageRepository mencatat token AI yang dipakai review per user per bulan.
+// month selalu tanggal 1 bulan tersebut (UTC).
+type AIUsageRepository interface {
+	// ReserveAITokens menambah pemakaian jika totalnya tidak melewati quota (quota <= 0 berarti tanpa batas).
+	// false berarti kuota tidak cukup dan pemakaian tidak berubah.
+	ReserveAITokens(ctx context.Context, userID int, month time.Time, tokens int64, quota int64) (bool, error)
+	// AddAITokens mengoreksi pemakaian setelah jumlah token sebenarnya diketahui,
+	// delta negatif mengembalikan reservasi. Pemakaian tidak pernah di bawah 0.
+	AddAITokens(ctx context.Context, userID int, month time.Time, delta int64) error
+	GetAITokenUsage(ctx context.Context, userID int, month time.Time) (int64, error)
+}
+
+type aiUsageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAIUsageRepository(db *pgxpool.Pool) AIUsageRepository {
+	return &aiUsageRepository{db: db}
+}
+
+func (r *aiUsageRepository) ReserveAITokens(ctx context.Context, userID int, month time.Time, tokens int64, quota int64) (bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "aiUsageRepository.ReserveAITokens")
+	defer span.End()
+
+	if quota > 0 && tokens > quota {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Kondisi kuota dicek di dalam upsert supaya review paralel milik user yang sama tidak bisa melewatinya
+	query := `INSERT INTO ai_token_usage (user_id, month, tokens, updated_at)
+              VALUES (@user_id, @month, @tokens, NOW())
+              ON CONFLICT (user_id, month) DO UPDATE
+              SET tokens = ai_token_usage.tokens + EXCLUDED.tokens, updated_at = NOW()
+              WHERE @quota::bigint <= 0 OR ai_token_usage.tokens + EXCLUDED.tokens <= @quota::bigint
+              RETURNING tokens`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.user.id", userID),
+		attribute.Int64("ai.tokens", tokens),
+		attribute.Int64("ai.quota", quota),
+	)
+
+	var total int64
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{"user_id": userID, "month": month, "tokens": tokens, "quota": quota}).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *aiUsageRepository) AddAITokens(ctx context.Context, userID int, month time.Time, delta int64) error {
+	ctx, span := tracing.Tracer.Start(ctx, "aiUsageRepository.AddAITokens")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO ai_token_usage (user_id, month, tokens, updated_at)
+              VALUES (@user_id, @month, GREATEST(@delta::bigint, 0), NOW())
+              ON CONFLICT (user_id, month) DO UPDATE
+              SET tokens = GREATEST(ai_token_usage.tokens + @delta::bigint, 0), updated_at = NOW()`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.user.id", userID),
+		attribute.Int64("ai.tokens.delta", delta),
+	)
+
+	if _, err := r.db.Exec(ctx, query, pgx.NamedArgs{"user_id": userID, "month": month, "delta": delta}); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *aiUsageRepository) GetAITokenUsage(ctx context.Context, userID int, month time.Time) (int64, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "aiUsageRepository.GetAITokenUsage")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT tokens FROM ai_token_usage WHERE user_id = $1 AND month = $2`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.user.id", userID),
+	)
+
+	var tokens int64
+	err := r.db.QueryRow(ctx, query, userID, month).Scan(&tokens)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	return tokens, nil
+}
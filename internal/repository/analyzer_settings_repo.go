@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AnalyzerSettingsRepository menyimpan pengaturan code review per repository: analyzer yang
+// diaktifkan dan profil review default (dipakai jika repository tidak punya .gocrud-review.yml)
+type AnalyzerSettingsRepository interface {
+	// GetEnabledAnalyzers mengembalikan nil jika repository belum mengatur analyzer (pakai default)
+	GetEnabledAnalyzers(ctx context.Context, repoID int) ([]string, error)
+	// SetEnabledAnalyzers mengganti daftar analyzer, slice kosong kembali ke default
+	SetEnabledAnalyzers(ctx context.Context, repoID int, names []string) error
+	// GetReviewConfig mengembalikan nil jika repository belum punya profil review
+	GetReviewConfig(ctx context.Context, repoID int) (*entity.ReviewConfig, error)
+	SetReviewConfig(ctx context.Context, repoID int, cfg *entity.ReviewConfig) error
+}
+
+type analyzerSettingsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAnalyzerSettingsRepository(db *pgxpool.Pool) AnalyzerSettingsRepository {
+	return &analyzerSettingsRepository{db: db}
+}
+
+func (r *analyzerSettingsRepository) GetEnabledAnalyzers(ctx context.Context, repoID int) ([]string, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "analyzerSettingsRepository.GetEnabledAnalyzers")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT analyzer FROM repository_analyzers WHERE repository_id = $1 ORDER BY analyzer`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", repoID),
+	)
+
+	rows, err := r.db.Query(ctx, query, repoID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return names, nil
+}
+
+func (r *analyzerSettingsRepository) SetEnabledAnalyzers(ctx context.Context, repoID int, names []string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "analyzerSettingsRepository.SetEnabledAnalyzers")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "DELETE+COPY"),
+		attribute.Int("db.repository.id", repoID),
+		attribute.StringSlice("db.repository.analyzers", names),
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM repository_analyzers WHERE repository_id = $1`, repoID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if len(names) > 0 {
+		rows := make([][]any, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, []any{repoID, name})
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"repository_analyzers"}, []string{"repository_id", "analyzer"}, pgx.CopyFromRows(rows)); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *analyzerSettingsRepository) GetReviewConfig(ctx context.Context, repoID int) (*entity.ReviewConfig, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "analyzerSettingsRepository.GetReviewConfig")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT config FROM repository_review_configs WHERE repository_id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", repoID),
+	)
+
+	var cfg entity.ReviewConfig
+	err := r.db.QueryRow(ctx, query, repoID).Scan(&cfg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (r *analyzerSettingsRepository) SetReviewConfig(ctx context.Context, repoID int, cfg *entity.ReviewConfig) error {
+	ctx, span := tracing.Tracer.Start(ctx, "analyzerSettingsRepository.SetReviewConfig")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO repository_review_configs (repository_id, config, updated_at)
+              VALUES ($1, $2, NOW())
+              ON CONFLICT (repository_id) DO UPDATE SET config = EXCLUDED.config, updated_at = NOW()`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", repoID),
+	)
+
+	if _, err := r.db.Exec(ctx, query, repoID, cfg); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
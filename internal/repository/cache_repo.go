@@ -1 +1,58 @@
-package repository
\ No newline at end of file
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheMiss dikembalikan Get jika key tidak ada atau sudah expired
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheRepository adalah key-value cache yang dipakai usecase (cache user/repository)
+// dan dedupe store event. Implementasinya Redis atau in-memory.
+type CacheRepository interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Ping(ctx context.Context) error
+}
+
+type redisCacheRepository struct {
+	redis *redis.Client
+}
+
+func NewRedisCacheRepository(redisClient *redis.Client) CacheRepository {
+	return &redisCacheRepository{redis: redisClient}
+}
+
+func (r *redisCacheRepository) Get(ctx context.Context, key string) (string, error) {
+	val, err := r.redis.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	return val, err
+}
+
+func (r *redisCacheRepository) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return r.redis.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisCacheRepository) Delete(ctx context.Context, key string) error {
+	return r.redis.Del(ctx, key).Err()
+}
+
+func (r *redisCacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := r.redis.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *redisCacheRepository) Ping(ctx context.Context) error {
+	return r.redis.Ping(ctx).Err()
+}
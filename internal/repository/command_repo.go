@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrCommandNotFound dikembalikan jika command ID tidak ada
+var ErrCommandNotFound = errors.New("command not found")
+
+type CommandRepository interface {
+	// CreateCommands menyimpan semua command beserta event outbox-nya dalam satu transaksi
+	CreateCommands(ctx context.Context, cmds []*entity.Command, msgs []*entity.OutboxMessage) error
+	GetCommandByID(ctx context.Context, id string) (*entity.Command, error)
+	UpdateCommandStatus(ctx context.Context, id string, status string, entityID *int, errMsg string) error
+}
+
+type commandRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommandRepository(db *pgxpool.Pool) CommandRepository {
+	return &commandRepository{db: db}
+}
+
+// CreateCommands menyimpan command dan event outbox-nya (msgs[i] milik cmds[i]) dalam satu
+// transaksi, jadi event tidak akan terkirim tanpa command (dan sebaliknya) dan batch tidak
+// pernah tersimpan sebagian.
+func (r *commandRepository) CreateCommands(ctx context.Context, cmds []*entity.Command, msgs []*entity.OutboxMessage) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commandRepository.CreateCommands")
+	defer span.End()
+
+	if len(cmds) != len(msgs) {
+		return fmt.Errorf("create commands: %d commands with %d outbox messages", len(cmds), len(msgs))
+	}
+
+	query := `INSERT INTO commands (id, type, status, created_at, updated_at)
+              VALUES ($1, $2, $3, NOW(), NOW()) RETURNING created_at, updated_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.command.count", len(cmds)),
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, cmd := range cmds {
+		if err := tx.QueryRow(ctx, query, cmd.ID, cmd.Type, cmd.Status).Scan(&cmd.CreatedAt, &cmd.UpdatedAt); err != nil {
+			span.RecordError(err)
+			return err
+		}
+		if err := insertOutboxMessage(ctx, tx, msgs[i]); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *commandRepository) GetCommandByID(ctx context.Context, id string) (*entity.Command, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "commandRepository.GetCommandByID")
+	defer span.End()
+
+	query := `SELECT id, type, status, entity_id, COALESCE(error, ''), created_at, updated_at
+              FROM commands WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.String("db.command.id", id),
+	)
+
+	var cmd entity.Command
+	err := r.db.QueryRow(ctx, query, id).Scan(&cmd.ID, &cmd.Type, &cmd.Status, &cmd.EntityID, &cmd.Error, &cmd.CreatedAt, &cmd.UpdatedAt)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommandNotFound
+		}
+		return nil, err
+	}
+
+	return &cmd, nil
+}
+
+func (r *commandRepository) UpdateCommandStatus(ctx context.Context, id string, status string, entityID *int, errMsg string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commandRepository.UpdateCommandStatus")
+	defer span.End()
+
+	query := `UPDATE commands SET status = $1, entity_id = $2, error = NULLIF($3, ''), updated_at = NOW()
+              WHERE id = $4`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.command.id", id),
+		attribute.String("db.command.status", status),
+	)
+
+	tag, err := r.db.Exec(ctx, query, status, entityID, errMsg, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCommandNotFound
+	}
+	return nil
+}
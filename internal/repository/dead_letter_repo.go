@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrDeadLetterReplayed = errors.New("dead letter already replayed")
+	// ErrDeadLetterUndecodable dikembalikan replay jika pesan tidak punya envelope dan payload-nya bukan JSON
+	ErrDeadLetterUndecodable = errors.New("dead letter payload cannot be decoded into an event")
+)
+
+type DeadLetterRepository interface {
+	InsertDeadLetter(ctx context.Context, dl *entity.DeadLetter) error
+	GetDeadLetters(ctx context.Context, status string, limit int) ([]entity.DeadLetter, error)
+	Replay(ctx context.Context, id int64) error
+}
+
+type deadLetterRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDeadLetterRepository(db *pgxpool.Pool) DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+func (r *deadLetterRepository) InsertDeadLetter(ctx context.Context, dl *entity.DeadLetter) error {
+	ctx, span := tracing.Tracer.Start(ctx, "deadLetterRepository.InsertDeadLetter")
+	defer span.End()
+
+	query := `INSERT INTO dead_letters (topic, original_topic, original_partition, original_offset, event_type, payload, headers, envelope, attempts, error, status, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'pending', NOW()) RETURNING id, status, created_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+		attribute.String("db.dead_letter.topic", dl.Topic),
+	)
+
+	headers, err := json.Marshal(dl.Headers)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// envelope NULL (bukan 'null') jika pesan tidak bisa di-decode
+	var envelope []byte
+	if len(dl.Envelope) > 0 {
+		envelope = dl.Envelope
+	}
+
+	err = r.db.QueryRow(ctx, query, dl.Topic, dl.OriginalTopic, dl.OriginalPartition, dl.OriginalOffset, dl.EventType, dl.Payload, headers, envelope, dl.Attempts, dl.Error).
+		Scan(&dl.ID, &dl.Status, &dl.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+func (r *deadLetterRepository) GetDeadLetters(ctx context.Context, status string, limit int) ([]entity.DeadLetter, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "deadLetterRepository.GetDeadLetters")
+	defer span.End()
+
+	query := `SELECT id, topic, original_topic, original_partition, original_offset, event_type, payload, headers, envelope, attempts, error, status, created_at, replayed_at
+              FROM dead_letters WHERE ($1 = '' OR status = $1) ORDER BY id DESC LIMIT $2`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.String("db.dead_letter.status", status),
+	)
+
+	rows, err := r.db.Query(ctx, query, status, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []entity.DeadLetter
+	for rows.Next() {
+		var dl entity.DeadLetter
+		var headers []byte
+		if err := rows.Scan(&dl.ID, &dl.Topic, &dl.OriginalTopic, &dl.OriginalPartition, &dl.OriginalOffset, &dl.EventType, &dl.Payload, &headers, &dl.Envelope, &dl.Attempts, &dl.Error, &dl.Status, &dl.CreatedAt, &dl.ReplayedAt); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if err := json.Unmarshal(headers, &dl.Headers); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		letters = append(letters, dl)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("db.result.count", len(letters)))
+	return letters, nil
+}
+
+// Replay membangun ulang envelope (lihat ReplayEnvelope), memasukkannya ke outbox untuk topic
+// asalnya dan menandai pesan sebagai replayed
+func (r *deadLetterRepository) Replay(ctx context.Context, id int64) error {
+	ctx, span := tracing.Tracer.Start(ctx, "deadLetterRepository.Replay")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.Int64("db.dead_letter.id", id),
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var dl entity.DeadLetter
+	query := "SELECT original_topic, event_type, payload, envelope, status FROM dead_letters WHERE id = $1 FOR UPDATE"
+	if err := tx.QueryRow(ctx, query, id).Scan(&dl.OriginalTopic, &dl.EventType, &dl.Payload, &dl.Envelope, &dl.Status); err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDeadLetterNotFound
+		}
+		return err
+	}
+	if dl.Status == entity.DeadLetterStatusReplayed {
+		return ErrDeadLetterReplayed
+	}
+
+	payload, err := ReplayEnvelope(&dl)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	msg := entity.OutboxMessage{Topic: dl.OriginalTopic, EventType: dl.EventType, Payload: payload}
+	if err := insertOutboxMessage(ctx, tx, &msg); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE dead_letters SET status = 'replayed', replayed_at = NOW() WHERE id = $1", id); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReplayEnvelope mengembalikan envelope JSON untuk outbox. Envelope hasil decode consumer
+// dipakai jika ada; jika tidak, payload dibaca sebagai envelope structured JSON atau payload
+// lama tanpa envelope. ID event asli dipertahankan supaya replay tetap idempotent.
+func ReplayEnvelope(dl *entity.DeadLetter) ([]byte, error) {
+	if len(dl.Envelope) > 0 {
+		return dl.Envelope, nil
+	}
+
+	var env event.Envelope
+	if err := json.Unmarshal(dl.Payload, &env); err == nil && env.Validate() == nil {
+		return json.Marshal(&env)
+	}
+	legacy, err := event.FromLegacy(dl.EventType, dl.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterUndecodable, err)
+	}
+	if err := event.Upcast(legacy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterUndecodable, err)
+	}
+	return json.Marshal(legacy)
+}
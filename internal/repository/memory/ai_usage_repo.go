@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/repository"
+	"time"
+)
+
+// aiUsageKey adalah primary key (user_id, month) tabel ai_token_usage
+type aiUsageKey struct {
+	userID int
+	month  string
+}
+
+func newAIUsageKey(userID int, month time.Time) aiUsageKey {
+	return aiUsageKey{userID: userID, month: month.UTC().Format("2006-01")}
+}
+
+type aiUsageRepository struct {
+	store *Store
+}
+
+func NewAIUsageRepository(store *Store) repository.AIUsageRepository {
+	return &aiUsageRepository{store: store}
+}
+
+func (r *aiUsageRepository) ReserveAITokens(ctx context.Context, userID int, month time.Time, tokens int64, quota int64) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := newAIUsageKey(userID, month)
+	if quota > 0 && s.aiTokenUsage[key]+tokens > quota {
+		return false, nil
+	}
+	s.aiTokenUsage[key] += tokens
+	return true, nil
+}
+
+func (r *aiUsageRepository) AddAITokens(ctx context.Context, userID int, month time.Time, delta int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := newAIUsageKey(userID, month)
+	s.aiTokenUsage[key] = max(s.aiTokenUsage[key]+delta, 0)
+	return nil
+}
+
+func (r *aiUsageRepository) GetAITokenUsage(ctx context.Context, userID int, month time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.aiTokenUsage[newAIUsageKey(userID, month)], nil
+}
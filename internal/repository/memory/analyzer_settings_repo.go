@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"maps"
+	"slices"
+	"sort"
+)
+
+type analyzerSettingsRepository struct {
+	store *Store
+}
+
+func NewAnalyzerSettingsRepository(store *Store) repository.AnalyzerSettingsRepository {
+	return &analyzerSettingsRepository{store: store}
+}
+
+func (r *analyzerSettingsRepository) GetEnabledAnalyzers(ctx context.Context, repoID int) ([]string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names, ok := s.repoAnalyzers[repoID]
+	if !ok {
+		return nil, nil
+	}
+	return append([]string{}, names...), nil
+}
+
+func (r *analyzerSettingsRepository) SetEnabledAnalyzers(ctx context.Context, repoID int, names []string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(names) == 0 {
+		delete(s.repoAnalyzers, repoID)
+		return nil
+	}
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	s.repoAnalyzers[repoID] = sorted
+	return nil
+}
+
+func (r *analyzerSettingsRepository) GetReviewConfig(ctx context.Context, repoID int) (*entity.ReviewConfig, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg, ok := s.repoReviewConfigs[repoID]
+	if !ok {
+		return nil, nil
+	}
+	cfg = cloneReviewConfig(cfg)
+	return &cfg, nil
+}
+
+func (r *analyzerSettingsRepository) SetReviewConfig(ctx context.Context, repoID int, cfg *entity.ReviewConfig) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repoReviewConfigs[repoID] = cloneReviewConfig(*cfg)
+	return nil
+}
+
+// cloneReviewConfig menyalin map dan slice supaya profil tersimpan tidak ikut berubah dari luar,
+// sama seperti kolom jsonb di Postgres
+func cloneReviewConfig(cfg entity.ReviewConfig) entity.ReviewConfig {
+	cfg.Analyzers.Enable = slices.Clone(cfg.Analyzers.Enable)
+	cfg.Analyzers.Disable = slices.Clone(cfg.Analyzers.Disable)
+	cfg.Severity = maps.Clone(cfg.Severity)
+	cfg.Include = slices.Clone(cfg.Include)
+	cfg.Exclude = slices.Clone(cfg.Exclude)
+	if cfg.FailOn != nil {
+		failOn := *cfg.FailOn
+		cfg.FailOn = &failOn
+	}
+	return cfg
+}
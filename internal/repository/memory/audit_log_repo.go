@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"time"
+)
+
+type auditLogRepo struct {
+	store *Store
+}
+
+func NewAuditLogRepository(store *Store) repository.AuditLogMongoRepository {
+	return &auditLogRepo{store: store}
+}
+
+func (r *auditLogRepo) InsertLog(ctx context.Context, log *entity.AuditLog) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Timestamp = time.Now()
+	s.auditLogs = append(s.auditLogs, *log)
+	return nil
+}
+
+func (r *auditLogRepo) GetLogs(ctx context.Context, userID int) ([]entity.AuditLog, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []entity.AuditLog
+	for _, l := range s.auditLogs {
+		if l.UserID == userID {
+			results = append(results, l)
+		}
+	}
+	return results, nil
+}
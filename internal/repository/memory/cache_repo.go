@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/repository"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value     string
+	expiresAt time.Time // zero berarti tanpa TTL
+}
+
+// cacheRepository pengganti Redis; key yang expired dibuang saat dibaca
+type cacheRepository struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewCacheRepository() repository.CacheRepository {
+	return &cacheRepository{entries: make(map[string]cacheEntry)}
+}
+
+func (c *cacheRepository) lookupLocked(key string) (cacheEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *cacheRepository) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookupLocked(key)
+	if !ok {
+		return "", repository.ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (c *cacheRepository) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := cacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *cacheRepository) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *cacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.lookupLocked(key)
+	return ok, nil
+}
+
+func (c *cacheRepository) Ping(ctx context.Context) error {
+	return nil
+}
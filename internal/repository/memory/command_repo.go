@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"time"
+)
+
+type commandRepository struct {
+	store *Store
+}
+
+func NewCommandRepository(store *Store) repository.CommandRepository {
+	return &commandRepository{store: store}
+}
+
+// CreateCommands menyimpan semua command dan event outbox-nya di bawah lock yang sama
+func (r *commandRepository) CreateCommands(ctx context.Context, cmds []*entity.Command, msgs []*entity.OutboxMessage) error {
+	if len(cmds) != len(msgs) {
+		return fmt.Errorf("create commands: %d commands with %d outbox messages", len(cmds), len(msgs))
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i, cmd := range cmds {
+		cmd.CreatedAt = now
+		cmd.UpdatedAt = now
+		s.commands[cmd.ID] = *cmd
+		s.insertOutboxLocked(msgs[i])
+	}
+	return nil
+}
+
+func (r *commandRepository) GetCommandByID(ctx context.Context, id string) (*entity.Command, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, ok := s.commands[id]
+	if !ok {
+		return nil, repository.ErrCommandNotFound
+	}
+	return &cmd, nil
+}
+
+func (r *commandRepository) UpdateCommandStatus(ctx context.Context, id string, status string, entityID *int, errMsg string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, ok := s.commands[id]
+	if !ok {
+		return repository.ErrCommandNotFound
+	}
+	cmd.Status = status
+	cmd.EntityID = entityID
+	cmd.Error = errMsg
+	cmd.UpdatedAt = time.Now()
+	s.commands[id] = cmd
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"time"
+)
+
+type deadLetterRepository struct {
+	store *Store
+}
+
+func NewDeadLetterRepository(store *Store) repository.DeadLetterRepository {
+	return &deadLetterRepository{store: store}
+}
+
+func (r *deadLetterRepository) InsertDeadLetter(ctx context.Context, dl *entity.DeadLetter) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextDeadLetterID++
+	dl.ID = s.nextDeadLetterID
+	dl.Status = entity.DeadLetterStatusPending
+	dl.CreatedAt = time.Now()
+
+	stored := *dl
+	s.deadLetters = append(s.deadLetters, &stored)
+	return nil
+}
+
+func (r *deadLetterRepository) GetDeadLetters(ctx context.Context, status string, limit int) ([]entity.DeadLetter, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []entity.DeadLetter
+	for i := len(s.deadLetters) - 1; i >= 0 && len(results) < limit; i-- {
+		dl := s.deadLetters[i]
+		if status == "" || dl.Status == status {
+			results = append(results, *dl)
+		}
+	}
+	return results, nil
+}
+
+// Replay memasukkan envelope (repository.ReplayEnvelope) ke outbox topic asal dan menandai dead letter sudah di-replay
+func (r *deadLetterRepository) Replay(ctx context.Context, id int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dl *entity.DeadLetter
+	for _, d := range s.deadLetters {
+		if d.ID == id {
+			dl = d
+			break
+		}
+	}
+	if dl == nil {
+		return repository.ErrDeadLetterNotFound
+	}
+	if dl.Status == entity.DeadLetterStatusReplayed {
+		return repository.ErrDeadLetterReplayed
+	}
+
+	payload, err := repository.ReplayEnvelope(dl)
+	if err != nil {
+		return err
+	}
+	s.insertOutboxLocked(&entity.OutboxMessage{
+		Topic:     dl.OriginalTopic,
+		EventType: dl.EventType,
+		Payload:   payload,
+	})
+
+	now := time.Now()
+	dl.Status = entity.DeadLetterStatusReplayed
+	dl.ReplayedAt = &now
+	return nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"slices"
+	"time"
+)
+
+type notificationPreferenceRepository struct {
+	store *Store
+}
+
+func NewNotificationPreferenceRepository(store *Store) repository.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{store: store}
+}
+
+func (r *notificationPreferenceRepository) GetNotificationPreferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.notificationPreferences[userID]
+	if !ok {
+		return nil, repository.ErrNotificationPreferencesNotFound
+	}
+	p.Channels = slices.Clone(p.Channels)
+	return &p, nil
+}
+
+func (r *notificationPreferenceRepository) SaveNotificationPreferences(ctx context.Context, prefs *entity.NotificationPreferences) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	prefs.UpdatedAt = &now
+	p := *prefs
+	p.Channels = slices.Clone(prefs.Channels)
+	if p.Channels == nil {
+		p.Channels = []string{}
+	}
+	s.notificationPreferences[prefs.UserID] = p
+	return nil
+}
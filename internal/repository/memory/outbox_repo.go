@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"time"
+)
+
+// outboxClaimTimeout sama dengan versi Postgres
+const outboxClaimTimeout = 30 * time.Second
+
+type outboxRepository struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) repository.OutboxRepository {
+	return &outboxRepository{store: store}
+}
+
+func (s *Store) insertOutboxLocked(msg *entity.OutboxMessage) {
+	s.nextOutboxID++
+	now := time.Now()
+	msg.ID = s.nextOutboxID
+	msg.Status = entity.OutboxStatusPending
+	msg.NextAttemptAt = now
+	msg.CreatedAt = now
+
+	stored := *msg
+	s.outbox = append(s.outbox, &stored)
+}
+
+func (s *Store) findOutboxLocked(id int64) *entity.OutboxMessage {
+	for _, msg := range s.outbox {
+		if msg.ID == id {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int) ([]entity.OutboxMessage, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var messages []entity.OutboxMessage
+	for _, msg := range s.outbox {
+		if len(messages) >= limit {
+			break
+		}
+		if msg.Status != entity.OutboxStatusPending || msg.NextAttemptAt.After(now) {
+			continue
+		}
+		msg.NextAttemptAt = now.Add(outboxClaimTimeout)
+		messages = append(messages, *msg)
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg := s.findOutboxLocked(id); msg != nil {
+		now := time.Now()
+		msg.Status = entity.OutboxStatusDelivered
+		msg.DeliveredAt = &now
+		msg.LastError = ""
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, backoff time.Duration) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg := s.findOutboxLocked(id); msg != nil {
+		msg.Attempts++
+		msg.LastError = errMsg
+		msg.NextAttemptAt = time.Now().Add(backoff)
+	}
+	return nil
+}
+
+func (r *outboxRepository) GetStats(ctx context.Context) (*entity.OutboxStats, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := &entity.OutboxStats{}
+	for _, msg := range s.outbox {
+		if msg.Status != entity.OutboxStatusPending {
+			continue
+		}
+		stats.Pending++
+		if msg.Attempts > 0 {
+			stats.Retrying++
+		}
+		if stats.OldestPendingAt == nil || msg.CreatedAt.Before(*stats.OldestPendingAt) {
+			createdAt := msg.CreatedAt
+			stats.OldestPendingAt = &createdAt
+		}
+	}
+	if stats.OldestPendingAt != nil {
+		stats.LagSeconds = time.Since(*stats.OldestPendingAt).Seconds()
+	}
+	return stats, nil
+}
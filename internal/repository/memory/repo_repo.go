@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"slices"
+	"sort"
+	"time"
+)
+
+type repoRepository struct {
+	store *Store
+}
+
+func NewRepositoryRepository(store *Store) repository.RepositoryRepository {
+	return &repoRepository{store: store}
+}
+
+func (r *repoRepository) CreateRepository(ctx context.Context, repo *entity.Repository) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sama seperti repositories_event_id_key: event yang sama tidak membuat baris baru
+	if repo.EventID != "" {
+		for _, existing := range s.repositories {
+			if existing.EventID == repo.EventID {
+				repo.ID = existing.ID
+				return nil
+			}
+		}
+	}
+
+	// Sama seperti foreign key repositories_user_id_fkey
+	if _, ok := s.users[repo.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"repositories\" violates foreign key constraint \"repositories_user_id_fkey\"")
+	}
+
+	s.nextRepoID++
+	now := time.Now()
+	repo.ID = s.nextRepoID
+	repo.CreatedAt = now
+	repo.UpdatedAt = now
+	s.repositories[repo.ID] = *repo
+	return nil
+}
+
+func (r *repoRepository) GetRepositoryByID(ctx context.Context, id int) (*entity.Repository, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *repoRepository) GetByID(ctx context.Context, id int) (*entity.Repository, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.repositories[id]
+	if !ok {
+		return nil, repository.ErrRepositoryNotFound
+	}
+	return &repo, nil
+}
+
+func (r *repoRepository) GetAllRepositories(ctx context.Context) ([]entity.Repository, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterRepositoriesLocked(func(entity.Repository) bool { return true }), nil
+}
+
+func (r *repoRepository) GetRepositoriesByURLKeys(ctx context.Context, keys []string) ([]entity.Repository, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterRepositoriesLocked(func(repo entity.Repository) bool {
+		return slices.Contains(keys, entity.NormalizeGitURL(repo.URL))
+	}), nil
+}
+
+func (r *repoRepository) GetRepositoriesByUserID(ctx context.Context, userID int) ([]entity.Repository, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repositories := s.filterRepositoriesLocked(func(repo entity.Repository) bool { return repo.UserID == userID })
+	if repositories == nil {
+		return []entity.Repository{}, nil
+	}
+	return repositories, nil
+}
+
+func (r *repoRepository) Update(ctx context.Context, repo *entity.Repository) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.repositories[repo.ID]
+	if !ok {
+		return repository.ErrRepositoryNotFound
+	}
+	existing.Name = repo.Name
+	existing.URL = repo.URL
+	existing.AIEnabled = repo.AIEnabled
+	existing.UpdatedAt = time.Now()
+	s.repositories[repo.ID] = existing
+	return nil
+}
+
+func (r *repoRepository) Delete(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteRepositoryLocked(id)
+	return nil
+}
+
+func (s *Store) filterRepositoriesLocked(keep func(entity.Repository) bool) []entity.Repository {
+	var repositories []entity.Repository
+	for _, repo := range s.repositories {
+		if keep(repo) {
+			repositories = append(repositories, repo)
+		}
+	}
+	sort.Slice(repositories, func(i, j int) bool { return repositories[i].ID < repositories[j].ID })
+	return repositories
+}
+
+// deleteRepositoryLocked ikut menghapus log code review (ON DELETE CASCADE)
+func (s *Store) deleteRepositoryLocked(id int) {
+	delete(s.repositories, id)
+	delete(s.repoAnalyzers, id)
+	delete(s.repoReviewConfigs, id)
+	delete(s.webhookSecrets, id)
+
+	for jobID, job := range s.reviewJobs {
+		if job.RepositoryID == id {
+			delete(s.reviewJobs, jobID)
+		}
+	}
+	for runID, run := range s.reviewRuns {
+		if run.RepositoryID == id {
+			delete(s.reviewRuns, runID)
+			delete(s.reviewFindings, runID)
+		}
+	}
+}
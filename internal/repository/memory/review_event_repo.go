@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultReviewEventCapacity = 2000
+	reviewEventIdleTTL         = 24 * time.Hour
+)
+
+// reviewEventBuffer adalah ring buffer event satu job. Event ke-i (0-based sejak job dibuat)
+// punya ID i+1, jadi Last-Event-ID cukup dibandingkan sebagai angka.
+type reviewEventBuffer struct {
+	events    []entity.ReviewEvent
+	start     int   // posisi event tertua di events
+	count     int   // jumlah event yang tersimpan
+	firstSeq  int64 // ID event tertua yang masih tersimpan
+	notify    chan struct{}
+	updatedAt time.Time
+}
+
+func (b *reviewEventBuffer) append(ev entity.ReviewEvent) {
+	capacity := len(b.events)
+	if b.count < capacity {
+		b.events[(b.start+b.count)%capacity] = ev
+		b.count++
+	} else {
+		// Penuh, timpa event tertua
+		b.events[b.start] = ev
+		b.start = (b.start + 1) % capacity
+		b.firstSeq++
+	}
+}
+
+// after mengembalikan event dengan ID > seq
+func (b *reviewEventBuffer) after(seq int64) []entity.ReviewEvent {
+	skip := int(max(seq-b.firstSeq+1, 0))
+	out := []entity.ReviewEvent{}
+	for i := skip; i < b.count; i++ {
+		out = append(out, b.events[(b.start+i)%len(b.events)])
+	}
+	return out
+}
+
+// reviewEventRepository pengganti Redis stream; buffer job yang lama tidak menerima event dibuang
+type reviewEventRepository struct {
+	mu       sync.Mutex
+	capacity int
+	buffers  map[string]*reviewEventBuffer
+}
+
+func NewReviewEventRepository(capacity int) repository.ReviewEventRepository {
+	if capacity < 1 {
+		capacity = defaultReviewEventCapacity
+	}
+	return &reviewEventRepository{
+		capacity: capacity,
+		buffers:  make(map[string]*reviewEventBuffer),
+	}
+}
+
+func (r *reviewEventRepository) AppendReviewEvent(ctx context.Context, ev *entity.ReviewEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	b, ok := r.buffers[ev.JobID]
+	if !ok {
+		r.sweepLocked(now)
+		b = &reviewEventBuffer{
+			events:   make([]entity.ReviewEvent, r.capacity),
+			firstSeq: 1,
+			notify:   make(chan struct{}),
+		}
+		r.buffers[ev.JobID] = b
+	}
+
+	ev.ID = strconv.FormatInt(b.firstSeq+int64(b.count), 10)
+	b.append(*ev)
+	b.updatedAt = now
+
+	// Bangunkan semua pembaca yang sedang menunggu
+	close(b.notify)
+	b.notify = make(chan struct{})
+	return nil
+}
+
+func (r *reviewEventRepository) ReadReviewEvents(ctx context.Context, jobID, afterID string, wait time.Duration) ([]entity.ReviewEvent, error) {
+	// ID yang tidak valid dibaca dari awal, sama seperti implementasi Redis
+	seq, err := strconv.ParseInt(afterID, 10, 64)
+	if err != nil || seq < 0 {
+		seq = 0
+	}
+
+	deadline := time.NewTimer(max(wait, 0))
+	defer deadline.Stop()
+
+	for {
+		r.mu.Lock()
+		b, ok := r.buffers[jobID]
+		if !ok {
+			// Belum ada event, buat buffer kosong supaya pembaca bisa menunggu notify
+			b = &reviewEventBuffer{
+				events:    make([]entity.ReviewEvent, r.capacity),
+				firstSeq:  1,
+				notify:    make(chan struct{}),
+				updatedAt: time.Now(),
+			}
+			r.buffers[jobID] = b
+		}
+		events := b.after(seq)
+		notify := b.notify
+		r.mu.Unlock()
+
+		if len(events) > 0 || wait <= 0 {
+			return events, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return []entity.ReviewEvent{}, nil
+		case <-notify:
+		}
+	}
+}
+
+// sweepLocked membuang buffer job yang sudah lama tidak menerima event
+func (r *reviewEventRepository) sweepLocked(now time.Time) {
+	for jobID, b := range r.buffers {
+		if now.Sub(b.updatedAt) > reviewEventIdleTTL {
+			delete(r.buffers, jobID)
+		}
+	}
+}
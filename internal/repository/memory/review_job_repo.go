@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"time"
+)
+
+type reviewJobRepository struct {
+	store *Store
+}
+
+func NewReviewJobRepository(store *Store) repository.ReviewJobRepository {
+	return &reviewJobRepository{store: store}
+}
+
+func (r *reviewJobRepository) EnqueueJob(ctx context.Context, job *entity.ReviewJob) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.Transitions = []entity.ReviewJobTransition{}
+	s.reviewJobs[job.ID] = *job
+	return nil
+}
+
+// ClaimNextJob memilih job queued tertua, lock Store menggantikan SKIP LOCKED
+func (r *reviewJobRepository) ClaimNextJob(ctx context.Context, workerID string, lease time.Duration) (*entity.ReviewJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *entity.ReviewJob
+	for _, job := range s.reviewJobs {
+		if job.Status != entity.ReviewJobQueued {
+			continue
+		}
+		if next == nil || job.CreatedAt.Before(next.CreatedAt) {
+			j := job
+			next = &j
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	transitionLocked(next, entity.ReviewJobRunning, "", now)
+	next.Attempts++
+	next.Error = ""
+	next.WorkerID = workerID
+	expires := now.Add(lease)
+	next.LeaseExpiresAt = &expires
+	next.StartedAt = &now
+	next.FinishedAt = nil
+	s.reviewJobs[next.ID] = *next
+	return copyJob(next), nil
+}
+
+func (r *reviewJobRepository) FinishJob(ctx context.Context, id string, workerID string, status string, runID *int64, errMsg string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.heldLocked(id, workerID)
+	if !ok {
+		return repository.ErrReviewJobLeaseLost
+	}
+	now := time.Now()
+	transitionLocked(&job, status, errMsg, now)
+	if runID != nil {
+		job.RunID = runID
+	}
+	job.Error = errMsg
+	job.CancelRequested = false
+	job.WorkerID = ""
+	job.LeaseExpiresAt = nil
+	job.Checkpoint = nil
+	job.FinishedAt = &now
+	s.reviewJobs[id] = job
+	return nil
+}
+
+func (r *reviewJobRepository) Heartbeat(ctx context.Context, id string, workerID string, lease time.Duration) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.heldLocked(id, workerID)
+	if !ok {
+		return false, repository.ErrReviewJobLeaseLost
+	}
+	expires := time.Now().Add(lease)
+	job.LeaseExpiresAt = &expires
+	s.reviewJobs[id] = job
+	return job.CancelRequested, nil
+}
+
+func (r *reviewJobRepository) SaveCheckpoint(ctx context.Context, id string, workerID string, checkpoint *entity.ReviewCheckpoint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.heldLocked(id, workerID)
+	if !ok {
+		return repository.ErrReviewJobLeaseLost
+	}
+	cp := *checkpoint
+	cp.FilesDone = append([]string{}, checkpoint.FilesDone...)
+	cp.Findings = append([]entity.ReviewFinding{}, checkpoint.Findings...)
+	runID := cp.RunID
+	job.Checkpoint = &cp
+	job.RunID = &runID
+	job.UpdatedAt = time.Now()
+	s.reviewJobs[id] = job
+	return nil
+}
+
+func (r *reviewJobRepository) ReleaseJob(ctx context.Context, id string, workerID string, reason string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.heldLocked(id, workerID)
+	if !ok {
+		return repository.ErrReviewJobLeaseLost
+	}
+	transitionLocked(&job, entity.ReviewJobQueued, reason, time.Now())
+	job.WorkerID = ""
+	job.LeaseExpiresAt = nil
+	job.StartedAt = nil
+	s.reviewJobs[id] = job
+	return nil
+}
+
+func (r *reviewJobRepository) RequeueExpiredJobs(ctx context.Context) ([]entity.ReviewJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var jobs []entity.ReviewJob
+	for id, job := range s.reviewJobs {
+		if job.Status != entity.ReviewJobRunning || job.LeaseExpiresAt == nil || !job.LeaseExpiresAt.Before(now) {
+			continue
+		}
+		worker := job.WorkerID
+		if worker == "" {
+			worker = "unknown"
+		}
+		transitionLocked(&job, entity.ReviewJobQueued, "lease expired on worker "+worker, now)
+		job.WorkerID = ""
+		job.LeaseExpiresAt = nil
+		job.StartedAt = nil
+		s.reviewJobs[id] = job
+		jobs = append(jobs, *copyJob(&job))
+	}
+	return jobs, nil
+}
+
+func (r *reviewJobRepository) CancelJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.reviewJobs[id]
+	if !ok {
+		return nil, repository.ErrReviewJobNotFound
+	}
+
+	now := time.Now()
+	switch job.Status {
+	case entity.ReviewJobQueued:
+		transitionLocked(&job, entity.ReviewJobCancelled, reason, now)
+		job.FinishedAt = &now
+	case entity.ReviewJobRunning:
+		// Dihentikan oleh worker, transisinya dicatat saat FinishJob
+		job.CancelRequested = true
+		job.UpdatedAt = now
+	default:
+		return nil, repository.ErrReviewJobFinished
+	}
+	s.reviewJobs[id] = job
+	return copyJob(&job), nil
+}
+
+func (r *reviewJobRepository) RetryJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.reviewJobs[id]
+	if !ok {
+		return nil, repository.ErrReviewJobNotFound
+	}
+	if job.Status != entity.ReviewJobFailed && job.Status != entity.ReviewJobCancelled {
+		return nil, repository.ErrReviewJobNotRetryable
+	}
+
+	transitionLocked(&job, entity.ReviewJobQueued, reason, time.Now())
+	job.Error = ""
+	job.CancelRequested = false
+	job.Checkpoint = nil
+	job.StartedAt = nil
+	job.FinishedAt = nil
+	s.reviewJobs[id] = job
+	return copyJob(&job), nil
+}
+
+func (r *reviewJobRepository) GetJobByID(ctx context.Context, id string) (*entity.ReviewJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.reviewJobs[id]
+	if !ok {
+		return nil, repository.ErrReviewJobNotFound
+	}
+	return copyJob(&job), nil
+}
+
+// heldLocked mengembalikan job running yang lease-nya dipegang workerID, dipanggil dengan s.mu terkunci
+func (s *Store) heldLocked(id, workerID string) (entity.ReviewJob, bool) {
+	job, ok := s.reviewJobs[id]
+	if !ok || job.Status != entity.ReviewJobRunning || job.WorkerID != workerID {
+		return entity.ReviewJob{}, false
+	}
+	return job, true
+}
+
+// transitionLocked mengubah status dan mencatat transisinya, dipanggil dengan s.mu terkunci
+func transitionLocked(job *entity.ReviewJob, to, reason string, at time.Time) {
+	job.Transitions = append(job.Transitions, entity.ReviewJobTransition{
+		From:   job.Status,
+		To:     to,
+		Reason: reason,
+		At:     at,
+	})
+	job.Status = to
+	job.UpdatedAt = at
+}
+
+// copyJob supaya slice transitions yang dikembalikan tidak berbagi array dengan Store
+func copyJob(job *entity.ReviewJob) *entity.ReviewJob {
+	c := *job
+	c.Transitions = append([]entity.ReviewJobTransition{}, job.Transitions...)
+	return &c
+}
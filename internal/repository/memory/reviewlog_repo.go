@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"sort"
+	"time"
+)
+
+type codeReviewRepository struct {
+	store *Store
+}
+
+func NewCodeReviewRepository(store *Store) repository.CodeReviewRepository {
+	return &codeReviewRepository{store: store}
+}
+
+func (r *codeReviewRepository) CreateReviewRun(ctx context.Context, run *entity.ReviewRun) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextReviewRunID++
+	run.ID = s.nextReviewRunID
+	run.CreatedAt = time.Now()
+	s.reviewRuns[run.ID] = *run
+	return nil
+}
+
+func (r *codeReviewRepository) FinishReviewRun(ctx context.Context, run *entity.ReviewRun, findings []entity.ReviewFinding) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reviewRuns[run.ID]; !ok {
+		return repository.ErrReviewRunNotFound
+	}
+
+	stored := make([]entity.ReviewFinding, 0, len(findings))
+	for _, f := range findings {
+		s.nextReviewFindID++
+		f.ID = s.nextReviewFindID
+		f.RunID = run.ID
+		stored = append(stored, f)
+	}
+	s.reviewFindings[run.ID] = append(s.reviewFindings[run.ID], stored...)
+	s.reviewRuns[run.ID] = *run
+	return nil
+}
+
+func (r *codeReviewRepository) ResumeReviewRun(ctx context.Context, id int64) (*entity.ReviewRun, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.reviewRuns[id]
+	if !ok {
+		return nil, repository.ErrReviewRunNotFound
+	}
+	now := time.Now()
+	run.Status = entity.ReviewRunRunning
+	run.Error = ""
+	run.Summary = entity.ReviewSummary{}
+	run.FinishedAt = nil
+	run.ResumeCount++
+	run.ResumedAt = &now
+	delete(s.reviewFindings, id)
+	s.reviewRuns[id] = run
+	return &run, nil
+}
+
+func (r *codeReviewRepository) GetReviewRunsByRepoID(ctx context.Context, repoID int) ([]entity.ReviewRun, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []entity.ReviewRun
+	for _, run := range s.reviewRuns {
+		if run.RepositoryID == repoID {
+			runs = append(runs, run)
+		}
+	}
+	if len(runs) == 0 {
+		return nil, repository.ErrNoReviewRuns
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	return runs, nil
+}
+
+func (r *codeReviewRepository) GetReviewRunByID(ctx context.Context, id int64) (*entity.ReviewRun, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.reviewRuns[id]
+	if !ok {
+		return nil, repository.ErrReviewRunNotFound
+	}
+	return &run, nil
+}
+
+func (r *codeReviewRepository) GetReviewFindingsByRunID(ctx context.Context, runID int64) ([]entity.ReviewFinding, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]entity.ReviewFinding{}, s.reviewFindings[runID]...), nil
+}
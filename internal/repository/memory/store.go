@@ -0,0 +1,70 @@
+// Package memory berisi implementasi in-memory dari interface di package repository,
+// dipakai saat aplikasi dijalankan dengan --backend=memory (tanpa Postgres, Redis dan MongoDB).
+// Semua data hilang saat proses berhenti.
+package memory
+
+import (
+	"go-crud/internal/entity"
+	"sync"
+)
+
+// Store menyimpan semua "tabel" di satu tempat supaya operasi yang di Postgres
+// berjalan dalam satu transaksi (command + outbox, replay DLQ) tetap atomik.
+type Store struct {
+	mu sync.Mutex
+
+	users      map[int]entity.User
+	nextUserID int
+
+	repositories map[int]entity.Repository
+	nextRepoID   int
+
+	reviewRuns       map[int64]entity.ReviewRun
+	reviewFindings   map[int64][]entity.ReviewFinding
+	nextReviewRunID  int64
+	nextReviewFindID int64
+
+	reviewJobs map[string]entity.ReviewJob
+
+	repoAnalyzers     map[int][]string
+	repoReviewConfigs map[int]entity.ReviewConfig
+	webhookSecrets    map[int]string
+
+	aiTokenUsage map[aiUsageKey]int64
+
+	notificationPreferences map[int]entity.NotificationPreferences
+
+	auditLogs []entity.AuditLog
+
+	commands map[string]entity.Command
+
+	outbox       []*entity.OutboxMessage
+	nextOutboxID int64
+
+	deadLetters      []*entity.DeadLetter
+	nextDeadLetterID int64
+
+	webhookSubscriptions  map[int64]entity.WebhookSubscription
+	nextWebhookID         int64
+	webhookDeliveries     []*entity.WebhookDelivery
+	nextWebhookDeliveryID int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:             make(map[int]entity.User),
+		repositories:      make(map[int]entity.Repository),
+		reviewRuns:        make(map[int64]entity.ReviewRun),
+		reviewFindings:    make(map[int64][]entity.ReviewFinding),
+		commands:          make(map[string]entity.Command),
+		reviewJobs:        make(map[string]entity.ReviewJob),
+		repoAnalyzers:     make(map[int][]string),
+		aiTokenUsage:      make(map[aiUsageKey]int64),
+		repoReviewConfigs: make(map[int]entity.ReviewConfig),
+		webhookSecrets:    make(map[int]string),
+
+		webhookSubscriptions: make(map[int64]entity.WebhookSubscription),
+
+		notificationPreferences: make(map[int]entity.NotificationPreferences),
+	}
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"sort"
+	"time"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sama seperti users_event_id_key: event yang sama mengembalikan user yang sudah ada
+	if user.EventID != "" {
+		for _, u := range s.users {
+			if u.EventID == user.EventID {
+				user.ID = u.ID
+				return nil
+			}
+		}
+	}
+
+	for _, u := range s.users {
+		if u.Email == user.Email {
+			return repository.ErrDuplicateEmail
+		}
+	}
+
+	s.nextUserID++
+	now := time.Now()
+	user.ID = s.nextUserID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id int) (*entity.User, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *entity.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.users[user.ID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	for _, u := range s.users {
+		if u.ID != user.ID && u.Email == user.Email {
+			return repository.ErrDuplicateEmail
+		}
+	}
+
+	existing.Name = user.Name
+	existing.Email = user.Email
+	existing.UpdatedAt = time.Now()
+	s.users[user.ID] = existing
+	return nil
+}
+
+// DeleteUser ikut menghapus repository, pemakaian token AI dan preferensi notifikasi milik user (ON DELETE CASCADE)
+func (r *userRepository) DeleteUser(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, id)
+	delete(s.notificationPreferences, id)
+	for repoID, repo := range s.repositories {
+		if repo.UserID == id {
+			s.deleteRepositoryLocked(repoID)
+		}
+	}
+	for key := range s.aiTokenUsage {
+		if key.userID == id {
+			delete(s.aiTokenUsage, key)
+		}
+	}
+	return nil
+}
+
+func (r *userRepository) GetAllUsers(ctx context.Context) ([]entity.User, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []entity.User
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"slices"
+	"sort"
+	"time"
+)
+
+type webhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) repository.WebhookRepository {
+	return &webhookRepository{store: store}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextWebhookID++
+	now := time.Now()
+	sub.ID = s.nextWebhookID
+	sub.Active = true
+	sub.ConsecutiveFailures = 0
+	sub.DisabledReason = ""
+	sub.DisabledAt = nil
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+
+	stored := *sub
+	stored.Events = slices.Clone(sub.Events)
+	s.webhookSubscriptions[sub.ID] = stored
+	return nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.webhookSubscriptions[id]
+	if !ok {
+		return nil, repository.ErrWebhookSubscriptionNotFound
+	}
+	sub.Events = slices.Clone(sub.Events)
+	return &sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]entity.WebhookSubscription, 0, len(s.webhookSubscriptions))
+	for _, sub := range s.webhookSubscriptions {
+		sub.Events = slices.Clone(sub.Events)
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.webhookSubscriptions[sub.ID]
+	if !ok {
+		return repository.ErrWebhookSubscriptionNotFound
+	}
+
+	now := time.Now()
+	stored.URL = sub.URL
+	stored.Events = slices.Clone(sub.Events)
+	stored.Description = sub.Description
+	if sub.Secret != "" {
+		stored.Secret = sub.Secret
+	}
+	switch {
+	case sub.Active:
+		if !stored.Active {
+			stored.ConsecutiveFailures = 0
+		}
+		stored.DisabledReason = ""
+		stored.DisabledAt = nil
+	case stored.DisabledAt == nil:
+		stored.DisabledReason = sub.DisabledReason
+		stored.DisabledAt = &now
+	}
+	stored.Active = sub.Active
+	stored.UpdatedAt = now
+	s.webhookSubscriptions[sub.ID] = stored
+
+	*sub = stored
+	sub.Events = slices.Clone(stored.Events)
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhookSubscriptions[id]; !ok {
+		return repository.ErrWebhookSubscriptionNotFound
+	}
+	delete(s.webhookSubscriptions, id)
+	s.webhookDeliveries = slices.DeleteFunc(s.webhookDeliveries, func(d *entity.WebhookDelivery) bool {
+		return d.SubscriptionID == id
+	})
+	return nil
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) (int, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inserted := 0
+	for _, d := range deliveries {
+		if _, ok := s.webhookSubscriptions[d.SubscriptionID]; !ok || s.hasWebhookDeliveryLocked(d.SubscriptionID, d.EventID) {
+			continue
+		}
+		s.nextWebhookDeliveryID++
+		now := time.Now()
+		d.ID = s.nextWebhookDeliveryID
+		d.Status = entity.WebhookDeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		d.CreatedAt = now
+		d.Payload = slices.Clone(d.Payload)
+
+		stored := d
+		s.webhookDeliveries = append(s.webhookDeliveries, &stored)
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (s *Store) hasWebhookDeliveryLocked(subscriptionID int64, eventID string) bool {
+	for _, d := range s.webhookDeliveries {
+		if d.SubscriptionID == subscriptionID && d.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, claim time.Duration) ([]entity.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var deliveries []entity.WebhookDelivery
+	for _, d := range s.webhookDeliveries {
+		if len(deliveries) >= limit {
+			break
+		}
+		if d.Status != entity.WebhookDeliveryPending || d.NextAttemptAt.After(now) || !s.webhookSubscriptions[d.SubscriptionID].Active {
+			continue
+		}
+		d.NextAttemptAt = now.Add(claim)
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, d *entity.WebhookDelivery, retryIn time.Duration) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.webhookDeliveries {
+		if stored.ID != d.ID {
+			continue
+		}
+		now := time.Now()
+		stored.Status = d.Status
+		stored.Attempts = d.Attempts
+		stored.NextAttemptAt = now.Add(retryIn)
+		stored.LastStatusCode = d.LastStatusCode
+		stored.LastError = d.LastError
+		stored.LastResponse = d.LastResponse
+		stored.DurationMs = d.DurationMs
+		stored.DeliveredAt = nil
+		if d.Status == entity.WebhookDeliverySucceeded {
+			stored.DeliveredAt = &now
+		}
+		break
+	}
+	return nil
+}
+
+func (r *webhookRepository) RecordSubscriptionResult(ctx context.Context, id int64, ok bool, disableAfter int, reason string) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, found := s.webhookSubscriptions[id]
+	if !found {
+		return false, nil
+	}
+	if ok {
+		sub.ConsecutiveFailures = 0
+		s.webhookSubscriptions[id] = sub
+		return false, nil
+	}
+
+	sub.ConsecutiveFailures++
+	disabled := sub.Active && sub.ConsecutiveFailures >= disableAfter
+	if disabled {
+		now := time.Now()
+		sub.Active = false
+		sub.DisabledAt = &now
+		sub.DisabledReason = reason
+		sub.UpdatedAt = now
+	}
+	s.webhookSubscriptions[id] = sub
+	return disabled, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := []entity.WebhookDelivery{}
+	for i := len(s.webhookDeliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if d := s.webhookDeliveries[i]; d.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, *d)
+		}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"go-crud/internal/repository"
+)
+
+type webhookSecretRepository struct {
+	store *Store
+}
+
+func NewWebhookSecretRepository(store *Store) repository.WebhookSecretRepository {
+	return &webhookSecretRepository{store: store}
+}
+
+func (r *webhookSecretRepository) SetWebhookSecret(ctx context.Context, repoID int, secret string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhookSecrets[repoID] = secret
+	return nil
+}
+
+func (r *webhookSecretRepository) GetWebhookSecrets(ctx context.Context, repoIDs []int) (map[int]string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets := make(map[int]string, len(repoIDs))
+	for _, id := range repoIDs {
+		if secret, ok := s.webhookSecrets[id]; ok {
+			secrets[id] = secret
+		}
+	}
+	return secrets, nil
+}
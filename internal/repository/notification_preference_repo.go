@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNotificationPreferencesNotFound dikembalikan jika user belum pernah menyimpan preferensi
+var ErrNotificationPreferencesNotFound = errors.New("notification preferences not found")
+
+type NotificationPreferenceRepository interface {
+	GetNotificationPreferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error)
+	// SaveNotificationPreferences mengganti seluruh preferensi user dan mengisi UpdatedAt
+	SaveNotificationPreferences(ctx context.Context, prefs *entity.NotificationPreferences) error
+}
+
+type notificationPreferenceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationPreferenceRepository(db *pgxpool.Pool) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) GetNotificationPreferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "notificationPreferenceRepository.GetNotificationPreferences")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT user_id, channels, min_severity, quiet_hours_start, quiet_hours_end, timezone, updated_at
+              FROM notification_preferences WHERE user_id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.user.id", userID),
+	)
+
+	var p entity.NotificationPreferences
+	err := r.db.QueryRow(ctx, query, userID).Scan(&p.UserID, &p.Channels, &p.MinSeverity,
+		&p.QuietHoursStart, &p.QuietHoursEnd, &p.Timezone, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotificationPreferencesNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *notificationPreferenceRepository) SaveNotificationPreferences(ctx context.Context, prefs *entity.NotificationPreferences) error {
+	ctx, span := tracing.Tracer.Start(ctx, "notificationPreferenceRepository.SaveNotificationPreferences")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO notification_preferences
+                  (user_id, channels, min_severity, quiet_hours_start, quiet_hours_end, timezone, updated_at)
+              VALUES (@user_id, @channels, @min_severity, @quiet_start, @quiet_end, @timezone, NOW())
+              ON CONFLICT (user_id) DO UPDATE SET
+                  channels = EXCLUDED.channels,
+                  min_severity = EXCLUDED.min_severity,
+                  quiet_hours_start = EXCLUDED.quiet_hours_start,
+                  quiet_hours_end = EXCLUDED.quiet_hours_end,
+                  timezone = EXCLUDED.timezone,
+                  updated_at = NOW()
+              RETURNING updated_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.user.id", prefs.UserID),
+	)
+
+	channels := prefs.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{
+		"user_id":      prefs.UserID,
+		"channels":     channels,
+		"min_severity": prefs.MinSeverity,
+		"quiet_start":  prefs.QuietHoursStart,
+		"quiet_end":    prefs.QuietHoursEnd,
+		"timezone":     prefs.Timezone,
+	}).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// outboxClaimTimeout adalah lama pesan "dipinjam" relay sebelum bisa diambil instance lain
+const outboxClaimTimeout = 30 * time.Second
+
+type OutboxRepository interface {
+	ClaimPending(ctx context.Context, limit int) ([]entity.OutboxMessage, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, errMsg string, backoff time.Duration) error
+	GetStats(ctx context.Context) (*entity.OutboxStats, error)
+}
+
+type outboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// insertOutboxMessage dipanggil di dalam transaksi pemanggil (command baru, replay DLQ).
+// Perubahan data user/repository tidak ikut transaksi ini, dilakukan consumer setelah event diterima.
+func insertOutboxMessage(ctx context.Context, tx pgx.Tx, msg *entity.OutboxMessage) error {
+	query := `INSERT INTO outbox (topic, event_type, payload, status, attempts, next_attempt_at, created_at)
+              VALUES ($1, $2, $3, $4, 0, NOW(), NOW()) RETURNING id, created_at`
+
+	msg.Status = entity.OutboxStatusPending
+	return tx.QueryRow(ctx, query, msg.Topic, msg.EventType, msg.Payload, msg.Status).Scan(&msg.ID, &msg.CreatedAt)
+}
+
+// ClaimPending mengambil pesan yang siap dikirim dan menggeser next_attempt_at
+// supaya relay di instance lain tidak mengirim pesan yang sama bersamaan.
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int) ([]entity.OutboxMessage, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "outboxRepository.ClaimPending")
+	defer span.End()
+
+	query := `UPDATE outbox SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+              WHERE id IN (
+                  SELECT id FROM outbox
+                  WHERE status = 'pending' AND next_attempt_at <= NOW()
+                  ORDER BY id
+                  LIMIT $1
+                  FOR UPDATE SKIP LOCKED
+              )
+              RETURNING id, topic, event_type, payload, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.limit", limit),
+	)
+
+	rows, err := r.db.Query(ctx, query, limit, int(outboxClaimTimeout.Seconds()))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []entity.OutboxMessage
+	for rows.Next() {
+		var msg entity.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.Topic, &msg.EventType, &msg.Payload, &msg.Status, &msg.Attempts, &msg.LastError, &msg.NextAttemptAt, &msg.CreatedAt); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("db.result.count", len(messages)))
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	ctx, span := tracing.Tracer.Start(ctx, "outboxRepository.MarkDelivered")
+	defer span.End()
+
+	query := "UPDATE outbox SET status = 'delivered', delivered_at = NOW(), last_error = NULL WHERE id = $1"
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.outbox.id", id),
+	)
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, backoff time.Duration) error {
+	ctx, span := tracing.Tracer.Start(ctx, "outboxRepository.MarkFailed")
+	defer span.End()
+
+	query := "UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = NOW() + $2 * INTERVAL '1 second' WHERE id = $3"
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.outbox.id", id),
+	)
+
+	_, err := r.db.Exec(ctx, query, errMsg, backoff.Seconds(), id)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+func (r *outboxRepository) GetStats(ctx context.Context) (*entity.OutboxStats, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "outboxRepository.GetStats")
+	defer span.End()
+
+	query := `SELECT COUNT(*), COUNT(*) FILTER (WHERE attempts > 0), MIN(created_at),
+                     COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at)), 0)::float8
+              FROM outbox WHERE status = 'pending'`
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+	)
+
+	var stats entity.OutboxStats
+	if err := r.db.QueryRow(ctx, query).Scan(&stats.Pending, &stats.Retrying, &stats.OldestPendingAt, &stats.LagSeconds); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"go-crud/internal/tracing"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProcessedEventRepository menyimpan ID event yang sudah diproses consumer, dipakai untuk
+// membuang pesan duplikat saat Kafka melakukan redelivery tanpa menyentuh database. Cek dan
+// tandai tidak atomik dengan penulisan data, jadi ini optimasi; idempotensi sebenarnya ada di
+// unique key event_id pada users dan repositories.
+type ProcessedEventRepository interface {
+	IsProcessed(ctx context.Context, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, eventID string) error
+}
+
+type processedEventRepository struct {
+	cache CacheRepository
+	ttl   time.Duration
+}
+
+func NewProcessedEventRepository(cache CacheRepository, ttl time.Duration) ProcessedEventRepository {
+	return &processedEventRepository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func processedEventKey(eventID string) string {
+	return "processed_event:" + eventID
+}
+
+func (r *processedEventRepository) IsProcessed(ctx context.Context, eventID string) (bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "processedEventRepository.IsProcessed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.operation", "EXISTS"),
+		attribute.String("event.id", eventID),
+	)
+
+	exists, err := r.cache.Exists(ctx, processedEventKey(eventID))
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *processedEventRepository) MarkProcessed(ctx context.Context, eventID string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "processedEventRepository.MarkProcessed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.operation", "SET"),
+		attribute.String("event.id", eventID),
+	)
+
+	err := r.cache.Set(ctx, processedEventKey(eventID), strconv.FormatInt(time.Now().Unix(), 10), r.ttl)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
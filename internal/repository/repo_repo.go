@@ -7,14 +7,20 @@ import (
 	"go-crud/internal/entity"
 	"go-crud/internal/tracing"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrRepositoryNotFound dikembalikan GetRepositoryByID, GetByID dan Update jika repository tidak ada
+var ErrRepositoryNotFound = errors.New("repository not found")
+
 type RepositoryRepository interface {
 	CreateRepository(ctx context.Context, repo *entity.Repository) error
 	GetRepositoryByID(ctx context.Context, id int) (*entity.Repository, error)
 	GetAllRepositories(ctx context.Context) ([]entity.Repository, error)
+	// GetRepositoriesByURLKeys mencari repository dengan entity.NormalizeGitURL(url) di keys
+	GetRepositoriesByURLKeys(ctx context.Context, keys []string) ([]entity.Repository, error)
 
 	GetByID(ctx context.Context, id int) (*entity.Repository, error)
 	GetRepositoriesByUserID(ctx context.Context, userID int) ([]entity.Repository, error) 
@@ -34,8 +40,17 @@ func (r *repoRepository) CreateRepository(ctx context.Context, repo *entity.Repo
 	ctx, span := tracing.Tracer.Start(ctx, "repoRepository.CreateRepository")
 	defer span.End()
 
-	query := `INSERT INTO repositories (user_id, name, url, ai_enabled, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4, NOW(), NOW()) RETURNING id`
+	// Event repository.created yang sama (redelivery, replay DLQ) mengembalikan baris yang sudah ada
+	query := `WITH ins AS (
+                  INSERT INTO repositories (user_id, name, url, url_key, ai_enabled, event_id, created_at, updated_at)
+                  VALUES ($1, $2, $3, $6, $4, NULLIF($5, ''), NOW(), NOW())
+                  ON CONFLICT (event_id) DO NOTHING
+                  RETURNING id
+              )
+              SELECT id FROM ins
+              UNION ALL
+              SELECT id FROM repositories WHERE event_id = NULLIF($5, '')
+              LIMIT 1`
 
 	span.SetAttributes(
 		attribute.String("db.system", "postgresql"),
@@ -45,7 +60,7 @@ func (r *repoRepository) CreateRepository(ctx context.Context, repo *entity.Repo
 		attribute.String("db.repo_name", repo.Name),
 	)
 
-	err := r.db.QueryRow(ctx, query, repo.UserID, repo.Name, repo.URL, repo.AIEnabled).Scan(&repo.ID)
+	err := r.db.QueryRow(ctx, query, repo.UserID, repo.Name, repo.URL, repo.AIEnabled, repo.EventID, entity.NormalizeGitURL(repo.URL)).Scan(&repo.ID)
 	if err != nil {
 		span.RecordError(err)
 		return err
@@ -78,7 +93,8 @@ func (r *repoRepository) GetRepositoriesByUserID(ctx context.Context, userID int
 	}
 	defer rows.Close()
 
-	var repositories []entity.Repository
+	// User tanpa repository bukan error: hasilnya slice kosong
+	repositories := []entity.Repository{}
 	for rows.Next() {
 		var repo entity.Repository
 		err := rows.Scan(&repo.ID, &repo.UserID, &repo.Name, &repo.URL, &repo.AIEnabled, &repo.CreatedAt, &repo.UpdatedAt)
@@ -89,11 +105,6 @@ func (r *repoRepository) GetRepositoriesByUserID(ctx context.Context, userID int
 		repositories = append(repositories, repo)
 	}
 
-	if len(repositories) == 0 {
-		err := errors.New("no repositories found for this user")
-		span.RecordError(err)
-		return nil, err
-	}
 	span.SetAttributes(attribute.Int("db.response_count", len(repositories)))
 
 	return repositories, nil
@@ -117,11 +128,10 @@ func (r *repoRepository) GetRepositoryByID(ctx context.Context, id int) (*entity
 	var repo entity.Repository
 	err := row.Scan(&repo.ID, &repo.UserID, &repo.Name, &repo.URL, &repo.AIEnabled, &repo.CreatedAt, &repo.UpdatedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			span.RecordError(err)
-			return nil, errors.New("repository not found")
-		}
 		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRepositoryNotFound
+		}
 		return nil, err
 	}
 	//hasil query
@@ -171,6 +181,47 @@ func (r *repoRepository) GetAllRepositories(ctx context.Context) ([]entity.Repos
 	return repositories, nil
 }
 
+func (r *repoRepository) GetRepositoriesByURLKeys(ctx context.Context, keys []string) ([]entity.Repository, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repoRepository.GetRepositoriesByURLKeys")
+	defer span.End()
+
+	query := "SELECT id, user_id, name, url, ai_enabled, created_at, updated_at FROM repositories WHERE url_key = ANY($1) ORDER BY id"
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.StringSlice("db.url_keys", keys),
+	)
+
+	rows, err := r.db.Query(ctx, query, keys)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repositories []entity.Repository
+	for rows.Next() {
+		var repo entity.Repository
+		err := rows.Scan(&repo.ID, &repo.UserID, &repo.Name, &repo.URL, &repo.AIEnabled, &repo.CreatedAt, &repo.UpdatedAt)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		repositories = append(repositories, repo)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("db.result.count", len(repositories)),
+	)
+
+	return repositories, nil
+}
+
 
 func (r *repoRepository) GetByID(ctx context.Context, id int) (*entity.Repository, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "repoRepository.GetByID")
@@ -190,9 +241,9 @@ func (r *repoRepository) GetByID(ctx context.Context, id int) (*entity.Repositor
 	err := row.Scan(&repo.ID, &repo.UserID, &repo.Name, &repo.URL, &repo.AIEnabled, &repo.CreatedAt, &repo.UpdatedAt)
 	if err != nil {
 		span.RecordError(err)
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
 			span.SetAttributes(attribute.String("db.result", "not found"))
-			return nil, errors.New("repository not found")
+			return nil, ErrRepositoryNotFound
 		}
 		return nil, err
 	}
@@ -216,11 +267,14 @@ func (r *repoRepository) Update(ctx context.Context, repo *entity.Repository) er
 	err := r.db.QueryRow(ctx, querySelect, repo.ID).Scan(&oldRepo.Name, &oldRepo.URL, &oldRepo.AIEnabled)
 	if err != nil {
 		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRepositoryNotFound
+		}
 		return err
 	}
 
 	// Tracing query update
-	queryUpdate := "UPDATE repositories SET name = $1, url = $2, ai_enabled = $3, updated_at = NOW() WHERE id = $4"
+	queryUpdate := "UPDATE repositories SET name = $1, url = $2, url_key = $5, ai_enabled = $3, updated_at = NOW() WHERE id = $4"
 	span.SetAttributes(
 		attribute.String("db.system", "postgresql"),
 		attribute.String("db.operation", "UPDATE"),
@@ -234,7 +288,7 @@ func (r *repoRepository) Update(ctx context.Context, repo *entity.Repository) er
 		attribute.Bool("db.new.ai_enabled", repo.AIEnabled),
 	)
 
-	_, err = r.db.Exec(ctx, queryUpdate, repo.Name, repo.URL, repo.AIEnabled, repo.ID)
+	_, err = r.db.Exec(ctx, queryUpdate, repo.Name, repo.URL, repo.AIEnabled, repo.ID, entity.NormalizeGitURL(repo.URL))
 	if err != nil {
 		span.RecordError(err)
 	}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"regexp"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// Event per job yang disimpan, event lebih lama dibuang (Last-Event-ID yang terlalu lama
+	// dilanjutkan dari event tertua yang masih ada)
+	reviewEventMaxLen = 2000
+	// Stream dihapus setelah tidak ada event baru selama ini
+	reviewEventTTL = 24 * time.Hour
+	// Maksimum event per pembacaan
+	reviewEventReadCount = 500
+)
+
+// ReviewEventRepository menyimpan event progres job code review untuk stream SSE.
+// Implementasinya Redis stream atau ring buffer in-memory.
+type ReviewEventRepository interface {
+	// AppendReviewEvent menambah event ke stream job dan mengisi ev.ID
+	AppendReviewEvent(ctx context.Context, ev *entity.ReviewEvent) error
+	// ReadReviewEvents mengembalikan event setelah afterID (kosong berarti dari awal).
+	// Jika belum ada event baru, menunggu paling lama wait lalu mengembalikan slice kosong.
+	ReadReviewEvents(ctx context.Context, jobID, afterID string, wait time.Duration) ([]entity.ReviewEvent, error)
+}
+
+type redisReviewEventRepository struct {
+	redis *redis.Client
+}
+
+func NewRedisReviewEventRepository(redisClient *redis.Client) ReviewEventRepository {
+	return &redisReviewEventRepository{redis: redisClient}
+}
+
+func reviewEventKey(jobID string) string {
+	return "review_events:" + jobID
+}
+
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+func (r *redisReviewEventRepository) AppendReviewEvent(ctx context.Context, ev *entity.ReviewEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "redisReviewEventRepository.AppendReviewEvent")
+	defer span.End()
+
+	key := reviewEventKey(ev.JobID)
+	span.SetAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", "XADD"),
+		attribute.String("db.redis.key", key),
+		attribute.String("review.event.type", ev.Type),
+	)
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redis.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: reviewEventMaxLen,
+		Approx: true,
+		Values: map[string]any{"data": payload},
+	})
+	pipe.Expire(ctx, key, reviewEventTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	ev.ID = add.Val()
+	return nil
+}
+
+func (r *redisReviewEventRepository) ReadReviewEvents(ctx context.Context, jobID, afterID string, wait time.Duration) ([]entity.ReviewEvent, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "redisReviewEventRepository.ReadReviewEvents")
+	defer span.End()
+
+	key := reviewEventKey(jobID)
+	span.SetAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", "XREAD"),
+		attribute.String("db.redis.key", key),
+	)
+
+	// Last-Event-ID dari client tidak dipercaya begitu saja, ID yang tidak valid dibaca dari awal
+	if !streamIDPattern.MatchString(afterID) {
+		afterID = "0-0"
+	}
+
+	block := wait
+	if block <= 0 {
+		block = -1 // tanpa BLOCK
+	}
+	streams, err := r.redis.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{key, afterID},
+		Count:   reviewEventReadCount,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return []entity.ReviewEvent{}, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	events := []entity.ReviewEvent{}
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			data, _ := msg.Values["data"].(string)
+			var ev entity.ReviewEvent
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				span.RecordError(err)
+				continue
+			}
+			ev.ID = msg.ID
+			events = append(events, ev)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrReviewJobNotFound dikembalikan jika job ID tidak ada
+	ErrReviewJobNotFound = errors.New("review job not found")
+	// ErrReviewJobFinished dikembalikan saat membatalkan job yang sudah selesai
+	ErrReviewJobFinished = errors.New("review job already finished")
+	// ErrReviewJobNotRetryable dikembalikan saat retry job yang tidak failed/cancelled
+	ErrReviewJobNotRetryable = errors.New("only failed or cancelled review jobs can be retried")
+	// ErrReviewJobLeaseLost dikembalikan jika job sudah tidak dipegang worker ini
+	// (lease habis dan diambil alih, atau job sudah selesai)
+	ErrReviewJobLeaseLost = errors.New("review job lease lost")
+)
+
+type ReviewJobRepository interface {
+	EnqueueJob(ctx context.Context, job *entity.ReviewJob) error
+	// ClaimNextJob mengambil job queued tertua, menandainya running dengan lease milik workerID,
+	// nil jika antrean kosong
+	ClaimNextJob(ctx context.Context, workerID string, lease time.Duration) (*entity.ReviewJob, error)
+	// Heartbeat memperpanjang lease dan mengembalikan cancel_requested
+	Heartbeat(ctx context.Context, id string, workerID string, lease time.Duration) (bool, error)
+	SaveCheckpoint(ctx context.Context, id string, workerID string, checkpoint *entity.ReviewCheckpoint) error
+	FinishJob(ctx context.Context, id string, workerID string, status string, runID *int64, errMsg string) error
+	// ReleaseJob mengembalikan job running ke antrean tanpa menunggu lease habis, checkpoint tetap disimpan
+	ReleaseJob(ctx context.Context, id string, workerID string, reason string) error
+	// RequeueExpiredJobs mengembalikan job running yang lease-nya habis ke antrean
+	RequeueExpiredJobs(ctx context.Context) ([]entity.ReviewJob, error)
+	// CancelJob langsung membatalkan job queued, job running hanya ditandai cancel_requested
+	CancelJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error)
+	// RetryJob mengembalikan job failed/cancelled ke antrean dengan parameter yang sama
+	RetryJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error)
+	GetJobByID(ctx context.Context, id string) (*entity.ReviewJob, error)
+}
+
+type reviewJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewJobRepository(db *pgxpool.Pool) ReviewJobRepository {
+	return &reviewJobRepository{db: db}
+}
+
+const reviewJobColumns = `id, repository_id, COALESCE(base_ref, ''), COALESCE(head_ref, ''), status, run_id, attempts, COALESCE(error, ''),
+              cancel_requested, COALESCE(worker_id, ''), lease_expires_at, checkpoint, transitions,
+              created_at, updated_at, started_at, finished_at`
+
+const leaseExpiry = `NOW() + make_interval(secs => @lease_secs::float8)`
+
+// appendTransition menambah {from, to, reason, at} ke kolom transitions.
+// Di dalam SET, "status" masih bernilai status lama.
+const appendTransition = `transitions = transitions || jsonb_build_array(jsonb_strip_nulls(jsonb_build_object(
+                  'from', status, 'to', @to::text, 'reason', NULLIF(@reason::text, ''), 'at', @at::timestamptz)))`
+
+func (r *reviewJobRepository) EnqueueJob(ctx context.Context, job *entity.ReviewJob) error {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.EnqueueJob")
+	defer span.End()
+
+	query := `INSERT INTO review_jobs (id, repository_id, base_ref, head_ref, status, created_at, updated_at)
+              VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NOW(), NOW()) RETURNING created_at, updated_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", job.ID),
+		attribute.Int("db.repository.id", job.RepositoryID),
+	)
+
+	if err := r.db.QueryRow(ctx, query, job.ID, job.RepositoryID, job.BaseRef, job.HeadRef, job.Status).Scan(&job.CreatedAt, &job.UpdatedAt); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	job.Transitions = []entity.ReviewJobTransition{}
+	return nil
+}
+
+func (r *reviewJobRepository) ClaimNextJob(ctx context.Context, workerID string, lease time.Duration) (*entity.ReviewJob, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.ClaimNextJob")
+	defer span.End()
+
+	query := `UPDATE review_jobs
+              SET status = @to, attempts = attempts + 1, error = NULL,
+                  worker_id = @worker_id, lease_expires_at = ` + leaseExpiry + `,
+                  started_at = NOW(), finished_at = NULL, updated_at = NOW(),
+                  ` + appendTransition + `
+              WHERE id = (
+                  SELECT id FROM review_jobs
+                  WHERE status = 'queued'
+                  ORDER BY created_at
+                  LIMIT 1
+                  FOR UPDATE SKIP LOCKED
+              )
+              RETURNING ` + reviewJobColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+	)
+
+	args := transitionArgs(entity.ReviewJobRunning, "")
+	args["worker_id"] = workerID
+	args["lease_secs"] = lease.Seconds()
+	job, err := scanReviewJob(r.db.QueryRow(ctx, query, args))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("db.review_job.id", job.ID))
+	return job, nil
+}
+
+func (r *reviewJobRepository) FinishJob(ctx context.Context, id string, workerID string, status string, runID *int64, errMsg string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.FinishJob")
+	defer span.End()
+
+	query := `UPDATE review_jobs
+              SET status = @to, run_id = COALESCE(@run_id, run_id), error = NULLIF(@reason, ''),
+                  cancel_requested = false, worker_id = NULL, lease_expires_at = NULL, checkpoint = NULL,
+                  finished_at = NOW(), updated_at = NOW(),
+                  ` + appendTransition + `
+              WHERE id = @id AND status = 'running' AND worker_id = @worker_id`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+		attribute.String("db.review_job.status", status),
+	)
+
+	args := transitionArgs(status, errMsg)
+	args["id"] = id
+	args["worker_id"] = workerID
+	args["run_id"] = runID
+	tag, err := r.db.Exec(ctx, query, args)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewJobLeaseLost
+	}
+	return nil
+}
+
+func (r *reviewJobRepository) Heartbeat(ctx context.Context, id string, workerID string, lease time.Duration) (bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.Heartbeat")
+	defer span.End()
+
+	query := `UPDATE review_jobs SET lease_expires_at = ` + leaseExpiry + `
+              WHERE id = @id AND status = 'running' AND worker_id = @worker_id
+              RETURNING cancel_requested`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+	)
+
+	var cancelRequested bool
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{"id": id, "worker_id": workerID, "lease_secs": lease.Seconds()}).Scan(&cancelRequested)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrReviewJobLeaseLost
+		}
+		span.RecordError(err)
+		return false, err
+	}
+	return cancelRequested, nil
+}
+
+func (r *reviewJobRepository) SaveCheckpoint(ctx context.Context, id string, workerID string, checkpoint *entity.ReviewCheckpoint) error {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.SaveCheckpoint")
+	defer span.End()
+
+	query := `UPDATE review_jobs SET checkpoint = @checkpoint, run_id = @run_id, updated_at = NOW()
+              WHERE id = @id AND status = 'running' AND worker_id = @worker_id`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+		attribute.Int("db.review_job.checkpoint_files", len(checkpoint.FilesDone)),
+	)
+
+	payload, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": id, "worker_id": workerID, "checkpoint": payload, "run_id": checkpoint.RunID})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewJobLeaseLost
+	}
+	return nil
+}
+
+func (r *reviewJobRepository) ReleaseJob(ctx context.Context, id string, workerID string, reason string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.ReleaseJob")
+	defer span.End()
+
+	query := `UPDATE review_jobs
+              SET status = @to, worker_id = NULL, lease_expires_at = NULL, started_at = NULL, updated_at = NOW(),
+                  ` + appendTransition + `
+              WHERE id = @id AND status = 'running' AND worker_id = @worker_id`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+	)
+
+	args := transitionArgs(entity.ReviewJobQueued, reason)
+	args["id"] = id
+	args["worker_id"] = workerID
+	tag, err := r.db.Exec(ctx, query, args)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewJobLeaseLost
+	}
+	return nil
+}
+
+func (r *reviewJobRepository) RequeueExpiredJobs(ctx context.Context) ([]entity.ReviewJob, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.RequeueExpiredJobs")
+	defer span.End()
+
+	// Reason memakai worker_id lama, jadi ikut dihitung sebelum worker_id di-reset
+	query := `UPDATE review_jobs
+              SET status = @to, worker_id = NULL, lease_expires_at = NULL, started_at = NULL, updated_at = NOW(),
+                  transitions = transitions || jsonb_build_array(jsonb_build_object(
+                      'from', status, 'to', @to::text,
+                      'reason', 'lease expired on worker ' || COALESCE(worker_id, 'unknown'), 'at', @at::timestamptz))
+              WHERE status = 'running' AND lease_expires_at < NOW()
+              RETURNING ` + reviewJobColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+	)
+
+	rows, err := r.db.Query(ctx, query, transitionArgs(entity.ReviewJobQueued, ""))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []entity.ReviewJob
+	for rows.Next() {
+		job, err := scanReviewJob(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *reviewJobRepository) CancelJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.CancelJob")
+	defer span.End()
+
+	cancelQueued := `UPDATE review_jobs
+              SET status = @to, finished_at = NOW(), updated_at = NOW(),
+                  ` + appendTransition + `
+              WHERE id = @id AND status = 'queued'
+              RETURNING ` + reviewJobColumns
+	// Job running dihentikan oleh worker yang memegangnya, transisinya dicatat saat FinishJob
+	requestCancel := `UPDATE review_jobs SET cancel_requested = true, updated_at = NOW()
+              WHERE id = @id AND status = 'running'
+              RETURNING ` + reviewJobColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", cancelQueued),
+		attribute.String("db.review_job.id", id),
+	)
+
+	args := transitionArgs(entity.ReviewJobCancelled, reason)
+	args["id"] = id
+	job, err := scanReviewJob(r.db.QueryRow(ctx, cancelQueued, args))
+	if errors.Is(err, pgx.ErrNoRows) {
+		job, err = scanReviewJob(r.db.QueryRow(ctx, requestCancel, pgx.NamedArgs{"id": id}))
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.stateError(ctx, id, ErrReviewJobFinished)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *reviewJobRepository) RetryJob(ctx context.Context, id string, reason string) (*entity.ReviewJob, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.RetryJob")
+	defer span.End()
+
+	query := `UPDATE review_jobs
+              SET status = @to, error = NULL, cancel_requested = false, checkpoint = NULL,
+                  started_at = NULL, finished_at = NULL, updated_at = NOW(),
+                  ` + appendTransition + `
+              WHERE id = @id AND status IN ('failed', 'cancelled')
+              RETURNING ` + reviewJobColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+	)
+
+	args := transitionArgs(entity.ReviewJobQueued, reason)
+	args["id"] = id
+	job, err := scanReviewJob(r.db.QueryRow(ctx, query, args))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.stateError(ctx, id, ErrReviewJobNotRetryable)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// stateError membedakan job yang tidak ada dari job yang statusnya tidak cocok
+func (r *reviewJobRepository) stateError(ctx context.Context, id string, conflict error) error {
+	if _, err := r.GetJobByID(ctx, id); err != nil {
+		return err
+	}
+	return conflict
+}
+
+func (r *reviewJobRepository) GetJobByID(ctx context.Context, id string) (*entity.ReviewJob, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "reviewJobRepository.GetJobByID")
+	defer span.End()
+
+	query := `SELECT ` + reviewJobColumns + ` FROM review_jobs WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.String("db.review_job.id", id),
+	)
+
+	job, err := scanReviewJob(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func transitionArgs(to, reason string) pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"to":     to,
+		"reason": reason,
+		"at":     time.Now(),
+	}
+}
+
+func scanReviewJob(row pgx.Row) (*entity.ReviewJob, error) {
+	var job entity.ReviewJob
+	var checkpoint, transitions []byte
+	err := row.Scan(&job.ID, &job.RepositoryID, &job.BaseRef, &job.HeadRef, &job.Status, &job.RunID, &job.Attempts, &job.Error,
+		&job.CancelRequested, &job.WorkerID, &job.LeaseExpiresAt, &checkpoint, &transitions,
+		&job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(transitions, &job.Transitions); err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		if err := json.Unmarshal(checkpoint, &job.Checkpoint); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
@@ -4,15 +4,29 @@ import (
 	"context"
 	"errors"
 	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrReviewRunNotFound dikembalikan jika run ID tidak ada
+	ErrReviewRunNotFound = errors.New("review run not found")
+	// ErrNoReviewRuns dikembalikan jika repository belum pernah di-review
+	ErrNoReviewRuns = errors.New("🚫 no review runs found for the given repository")
 )
 
 type CodeReviewRepository interface {
-	InsertCodeReviewLog(ctx context.Context, log *entity.CodeReviewLog) error
-	GetCodeReviewLogsByRepoID(ctx context.Context, repoID int) ([]entity.CodeReviewLog, error)
+	CreateReviewRun(ctx context.Context, run *entity.ReviewRun) error
+	FinishReviewRun(ctx context.Context, run *entity.ReviewRun, findings []entity.ReviewFinding) error
+	// ResumeReviewRun membuka kembali run yang terputus, temuan parsial yang sempat tersimpan dihapus
+	ResumeReviewRun(ctx context.Context, id int64) (*entity.ReviewRun, error)
+	GetReviewRunsByRepoID(ctx context.Context, repoID int) ([]entity.ReviewRun, error)
+	GetReviewRunByID(ctx context.Context, id int64) (*entity.ReviewRun, error)
+	GetReviewFindingsByRunID(ctx context.Context, runID int64) ([]entity.ReviewFinding, error)
 }
 
 type codeReviewRepository struct {
@@ -23,58 +37,280 @@ func NewCodeReviewRepository(db *pgxpool.Pool) CodeReviewRepository {
 	return &codeReviewRepository{db: db}
 }
 
-// Insert log review
-func (r *codeReviewRepository) InsertCodeReviewLog(ctx context.Context, log *entity.CodeReviewLog) error {
+const reviewRunColumns = `id, repository_id, status, COALESCE(commit_sha, ''), COALESCE(base_sha, ''), COALESCE(error, ''),
+              error_count, warning_count, info_count, duration_ms, resume_count, resumed_at,
+              started_at, finished_at, created_at`
+
+// CreateReviewRun mencatat run baru dengan status running
+func (r *codeReviewRepository) CreateReviewRun(ctx context.Context, run *entity.ReviewRun) error {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.CreateReviewRun")
+	defer span.End()
+
 	// Gunakan context dengan timeout agar tidak menggantung
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO codereview_log (repository_id, review_result, created_at) 
-              VALUES ($1, $2, NOW()) RETURNING id`
+	query := `INSERT INTO review_runs (repository_id, status, started_at, created_at)
+              VALUES ($1, $2, $3, NOW()) RETURNING id, created_at`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", run.RepositoryID),
+	)
+
+	if err := r.db.QueryRow(ctx, query, run.RepositoryID, run.Status, run.StartedAt).Scan(&run.ID, &run.CreatedAt); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// FinishReviewRun menyimpan temuan dan ringkasan run dalam satu transaksi
+func (r *codeReviewRepository) FinishReviewRun(ctx context.Context, run *entity.ReviewRun, findings []entity.ReviewFinding) error {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.FinishReviewRun")
+	defer span.End()
 
-	err := r.db.QueryRow(ctx, query, log.RepositoryID, log.ReviewResult).Scan(&log.ID)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `UPDATE review_runs
+              SET status = $1, commit_sha = NULLIF($2, ''), base_sha = NULLIF($3, ''), error = NULLIF($4, ''),
+                  error_count = $5, warning_count = $6, info_count = $7,
+                  duration_ms = $8, finished_at = $9
+              WHERE id = $10`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.review_run.id", run.ID),
+		attribute.String("db.review_run.status", run.Status),
+		attribute.Int("db.review_run.findings", len(findings)),
+	)
+
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		span.RecordError(err)
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, run.Status, run.CommitSHA, run.BaseSHA, run.Error,
+		run.Summary.Errors, run.Summary.Warnings, run.Summary.Infos,
+		run.DurationMs, run.FinishedAt, run.ID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewRunNotFound
+	}
+
+	if len(findings) > 0 {
+		rows := make([][]any, 0, len(findings))
+		for _, f := range findings {
+			rows = append(rows, []any{run.ID, f.RuleID, f.Severity, f.File, f.StartLine, f.StartColumn,
+				f.EndLine, f.EndColumn, f.Message, nullIfEmpty(f.SuggestedFix), f.Fingerprint})
+		}
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"review_findings"},
+			[]string{"run_id", "rule_id", "severity", "file_path", "start_line", "start_column",
+				"end_line", "end_column", "message", "suggested_fix", "fingerprint"},
+			pgx.CopyFromRows(rows))
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
 
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return err
+	}
 	return nil
 }
 
-// Get logs by repository ID
-func (r *codeReviewRepository) GetCodeReviewLogsByRepoID(ctx context.Context, repoID int) ([]entity.CodeReviewLog, error) {
+func (r *codeReviewRepository) ResumeReviewRun(ctx context.Context, id int64) (*entity.ReviewRun, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.ResumeReviewRun")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `UPDATE review_runs
+              SET status = $1, error = NULL, error_count = 0, warning_count = 0, info_count = 0,
+                  finished_at = NULL, resume_count = resume_count + 1, resumed_at = NOW()
+              WHERE id = $2
+              RETURNING ` + reviewRunColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.review_run.id", id),
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Temuan dari checkpoint ditulis ulang saat FinishReviewRun
+	if _, err := tx.Exec(ctx, `DELETE FROM review_findings WHERE run_id = $1`, id); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	run, err := scanReviewRun(tx.QueryRow(ctx, query, entity.ReviewRunRunning, id))
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewRunNotFound
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetReviewRunsByRepoID mengembalikan run terbaru lebih dulu
+func (r *codeReviewRepository) GetReviewRunsByRepoID(ctx context.Context, repoID int) ([]entity.ReviewRun, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.GetReviewRunsByRepoID")
+	defer span.End()
+
 	// Gunakan context dengan timeout agar tidak menggantung
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query := "SELECT id, repository_id, review_result, created_at FROM codereview_log WHERE repository_id = $1"
+	query := `SELECT ` + reviewRunColumns + ` FROM review_runs WHERE repository_id = $1 ORDER BY id DESC`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", repoID),
+	)
+
 	rows, err := r.db.Query(ctx, query, repoID)
 	if err != nil {
-		// Jika tidak ada data, kembalikan error yang lebih deskriptif
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("🚫 no review logs found for the given repository")
-		}
+		span.RecordError(err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var logs []entity.CodeReviewLog
+	var runs []entity.ReviewRun
 	for rows.Next() {
-		var log entity.CodeReviewLog
-		if err := rows.Scan(&log.ID, &log.RepositoryID, &log.ReviewResult, &log.CreatedAt); err != nil {
+		run, err := scanReviewRun(rows)
+		if err != nil {
+			span.RecordError(err)
 			return nil, err
 		}
-		logs = append(logs, log)
+		runs = append(runs, *run)
 	}
 
 	// Periksa jika terjadi error selama iterasi rows
 	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(runs) == 0 {
+		return nil, ErrNoReviewRuns
+	}
+	return runs, nil
+}
+
+func (r *codeReviewRepository) GetReviewRunByID(ctx context.Context, id int64) (*entity.ReviewRun, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.GetReviewRunByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + reviewRunColumns + ` FROM review_runs WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.review_run.id", id),
+	)
+
+	run, err := scanReviewRun(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewRunNotFound
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *codeReviewRepository) GetReviewFindingsByRunID(ctx context.Context, runID int64) ([]entity.ReviewFinding, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "codeReviewRepository.GetReviewFindingsByRunID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `SELECT id, run_id, rule_id, severity, file_path, start_line, start_column, end_line, end_column,
+                     message, COALESCE(suggested_fix, ''), fingerprint
+              FROM review_findings WHERE run_id = $1 ORDER BY id`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.review_run.id", runID),
+	)
+
+	rows, err := r.db.Query(ctx, query, runID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := []entity.ReviewFinding{}
+	for rows.Next() {
+		var f entity.ReviewFinding
+		if err := rows.Scan(&f.ID, &f.RunID, &f.RuleID, &f.Severity, &f.File, &f.StartLine, &f.StartColumn,
+			&f.EndLine, &f.EndColumn, &f.Message, &f.SuggestedFix, &f.Fingerprint); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
+	return findings, nil
+}
 
-	// Jika logs kosong, kembalikan error yang sesuai
-	if len(logs) == 0 {
-		return nil, errors.New("🚫 no review logs found for the given repository")
+func scanReviewRun(row pgx.Row) (*entity.ReviewRun, error) {
+	var run entity.ReviewRun
+	err := row.Scan(&run.ID, &run.RepositoryID, &run.Status, &run.CommitSHA, &run.BaseSHA, &run.Error,
+		&run.Summary.Errors, &run.Summary.Warnings, &run.Summary.Infos,
+		&run.DurationMs, &run.ResumeCount, &run.ResumedAt, &run.StartedAt, &run.FinishedAt, &run.CreatedAt)
+	if err != nil {
+		return nil, err
 	}
+	run.Summary.Total = run.Summary.Errors + run.Summary.Warnings + run.Summary.Infos
+	return &run, nil
+}
 
-	return logs, nil
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
 }
@@ -7,10 +7,24 @@ import (
 	"go-crud/internal/entity"
 	"go-crud/internal/tracing"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrUserNotFound dikembalikan GetUserByID jika user tidak ada
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrDuplicateEmail dikembalikan CreateUser/UpdateUser jika email sudah dipakai user lain (users_email_key)
+var ErrDuplicateEmail = errors.New("duplicate key value violates unique constraint \"users_email_key\"")
+
+// isUniqueViolation mengecek error Postgres unique_violation (23505) pada constraint tertentu
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
 // UserRepository interface
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *entity.User) error
@@ -30,12 +44,24 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 	return &userRepository{db: db}
 }
 
-// CreateUser untuk menyimpan user ke database
+// CreateUser untuk menyimpan user ke database. User dengan EventID yang sudah tersimpan tidak
+// di-insert ulang, ID user yang ada dikembalikan (redelivery event user.created).
 func (r *userRepository) CreateUser(ctx context.Context, user *entity.User) error {
 	ctx, span := tracing.Tracer.Start(ctx, "userRepository.CreateUser")
 	defer span.End()
 
-	query := "INSERT INTO users (name, email, created_at, updated_at) VALUES ($1, $2, NOW(), NOW()) RETURNING id"
+	// Jika baris dengan event_id yang sama baru di-commit transaksi lain setelah statement dimulai,
+	// SELECT kedua belum melihatnya dan hasilnya ErrNoRows; consumer cukup mengulang event.
+	query := `WITH ins AS (
+                  INSERT INTO users (name, email, event_id, created_at, updated_at)
+                  VALUES ($1, $2, NULLIF($3, ''), NOW(), NOW())
+                  ON CONFLICT (event_id) DO NOTHING
+                  RETURNING id
+              )
+              SELECT id FROM ins
+              UNION ALL
+              SELECT id FROM users WHERE event_id = NULLIF($3, '')
+              LIMIT 1`
 
 	span.SetAttributes(
 		attribute.String("db.system", "postgresql"),
@@ -45,7 +71,10 @@ func (r *userRepository) CreateUser(ctx context.Context, user *entity.User) erro
 		attribute.String("db.user.email", user.Email),
 	)
 
-	err := r.db.QueryRow(ctx, query, user.Name, user.Email).Scan(&user.ID)
+	err := r.db.QueryRow(ctx, query, user.Name, user.Email, user.EventID).Scan(&user.ID)
+	if isUniqueViolation(err, "users_email_key") {
+		err = ErrDuplicateEmail
+	}
 	if err != nil {
 		span.RecordError(err)
 	} else {
@@ -75,8 +104,8 @@ func (r *userRepository) GetUserByID(ctx context.Context, id int) (*entity.User,
 	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		span.RecordError(err)
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -118,6 +147,9 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *entity.User) erro
 	)
 
 	_, err = r.db.Exec(ctx, query, user.Name, user.Email, user.ID)
+	if isUniqueViolation(err, "users_email_key") {
+		err = ErrDuplicateEmail
+	}
 	if err != nil {
 		span.RecordError(err)
 	}
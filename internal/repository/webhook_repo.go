@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-crud/internal/entity"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrWebhookSubscriptionNotFound dikembalikan jika subscription ID tidak ada
+var ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// WebhookRepository menyimpan subscription webhook keluar dan antrean pengirimannya.
+// Baris pengiriman sekaligus menjadi log: status, jumlah percobaan dan response terakhir.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error)
+	// UpdateSubscription mengganti url, events, description dan active, secret hanya diganti
+	// jika diisi. Mengaktifkan kembali subscription mereset hitungan gagal berturut-turut.
+	UpdateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	// EnqueueDeliveries memasukkan pengiriman baru, event yang sudah pernah dimasukkan untuk
+	// subscription yang sama (event bus mengirim ulang) dilewati. Mengembalikan jumlah yang masuk.
+	EnqueueDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) (int, error)
+	// ClaimDueDeliveries mengambil pengiriman pending yang jatuh tempo milik subscription aktif
+	// dan menggeser next_attempt_at sejauh claim supaya tidak dikirim worker lain bersamaan
+	ClaimDueDeliveries(ctx context.Context, limit int, claim time.Duration) ([]entity.WebhookDelivery, error)
+	// RecordAttempt menyimpan hasil satu percobaan (status, attempts dan last_*). Pengiriman
+	// pending dicoba lagi setelah retryIn, pengiriman succeeded mendapat delivered_at.
+	RecordAttempt(ctx context.Context, d *entity.WebhookDelivery, retryIn time.Duration) error
+	// RecordSubscriptionResult mereset hitungan gagal subscription saat ok, atau menambahnya dan
+	// menonaktifkan subscription begitu mencapai disableAfter. true jika panggilan ini menonaktifkannya.
+	RecordSubscriptionResult(ctx context.Context, id int64, ok bool, disableAfter int, reason string) (bool, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, url, events, secret, COALESCE(description, ''), active, consecutive_failures,
+              COALESCE(disabled_reason, ''), disabled_at, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+              COALESCE(last_status_code, 0), COALESCE(last_error, ''), COALESCE(last_response, ''), duration_ms, created_at, delivered_at`
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.CreateSubscription")
+	defer span.End()
+
+	query := `INSERT INTO webhook_subscriptions (url, events, secret, description, active, created_at, updated_at)
+              VALUES ($1, $2, $3, NULLIF($4, ''), true, NOW(), NOW()) RETURNING ` + webhookSubscriptionColumns
+
+	// Secret tidak ikut dicatat di span
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+	)
+
+	created, err := scanWebhookSubscription(r.db.QueryRow(ctx, query, sub.URL, sub.Events, sub.Secret, sub.Description))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	*sub = *created
+	span.SetAttributes(attribute.Int64("db.webhook_subscription.id", sub.ID))
+	return nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.GetSubscription")
+	defer span.End()
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_subscription.id", id),
+	)
+
+	sub, err := scanWebhookSubscription(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookSubscriptionNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.ListSubscriptions")
+	defer span.End()
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY id`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+	)
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []entity.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("db.result.count", len(subs)))
+	return subs, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *entity.WebhookSubscription) error {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.UpdateSubscription")
+	defer span.End()
+
+	// Di dalam SET, "active" masih bernilai lama
+	query := `UPDATE webhook_subscriptions
+              SET url = @url, events = @events, description = NULLIF(@description, ''),
+                  secret = COALESCE(NULLIF(@secret, ''), secret),
+                  consecutive_failures = CASE WHEN @active AND NOT active THEN 0 ELSE consecutive_failures END,
+                  disabled_reason = CASE WHEN @active THEN NULL ELSE COALESCE(disabled_reason, NULLIF(@reason, '')) END,
+                  disabled_at = CASE WHEN @active THEN NULL ELSE COALESCE(disabled_at, NOW()) END,
+                  active = @active, updated_at = NOW()
+              WHERE id = @id
+              RETURNING ` + webhookSubscriptionColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_subscription.id", sub.ID),
+	)
+
+	args := pgx.NamedArgs{
+		"id":          sub.ID,
+		"url":         sub.URL,
+		"events":      sub.Events,
+		"description": sub.Description,
+		"secret":      sub.Secret,
+		"active":      sub.Active,
+		"reason":      sub.DisabledReason,
+	}
+	updated, err := scanWebhookSubscription(r.db.QueryRow(ctx, query, args))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookSubscriptionNotFound
+		}
+		span.RecordError(err)
+		return err
+	}
+	*sub = *updated
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.DeleteSubscription")
+	defer span.End()
+
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "DELETE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_subscription.id", id),
+	)
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.EnqueueDeliveries")
+	defer span.End()
+
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+              VALUES ($1, $2, $3, $4, 'pending', NOW(), NOW())
+              ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.webhook_delivery.count", len(deliveries)),
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := 0
+	for _, d := range deliveries {
+		tag, err := tx.Exec(ctx, query, d.SubscriptionID, d.EventID, d.EventType, d.Payload)
+		if err != nil {
+			span.RecordError(err)
+			return 0, err
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	return inserted, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, claim time.Duration) ([]entity.WebhookDelivery, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.ClaimDueDeliveries")
+	defer span.End()
+
+	// Pengiriman milik subscription nonaktif tetap pending dan dilanjutkan saat diaktifkan kembali
+	query := `UPDATE webhook_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2::float8)
+              WHERE id IN (
+                  SELECT d.id FROM webhook_deliveries d
+                  JOIN webhook_subscriptions s ON s.id = d.subscription_id
+                  WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND s.active
+                  ORDER BY d.id
+                  LIMIT $1
+                  FOR UPDATE OF d SKIP LOCKED
+              )
+              RETURNING ` + webhookDeliveryColumns
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.limit", limit),
+	)
+
+	rows, err := r.db.Query(ctx, query, limit, claim.Seconds())
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("db.result.count", len(deliveries)))
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, d *entity.WebhookDelivery, retryIn time.Duration) error {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.RecordAttempt")
+	defer span.End()
+
+	query := `UPDATE webhook_deliveries
+              SET status = $2, attempts = $3, next_attempt_at = NOW() + make_interval(secs => $4::float8),
+                  last_status_code = NULLIF($5, 0), last_error = NULLIF($6, ''), last_response = NULLIF($7, ''), duration_ms = $8,
+                  delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() END
+              WHERE id = $1`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_delivery.id", d.ID),
+	)
+
+	_, err := r.db.Exec(ctx, query, d.ID, d.Status, d.Attempts, retryIn.Seconds(), d.LastStatusCode, d.LastError, d.LastResponse, d.DurationMs)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+func (r *webhookRepository) RecordSubscriptionResult(ctx context.Context, id int64, ok bool, disableAfter int, reason string) (bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.RecordSubscriptionResult")
+	defer span.End()
+
+	if ok {
+		query := `UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures > 0`
+		span.SetAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", "UPDATE"),
+			attribute.String("db.statement", query),
+			attribute.Int64("db.webhook_subscription.id", id),
+		)
+		if _, err := r.db.Exec(ctx, query, id); err != nil {
+			span.RecordError(err)
+			return false, err
+		}
+		return false, nil
+	}
+
+	// Di dalam SET semua kolom masih bernilai lama, jadi "trip" berarti subscription baru
+	// mencapai batas pada kegagalan ini
+	query := `WITH prev AS (SELECT active FROM webhook_subscriptions WHERE id = @id FOR UPDATE)
+              UPDATE webhook_subscriptions s
+              SET consecutive_failures = s.consecutive_failures + 1,
+                  active = s.active AND s.consecutive_failures + 1 < @disable_after,
+                  disabled_at = CASE WHEN s.active AND s.consecutive_failures + 1 >= @disable_after THEN NOW() ELSE s.disabled_at END,
+                  disabled_reason = CASE WHEN s.active AND s.consecutive_failures + 1 >= @disable_after THEN @reason ELSE s.disabled_reason END,
+                  updated_at = NOW()
+              FROM prev
+              WHERE s.id = @id
+              RETURNING prev.active AND NOT s.active`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_subscription.id", id),
+	)
+
+	var disabled bool
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{"id": id, "disable_after": disableAfter, "reason": reason}).Scan(&disabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Subscription dihapus saat pengiriman berjalan
+		return false, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	return disabled, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookRepository.ListDeliveries")
+	defer span.End()
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY id DESC LIMIT $2`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.Int64("db.webhook_subscription.id", subscriptionID),
+	)
+
+	rows, err := r.db.Query(ctx, query, subscriptionID, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []entity.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("db.result.count", len(deliveries)))
+	return deliveries, nil
+}
+
+func scanWebhookSubscription(row pgx.Row) (*entity.WebhookSubscription, error) {
+	var sub entity.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Events, &sub.Secret, &sub.Description, &sub.Active, &sub.ConsecutiveFailures,
+		&sub.DisabledReason, &sub.DisabledAt, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	var d entity.WebhookDelivery
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.LastResponse, &d.DurationMs, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"go-crud/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WebhookSecretRepository menyimpan secret webhook git per repository. Secret disimpan apa
+// adanya karena dibutuhkan untuk menghitung ulang HMAC payload.
+type WebhookSecretRepository interface {
+	SetWebhookSecret(ctx context.Context, repoID int, secret string) error
+	// GetWebhookSecrets mengembalikan secret per repository ID, repository tanpa secret tidak ada di map
+	GetWebhookSecrets(ctx context.Context, repoIDs []int) (map[int]string, error)
+}
+
+type webhookSecretRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookSecretRepository(db *pgxpool.Pool) WebhookSecretRepository {
+	return &webhookSecretRepository{db: db}
+}
+
+func (r *webhookSecretRepository) SetWebhookSecret(ctx context.Context, repoID int, secret string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookSecretRepository.SetWebhookSecret")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO repository_webhook_secrets (repository_id, secret, updated_at)
+              VALUES ($1, $2, NOW())
+              ON CONFLICT (repository_id) DO UPDATE SET secret = EXCLUDED.secret, updated_at = NOW()`
+
+	// Secret tidak ikut dicatat di span
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.statement", query),
+		attribute.Int("db.repository.id", repoID),
+	)
+
+	if _, err := r.db.Exec(ctx, query, repoID, secret); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookSecretRepository) GetWebhookSecrets(ctx context.Context, repoIDs []int) (map[int]string, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "webhookSecretRepository.GetWebhookSecrets")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT repository_id, secret FROM repository_webhook_secrets WHERE repository_id = ANY($1)`
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.statement", query),
+		attribute.IntSlice("db.repository.ids", repoIDs),
+	)
+
+	secrets := make(map[int]string, len(repoIDs))
+	if len(repoIDs) == 0 {
+		return secrets, nil
+	}
+
+	rows, err := r.db.Query(ctx, query, repoIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			repoID int
+			secret string
+		)
+		if err := rows.Scan(&repoID, &secret); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		secrets[repoID] = secret
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return secrets, nil
+}
@@ -0,0 +1,91 @@
+package review
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+)
+
+// Batas panjang fungsi default untuk analyzer function-size
+const defaultMaxFunctionLines = 80
+
+// BuiltinAnalyzers mengembalikan semua analyzer bawaan dengan pengaturan default,
+// didaftarkan ke registry saat aplikasi start
+func BuiltinAnalyzers() []port.Analyzer {
+	return []port.Analyzer{
+		NewGofmtAnalyzer(),
+		NewStyleAnalyzer(),
+		NewVetAnalyzer(),
+		NewUncheckedErrorAnalyzer(),
+		NewPrintlnAnalyzer(),
+		NewEmptyErrorBranchAnalyzer(),
+		NewFunctionSizeAnalyzer(defaultMaxFunctionLines),
+		NewTodoAnalyzer(),
+	}
+}
+
+// goAnalyzer menjalankan sekumpulan fileCheck atas setiap file Go yang berhasil di-parse
+type goAnalyzer struct {
+	name   string
+	checks []fileCheck
+}
+
+func (a *goAnalyzer) Name() string {
+	return a.name
+}
+
+func (a *goAnalyzer) Languages() []string {
+	return []string{port.LanguageGo}
+}
+
+func (a *goAnalyzer) Analyze(ctx context.Context, ws *port.AnalysisWorkspace) ([]entity.ReviewFinding, error) {
+	var findings []entity.ReviewFinding
+	for _, sf := range ws.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if sf.AST == nil {
+			continue
+		}
+		f := &sourceFile{sf}
+		for _, check := range a.checks {
+			findings = append(findings, check(f)...)
+		}
+	}
+	return findings, nil
+}
+
+// NewGofmtAnalyzer melaporkan file yang belum di-gofmt
+func NewGofmtAnalyzer() port.Analyzer {
+	return &goAnalyzer{name: "gofmt", checks: []fileCheck{checkGofmt}}
+}
+
+// NewStyleAnalyzer memeriksa konvensi penulisan Go: doc comment exported dan pesan error
+func NewStyleAnalyzer() port.Analyzer {
+	return &goAnalyzer{name: "go-style", checks: []fileCheck{checkExportedDoc, checkErrorStrings}}
+}
+
+// NewVetAnalyzer menjalankan diagnostik ala go vet
+func NewVetAnalyzer() port.Analyzer {
+	return &goAnalyzer{name: "go-vet", checks: []fileCheck{checkDeferInLoop, checkSelfAssign, checkPrintf, checkUnreachable}}
+}
+
+// NewPrintlnAnalyzer melaporkan fmt.Print* di luar package main
+func NewPrintlnAnalyzer() port.Analyzer {
+	return &goAnalyzer{name: "println-in-library", checks: []fileCheck{checkPrintlnInLibrary}}
+}
+
+// NewEmptyErrorBranchAnalyzer melaporkan if err != nil {} yang kosong
+func NewEmptyErrorBranchAnalyzer() port.Analyzer {
+	return &goAnalyzer{name: "empty-error-branch", checks: []fileCheck{checkEmptyErrorBranch}}
+}
+
+// NewFunctionSizeAnalyzer melaporkan fungsi yang lebih panjang dari maxLines baris
+func NewFunctionSizeAnalyzer(maxLines int) port.Analyzer {
+	if maxLines < 1 {
+		maxLines = defaultMaxFunctionLines
+	}
+	return &goAnalyzer{name: "function-size", checks: []fileCheck{
+		func(f *sourceFile) []entity.ReviewFinding { return checkFunctionSize(f, maxLines) },
+	}}
+}
@@ -0,0 +1,207 @@
+package review
+
+import (
+	"bytes"
+	"go-crud/internal/entity"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// checkGofmt melaporkan file yang berbeda dari output gofmt, di baris pertama yang berbeda
+func checkGofmt(f *sourceFile) []entity.ReviewFinding {
+	formatted, err := format.Source(f.Src)
+	if err != nil || bytes.Equal(formatted, f.Src) {
+		return nil
+	}
+
+	line := firstDiffLine(f.Src, formatted)
+	return []entity.ReviewFinding{{
+		RuleID:       "gofmt",
+		Severity:     entity.SeverityWarning,
+		File:         f.Path,
+		StartLine:    line,
+		StartColumn:  1,
+		EndLine:      line,
+		EndColumn:    1,
+		Message:      "file is not gofmt-ed",
+		SuggestedFix: "run gofmt -w " + f.Path,
+	}}
+}
+
+func firstDiffLine(a, b []byte) int {
+	line := 1
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return line
+		}
+		if a[i] == '\n' {
+			line++
+		}
+	}
+	return line
+}
+
+// checkExportedDoc melaporkan fungsi dan type exported tanpa doc comment
+func checkExportedDoc(f *sourceFile) []entity.ReviewFinding {
+	if strings.HasSuffix(f.Path, "_test.go") {
+		return nil
+	}
+
+	var findings []entity.ReviewFinding
+	for _, decl := range f.AST.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() || d.Doc != nil || !exportedReceiver(d) {
+				continue
+			}
+			kind := "function"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			findings = append(findings, f.finding("exported-doc", entity.SeverityInfo, d.Name,
+				"exported "+kind+" "+d.Name.Name+" should have a doc comment",
+				"add a comment of the form \"// "+d.Name.Name+" ...\""))
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				// Doc bisa menempel di GenDecl (type X ...) atau di spec dalam type ( ... )
+				if !ts.Name.IsExported() || ts.Doc != nil || (d.Doc != nil && !d.Lparen.IsValid()) {
+					continue
+				}
+				findings = append(findings, f.finding("exported-doc", entity.SeverityInfo, ts.Name,
+					"exported type "+ts.Name.Name+" should have a doc comment",
+					"add a comment of the form \"// "+ts.Name.Name+" ...\""))
+			}
+		}
+	}
+	return findings
+}
+
+// exportedReceiver bernilai true untuk fungsi biasa atau method dengan receiver exported
+func exportedReceiver(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return true
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// checkDeferInLoop melaporkan defer di dalam loop, baru dijalankan saat fungsi return
+func checkDeferInLoop(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+
+	var walk func(root ast.Node, inLoop bool)
+	walk = func(root ast.Node, inLoop bool) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			if n == root {
+				return true
+			}
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				// Defer dalam closure dijalankan saat closure selesai
+				walk(n.Body, false)
+				return false
+			case *ast.ForStmt:
+				walk(n.Body, true)
+				return false
+			case *ast.RangeStmt:
+				walk(n.Body, true)
+				return false
+			case *ast.DeferStmt:
+				if inLoop {
+					findings = append(findings, f.finding("defer-in-loop", entity.SeverityWarning, n,
+						"defer inside a loop runs only when the function returns",
+						"move the loop body into a function so the defer runs every iteration"))
+				}
+			}
+			return true
+		})
+	}
+	walk(f.AST, false)
+
+	return findings
+}
+
+// checkErrorStrings mengikuti konvensi Go: pesan error huruf kecil tanpa tanda baca di akhir
+func checkErrorStrings(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		name := calleeName(call)
+		if name != "errors.New" && name != "fmt.Errorf" {
+			return true
+		}
+		msg, ok := stringLit(call.Args[0])
+		if !ok || msg == "" {
+			return true
+		}
+
+		if first, size := utf8.DecodeRuneInString(msg); unicode.IsUpper(first) {
+			// Akronim seperti "URL ..." atau "ID ..." tetap boleh
+			if second, _ := utf8.DecodeRuneInString(msg[size:]); !unicode.IsUpper(second) {
+				findings = append(findings, f.finding("error-string", entity.SeverityInfo, call.Args[0],
+					"error strings should not be capitalized",
+					strconv.Quote(string(unicode.ToLower(first))+msg[size:])))
+				return true
+			}
+		}
+		if strings.ContainsAny(msg[len(msg)-1:], ".:!\n") {
+			findings = append(findings, f.finding("error-string", entity.SeverityInfo, call.Args[0],
+				"error strings should not end with punctuation or newlines",
+				strconv.Quote(strings.TrimRight(msg, ".:!\n"))))
+		}
+		return true
+	})
+
+	return findings
+}
+
+// calleeName mengembalikan "pkg.Func" untuk pemanggilan selector, atau nama fungsi biasa
+func calleeName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		if x, ok := fn.X.(*ast.Ident); ok {
+			return x.Name + "." + fn.Sel.Name
+		}
+	}
+	return ""
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
@@ -0,0 +1,184 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/usecase/port"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnsupportedURL = errors.New("unsupported repository URL")
+	ErrRemoteDisabled = errors.New("remote repository checkout is disabled (set REVIEW_ALLOW_REMOTE=true)")
+	ErrLocalDisabled  = errors.New("local repository checkout is disabled (set REVIEW_ALLOW_LOCAL=true and REVIEW_LOCAL_ROOT)")
+	ErrLocalOutside   = errors.New("local repository path is outside REVIEW_LOCAL_ROOT")
+	ErrInvalidRef     = errors.New("invalid git ref")
+)
+
+// GitCheckout meng-clone repository dengan binary git lokal ke direktori sementara.
+// Remote (http/https/ssh/git) hanya jika AllowRemote. file:// dan path lokal hanya jika
+// AllowLocal dan path-nya (setelah symlink di-resolve) berada di dalam LocalRoot, supaya
+// URL repository tidak bisa dipakai membaca file server seperti /etc.
+type GitCheckout struct {
+	BaseDir     string
+	AllowRemote bool
+	AllowLocal  bool
+	LocalRoot   string
+}
+
+// NewGitCheckout membaca REVIEW_WORKDIR, REVIEW_ALLOW_REMOTE, REVIEW_ALLOW_LOCAL dan REVIEW_LOCAL_ROOT dari env
+func NewGitCheckout() *GitCheckout {
+	return &GitCheckout{
+		BaseDir:     os.Getenv("REVIEW_WORKDIR"),
+		AllowRemote: os.Getenv("REVIEW_ALLOW_REMOTE") == "true",
+		AllowLocal:  os.Getenv("REVIEW_ALLOW_LOCAL") == "true",
+		LocalRoot:   os.Getenv("REVIEW_LOCAL_ROOT"),
+	}
+}
+
+func (g *GitCheckout) Checkout(ctx context.Context, rawURL string, ref string) (*port.Workspace, error) {
+	// Ref diawali "-" akan dibaca git sebagai opsi
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRef, ref)
+	}
+	src, remote, err := g.resolveSource(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(g.BaseDir, "codereview-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	args := []string{"clone", "--quiet"}
+	if remote {
+		// Clone lokal sudah murah (hardlink), shallow clone hanya untuk remote
+		args = append(args, "--depth", "1")
+	}
+	args = append(args, "--", src, dir)
+	if _, err := runGit(ctx, "", args...); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	if ref != "" {
+		if err := checkoutRef(ctx, dir, ref); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
+	sha, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return &port.Workspace{Dir: dir, CommitSHA: sha, Cleanup: cleanup}, nil
+}
+
+// checkoutRef pindah ke ref tertentu, misalnya commit yang tercatat di checkpoint review
+func checkoutRef(ctx context.Context, dir, ref string) error {
+	sha, err := resolveCommit(ctx, dir, ref)
+	if err != nil {
+		return err
+	}
+	_, err = runGit(ctx, dir, "checkout", "--quiet", "--detach", sha)
+	return err
+}
+
+// resolveCommit mengubah ref (SHA, branch atau tag) menjadi commit SHA. Branch selain default
+// hanya ada sebagai origin/<branch> di hasil clone, dan shallow clone remote belum tentu
+// punya commit tersebut, jadi sebagai jalan terakhir ref di-fetch dari origin.
+func resolveCommit(ctx context.Context, dir, ref string) (string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRef, ref)
+	}
+	for _, candidate := range []string{ref, "origin/" + ref} {
+		if sha, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return sha, nil
+		}
+	}
+	if _, err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrInvalidRef, ref, err)
+	}
+	return runGit(ctx, dir, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+}
+
+// resolveSource mengubah Repository.URL menjadi argumen sumber untuk git clone
+func (g *GitCheckout) resolveSource(rawURL string) (src string, remote bool, err error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", false, ErrUnsupportedURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err == nil && len(u.Scheme) > 1 {
+		switch u.Scheme {
+		case "file":
+			if u.Host != "" && u.Host != "localhost" {
+				return "", false, fmt.Errorf("%w: file URL with host %q", ErrUnsupportedURL, u.Host)
+			}
+			path, err := g.localPath(u.Path)
+			return path, false, err
+		case "http", "https", "ssh", "git":
+			if !g.AllowRemote {
+				return "", true, ErrRemoteDisabled
+			}
+			return rawURL, true, nil
+		default:
+			return "", false, fmt.Errorf("%w: scheme %q", ErrUnsupportedURL, u.Scheme)
+		}
+	}
+
+	// Tanpa scheme dianggap path lokal
+	path, err := g.localPath(rawURL)
+	return path, false, err
+}
+
+// localPath memastikan checkout lokal diizinkan dan path berada di dalam LocalRoot.
+// Path dikembalikan dalam bentuk absolut tanpa symlink, itu yang di-clone.
+func (g *GitCheckout) localPath(raw string) (string, error) {
+	if !g.AllowLocal || g.LocalRoot == "" {
+		return "", ErrLocalDisabled
+	}
+	root, err := filepath.Abs(g.LocalRoot)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("REVIEW_LOCAL_ROOT %s: %w", g.LocalRoot, err)
+	}
+
+	path, err := filepath.Abs(raw)
+	if err != nil {
+		return "", err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", fmt.Errorf("repository path %s: %w", raw, err)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrLocalOutside
+	}
+	return path, nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,53 @@
+package review
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFile adalah konfigurasi review yang dibaca dari root repository
+	ConfigFile = ".gocrud-review.yml"
+	// Konfigurasi lebih besar dari ini hampir pasti bukan file yang ditulis tangan
+	maxConfigSize = 64 << 10
+)
+
+// LoadConfig membaca ConfigFile di root workspace. Field yang tidak dikenal dianggap error
+// supaya salah ketik tidak diam-diam diabaikan. File kosong berarti konfigurasi kosong.
+func (e *Engine) LoadConfig(dir string) (*entity.ReviewConfig, string, error) {
+	// Lstat: symlink tidak diikuti supaya repository tidak bisa membuat server membaca file di luar workspace
+	info, err := os.Lstat(filepath.Join(dir, ConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ConfigFile, nil
+	}
+	if err != nil {
+		return nil, ConfigFile, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, ConfigFile, fmt.Errorf("%s is not a regular file", ConfigFile)
+	}
+	if info.Size() > maxConfigSize {
+		return nil, ConfigFile, fmt.Errorf("%s is larger than %d bytes", ConfigFile, maxConfigSize)
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		return nil, ConfigFile, err
+	}
+
+	var cfg entity.ReviewConfig
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, ConfigFile, fmt.Errorf("parse %s: %w", ConfigFile, err)
+	}
+	return &cfg, ConfigFile, nil
+}
@@ -0,0 +1,102 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/usecase/port"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Header hunk unified diff, hanya sisi head (+start,count) yang dipakai
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// Diff menghitung baris yang berubah dari base ke commit workspace. Seperti pull request,
+// pembanding adalah merge-base base dan head supaya commit baru di base tidak ikut terhitung;
+// shallow clone tidak punya histori untuk merge-base, jadi base dipakai langsung.
+func (g *GitCheckout) Diff(ctx context.Context, ws *port.Workspace, base string) (*port.Diff, error) {
+	baseSHA, err := resolveCommit(ctx, ws.Dir, base)
+	if err != nil {
+		return nil, err
+	}
+
+	from := baseSHA
+	if mergeBase, err := runGit(ctx, ws.Dir, "merge-base", baseSHA, ws.CommitSHA); err == nil {
+		from = mergeBase
+	}
+
+	out, err := gitOutput(ctx, ws.Dir, "diff", "--no-color", "--no-ext-diff",
+		"--unified=0", "--diff-filter=d", "--find-renames", from, ws.CommitSHA)
+	if err != nil {
+		return nil, err
+	}
+	return &port.Diff{BaseSHA: baseSHA, HeadSHA: ws.CommitSHA, Files: parseUnifiedDiff(out)}, nil
+}
+
+// parseUnifiedDiff mengambil rentang baris yang ditambah/diubah per file dari output
+// git diff --unified=0. Hunk yang hanya menghapus baris tidak punya baris di head.
+// File tanpa hunk (binary, rename tanpa perubahan isi) tidak ikut.
+func parseUnifiedDiff(out string) map[string][]port.LineRange {
+	files := map[string][]port.LineRange{}
+	current, inHeader := "", false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, inHeader = "", true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			current = diffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil || current == "" {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count == 0 {
+				continue
+			}
+			files[current] = append(files[current], port.LineRange{Start: start, End: start + count - 1})
+		}
+	}
+	return files
+}
+
+// diffPath mengubah "+++ b/path" menjadi path relatif. Nama dengan karakter non-ASCII atau
+// khusus ditulis git dalam tanda kutip dengan escape oktal, contoh "b/caf\303\251.go".
+func diffPath(name string) string {
+	if name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+	}
+	return strings.TrimPrefix(name, "b/")
+}
+
+// gitOutput seperti runGit tetapi hanya membaca stdout, peringatan git di stderr
+// (misalnya batas rename detection) tidak boleh tercampur ke output diff
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
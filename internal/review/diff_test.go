@@ -0,0 +1,175 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	"go-crud/internal/usecase/port"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want map[string][]port.LineRange
+	}{
+		{
+			name: "modified file with several hunks",
+			diff: `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@ import (
+-	"fmt"
++	"log"
+@@ -10,0 +11,3 @@ func main() {
++	a()
++	b()
++	c()
+`,
+			want: map[string][]port.LineRange{"main.go": {{Start: 3, End: 3}, {Start: 11, End: 13}}},
+		},
+		{
+			name: "rename with changes uses the new path",
+			diff: `diff --git a/old/name.go b/new/name.go
+similarity index 90%
+rename from old/name.go
+rename to new/name.go
+index 1111111..2222222 100644
+--- a/old/name.go
++++ b/new/name.go
+@@ -5 +5,2 @@ func x() {
+-	return 1
++	y()
++	return 2
+`,
+			want: map[string][]port.LineRange{"new/name.go": {{Start: 5, End: 6}}},
+		},
+		{
+			name: "pure rename has no hunks",
+			diff: `diff --git a/a.go b/b.go
+similarity index 100%
+rename from a.go
+rename to b.go
+`,
+			want: map[string][]port.LineRange{},
+		},
+		{
+			name: "deleted file has no head lines",
+			diff: `diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 1111111..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package gone
+-
+-func x() {}
+`,
+			want: map[string][]port.LineRange{},
+		},
+		{
+			name: "new file",
+			diff: `diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..1111111
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package main
++
+`,
+			want: map[string][]port.LineRange{"new.go": {{Start: 1, End: 2}}},
+		},
+		{
+			name: "deletion-only hunk is skipped",
+			diff: `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -7,2 +6,0 @@ func main() {
+-	a()
+-	b()
+@@ -20 +18 @@ func other() {
+-	old()
++	new()
+`,
+			want: map[string][]port.LineRange{"main.go": {{Start: 18, End: 18}}},
+		},
+		{
+			name: "no newline at end of file",
+			diff: `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -12 +12 @@ func main() {
+-}
+\ No newline at end of file
++}
+`,
+			want: map[string][]port.LineRange{"main.go": {{Start: 12, End: 12}}},
+		},
+		{
+			// Baris isi "++ b/evil.go" tampil sebagai "+++ b/evil.go" di dalam hunk
+			name: "added line that looks like a file header",
+			diff: `diff --git a/notes.md b/notes.md
+--- a/notes.md
++++ b/notes.md
+@@ -1,0 +2,2 @@
++++ b/evil.go
++text
+`,
+			want: map[string][]port.LineRange{"notes.md": {{Start: 2, End: 3}}},
+		},
+		{
+			name: "quoted path with non-ASCII name",
+			diff: `diff --git "a/caf\303\251.go" "b/caf\303\251.go"
+--- "a/caf\303\251.go"
++++ "b/caf\303\251.go"
+@@ -1 +1 @@
+-package a
++package b
+`,
+			want: map[string][]port.LineRange{"café.go": {{Start: 1, End: 1}}},
+		},
+		{
+			name: "binary file has no hunks",
+			diff: `diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ
+`,
+			want: map[string][]port.LineRange{},
+		},
+		{
+			name: "empty output",
+			diff: "",
+			want: map[string][]port.LineRange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUnifiedDiff(tt.diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseUnifiedDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"b/internal/review/diff.go", "internal/review/diff.go"},
+		{"/dev/null", ""},
+		{`"b/caf\303\251.go"`, "café.go"},
+		{`"b/with\ttab.go"`, "with\ttab.go"},
+		{`"b/with \"quote\".go"`, `with "quote".go`},
+		// Kutipan rusak dibiarkan apa adanya
+		{`"b/broken.go`, `"b/broken.go`},
+	}
+	for _, tt := range tests {
+		if got := diffPath(tt.name); got != tt.want {
+			t.Errorf("diffPath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
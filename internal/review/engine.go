@@ -0,0 +1,359 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// File lebih besar dari ini biasanya hasil generate, tidak dianalisis
+const maxFileSize = 1 << 20
+
+// sourceFile adalah satu file Go yang sudah di-parse
+type sourceFile struct {
+	*port.SourceFile
+}
+
+func (f *sourceFile) finding(ruleID, severity string, node ast.Node, message, fix string) entity.ReviewFinding {
+	start, end := f.Fset.Position(node.Pos()), f.Fset.Position(node.End())
+	return entity.ReviewFinding{
+		RuleID:       ruleID,
+		Severity:     severity,
+		File:         f.Path,
+		StartLine:    start.Line,
+		StartColumn:  start.Column,
+		EndLine:      end.Line,
+		EndColumn:    end.Column,
+		Message:      message,
+		SuggestedFix: fix,
+	}
+}
+
+// lineText mengembalikan isi baris (1-based) tanpa spasi di awal/akhir
+func lineText(src []byte, line int) string {
+	for i := 1; i < line; i++ {
+		idx := bytes.IndexByte(src, '\n')
+		if idx < 0 {
+			return ""
+		}
+		src = src[idx+1:]
+	}
+	if idx := bytes.IndexByte(src, '\n'); idx >= 0 {
+		src = src[:idx]
+	}
+	return string(bytes.TrimSpace(src))
+}
+
+// fingerprint menandai temuan secara stabil antar run: rule, file, pesan dan isi baris,
+// bukan nomor baris, supaya tidak berubah ketika kode di atasnya bergeser.
+// occurrence membedakan temuan identik di file yang sama.
+func fingerprint(f entity.ReviewFinding, text string, occurrence int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%d", f.RuleID, f.File, f.Message, text, occurrence)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fileCheck menghasilkan temuan untuk satu file Go
+type fileCheck func(f *sourceFile) []entity.ReviewFinding
+
+// Ekstensi file yang dianalisis dan bahasanya
+var languageByExt = map[string]string{
+	".go":    port.LanguageGo,
+	".py":    "python",
+	".js":    "javascript",
+	".ts":    "typescript",
+	".java":  "java",
+	".kt":    "kotlin",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".php":   "php",
+	".cs":    "csharp",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".swift": "swift",
+	".sh":    "shell",
+	".sql":   "sql",
+}
+
+func languageOf(path string) string {
+	return languageByExt[filepath.Ext(path)]
+}
+
+// Engine membaca file workspace dan menjalankan Analyzer per direktori
+type Engine struct{}
+
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Analyze menganalisis semua file kecuali opts.Skip. Hasilnya hanya temuan dari file
+// yang dianalisis pada pemanggilan ini, temuan file yang di-skip disimpan pemanggil.
+// Dengan opts.Diff hanya file yang berubah yang dilaporkan, tetapi analyzer tetap melihat
+// seluruh file di package-nya (misalnya untuk mengenali fungsi yang mengembalikan error).
+// File yang tidak dipilih opts.Include diabaikan sepenuhnya.
+func (e *Engine) Analyze(ctx context.Context, dir string, opts port.AnalyzeOptions) ([]entity.ReviewFinding, error) {
+	languages := map[string]bool{}
+	for _, a := range opts.Analyzers {
+		for _, lang := range a.Languages() {
+			languages[lang] = true
+		}
+	}
+
+	files, err := listSourceFiles(ctx, dir, func(lang string) bool {
+		return languages[lang] || languages[port.AnyLanguage]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Include != nil {
+		files = slices.DeleteFunc(files, func(rel string) bool { return !opts.Include(rel) })
+	}
+	total := len(files)
+	if opts.Diff != nil {
+		total = 0
+		for _, rel := range files {
+			if opts.Diff.Changed(rel) {
+				total++
+			}
+		}
+	}
+	if opts.OnStart != nil {
+		opts.OnStart(total)
+	}
+
+	findings := []entity.ReviewFinding{}
+	// File sudah urut, jadi file satu direktori selalu berurutan
+	for i := 0; i < len(files); {
+		pkgDir := path.Dir(files[i])
+		j := i
+		var batch, pkgFiles []string
+		for ; j < len(files) && path.Dir(files[j]) == pkgDir; j++ {
+			pkgFiles = append(pkgFiles, files[j])
+			if opts.Skip[files[j]] || (opts.Diff != nil && !opts.Diff.Changed(files[j])) {
+				continue
+			}
+			batch = append(batch, files[j])
+		}
+		i = j
+		if len(batch) == 0 {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		analyzed := batch
+		if opts.Diff != nil {
+			analyzed = pkgFiles
+		}
+		byFile, err := e.analyzePackage(ctx, dir, analyzed, opts.Analyzers, opts.Diff)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range batch {
+			fileFindings := byFile[rel]
+			if opts.Diff != nil {
+				fileFindings = changedLines(opts.Diff, fileFindings)
+			}
+			findings = append(findings, fileFindings...)
+			if opts.OnFile != nil {
+				opts.OnFile(rel, fileFindings)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartColumn < b.StartColumn
+	})
+	return findings, nil
+}
+
+// listSourceFiles mengembalikan path relatif (pakai '/') semua file dengan bahasa yang
+// didukung, urut leksikal
+func listSourceFiles(ctx context.Context, dir string, supported func(lang string) bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang := languageOf(d.Name())
+		if !d.Type().IsRegular() || lang == "" || !supported(lang) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// changedLines menyisakan temuan yang mengenai baris yang berubah
+func changedLines(diff *port.Diff, findings []entity.ReviewFinding) []entity.ReviewFinding {
+	var kept []entity.ReviewFinding
+	for _, f := range findings {
+		if diff.Touches(f.File, f.StartLine, f.EndLine) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// analyzePackage membaca file satu direktori lalu menjalankan setiap analyzer atas file
+// yang bahasanya didukung. Temuan dikelompokkan per file dan diberi fingerprint.
+func (e *Engine) analyzePackage(ctx context.Context, dir string, rels []string, analyzers []port.Analyzer, diff *port.Diff) (map[string][]entity.ReviewFinding, error) {
+	goEnabled := false
+	for _, a := range analyzers {
+		goEnabled = goEnabled || slices.Contains(a.Languages(), port.LanguageGo)
+	}
+
+	byFile := make(map[string][]entity.ReviewFinding, len(rels))
+	sources := make(map[string]*port.SourceFile, len(rels))
+	var files []*port.SourceFile
+
+	for _, rel := range rels {
+		src, err := readSource(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if src == nil {
+			continue
+		}
+
+		sf := &port.SourceFile{Path: rel, Language: languageOf(rel), Src: src}
+		// Syntax error hanya dilaporkan jika ada analyzer Go yang aktif
+		if sf.Language == port.LanguageGo && goEnabled {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, rel, src, parser.ParseComments|parser.AllErrors)
+			if err != nil {
+				byFile[rel] = parseFindings(rel, err)
+			} else {
+				sf.Fset, sf.AST = fset, file
+			}
+		}
+		sources[rel] = sf
+		files = append(files, sf)
+	}
+
+	for _, a := range analyzers {
+		ws := &port.AnalysisWorkspace{Dir: dir, Files: supportedFiles(files, a.Languages()), Diff: diff}
+		if len(ws.Files) == 0 {
+			continue
+		}
+		found, err := a.Analyze(ctx, ws)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %s: %w", a.Name(), err)
+		}
+		for _, f := range found {
+			if _, ok := sources[f.File]; ok {
+				byFile[f.File] = append(byFile[f.File], f)
+			}
+		}
+	}
+
+	for rel, findings := range byFile {
+		var src []byte
+		if sf := sources[rel]; sf != nil {
+			src = sf.Src
+		}
+		seen := map[string]int{}
+		for i := range findings {
+			text := lineText(src, findings[i].StartLine)
+			key := findings[i].RuleID + "\x00" + findings[i].Message + "\x00" + text
+			findings[i].Fingerprint = fingerprint(findings[i], text, seen[key])
+			seen[key]++
+		}
+	}
+	return byFile, nil
+}
+
+// readSource membaca isi file, nil untuk file yang terlalu besar
+func readSource(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxFileSize {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
+
+func supportedFiles(files []*port.SourceFile, languages []string) []*port.SourceFile {
+	var out []*port.SourceFile
+	for _, f := range files {
+		for _, lang := range languages {
+			if lang == port.AnyLanguage || lang == f.Language {
+				out = append(out, f)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// parseFindings mengubah syntax error pertama menjadi temuan, file tidak dianalisis lebih lanjut.
+// Error berikutnya biasanya hanya efek lanjutan dari error pertama.
+func parseFindings(rel string, err error) []entity.ReviewFinding {
+	finding := entity.ReviewFinding{
+		RuleID:      "parse",
+		Severity:    entity.SeverityError,
+		File:        rel,
+		StartLine:   1,
+		StartColumn: 1,
+		Message:     err.Error(),
+	}
+
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		finding.StartLine = list[0].Pos.Line
+		finding.StartColumn = list[0].Pos.Column
+		finding.Message = list[0].Msg
+	}
+	finding.EndLine, finding.EndColumn = finding.StartLine, finding.StartColumn
+	return []entity.ReviewFinding{finding}
+}
+
+// skipDir mengikuti aturan go tool: vendor, testdata, dan direktori berawalan . atau _
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
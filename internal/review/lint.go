@@ -0,0 +1,251 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// Fungsi standard library (dan library umum) yang mengembalikan error
+var errorFuncs = map[string]bool{
+	"os.Remove":           true,
+	"os.RemoveAll":        true,
+	"os.Setenv":           true,
+	"os.Unsetenv":         true,
+	"os.Chdir":            true,
+	"os.Chmod":            true,
+	"os.Chown":            true,
+	"os.Mkdir":            true,
+	"os.MkdirAll":         true,
+	"os.WriteFile":        true,
+	"os.Rename":           true,
+	"os.Symlink":          true,
+	"os.Link":             true,
+	"os.Truncate":         true,
+	"json.Unmarshal":      true,
+	"xml.Unmarshal":       true,
+	"yaml.Unmarshal":      true,
+	"io.WriteString":      true,
+	"io.Copy":             true,
+	"io.ReadFull":         true,
+	"http.ListenAndServe": true,
+	"godotenv.Load":       true,
+	"filepath.Walk":       true,
+	"filepath.WalkDir":    true,
+}
+
+// Method encoder/decoder yang error-nya sering diabaikan, contoh json.NewEncoder(w).Encode(v)
+var errorCodecs = map[string]bool{
+	"json.NewEncoder": true,
+	"json.NewDecoder": true,
+	"xml.NewEncoder":  true,
+	"xml.NewDecoder":  true,
+	"gob.NewEncoder":  true,
+	"gob.NewDecoder":  true,
+}
+
+// uncheckedErrorAnalyzer melaporkan pemanggilan fungsi yang mengembalikan error sebagai statement
+// tanpa memakai hasilnya. Tanpa type checker, fungsi dikenali dari daftar errorFuncs dan dari
+// deklarasi fungsi/method di package yang sama yang hasil terakhirnya bertipe error.
+type uncheckedErrorAnalyzer struct{}
+
+// NewUncheckedErrorAnalyzer melaporkan error yang tidak diperiksa
+func NewUncheckedErrorAnalyzer() port.Analyzer {
+	return uncheckedErrorAnalyzer{}
+}
+
+func (uncheckedErrorAnalyzer) Name() string {
+	return "unchecked-error"
+}
+
+func (uncheckedErrorAnalyzer) Languages() []string {
+	return []string{port.LanguageGo}
+}
+
+func (uncheckedErrorAnalyzer) Analyze(ctx context.Context, ws *port.AnalysisWorkspace) ([]entity.ReviewFinding, error) {
+	// Fungsi dan method di package ini yang mengembalikan error
+	funcs, methods := map[string]bool{}, map[string]bool{}
+	for _, sf := range ws.Files {
+		if sf.AST == nil {
+			continue
+		}
+		for _, decl := range sf.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !returnsError(fn.Type) {
+				continue
+			}
+			if fn.Recv != nil {
+				methods[fn.Name.Name] = true
+			} else {
+				funcs[fn.Name.Name] = true
+			}
+		}
+	}
+
+	var findings []entity.ReviewFinding
+	for _, sf := range ws.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if sf.AST == nil {
+			continue
+		}
+		f := &sourceFile{sf}
+		imports := importNames(sf.AST)
+
+		ast.Inspect(sf.AST, func(n ast.Node) bool {
+			stmt, ok := n.(*ast.ExprStmt)
+			if !ok {
+				return true
+			}
+			call, ok := stmt.X.(*ast.CallExpr)
+			if !ok || !callReturnsError(call, imports, funcs, methods) {
+				return true
+			}
+			findings = append(findings, f.finding("unchecked-error", entity.SeverityWarning, call,
+				fmt.Sprintf("error returned by %s is not checked", types.ExprString(call.Fun)),
+				"handle the error, or assign it to _ if ignoring it is intended"))
+			return true
+		})
+	}
+	return findings, nil
+}
+
+func callReturnsError(call *ast.CallExpr, imports, funcs, methods map[string]bool) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return funcs[fn.Name]
+	case *ast.SelectorExpr:
+		if x, ok := fn.X.(*ast.Ident); ok && imports[x.Name] {
+			return errorFuncs[x.Name+"."+fn.Sel.Name]
+		}
+		if inner, ok := fn.X.(*ast.CallExpr); ok && errorCodecs[calleeName(inner)] {
+			return fn.Sel.Name == "Encode" || fn.Sel.Name == "Decode"
+		}
+		return methods[fn.Sel.Name]
+	}
+	return false
+}
+
+func returnsError(ft *ast.FuncType) bool {
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return false
+	}
+	last, ok := ft.Results.List[len(ft.Results.List)-1].Type.(*ast.Ident)
+	return ok && last.Name == "error"
+}
+
+// importNames mengembalikan nama package yang di-import file, memakai alias jika ada
+func importNames(file *ast.File) map[string]bool {
+	names := map[string]bool{}
+	for _, imp := range file.Imports {
+		if imp.Name != nil {
+			names[imp.Name.Name] = true
+			continue
+		}
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		// Versi major di akhir path, contoh github.com/go-chi/chi/v5 -> chi
+		if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+			trimmed := strings.TrimSuffix(path, "/"+name)
+			name = trimmed[strings.LastIndex(trimmed, "/")+1:]
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// checkPrintlnInLibrary melaporkan fmt.Print* di package selain main, output seharusnya lewat logger
+func checkPrintlnInLibrary(f *sourceFile) []entity.ReviewFinding {
+	if f.AST.Name.Name == "main" || strings.HasSuffix(f.Path, "_test.go") {
+		return nil
+	}
+
+	var findings []entity.ReviewFinding
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch name := calleeName(call); name {
+		case "fmt.Println", "fmt.Printf", "fmt.Print":
+			findings = append(findings, f.finding("println-in-library", entity.SeverityInfo, call,
+				name+" in package "+f.AST.Name.Name+" writes directly to stdout",
+				"use a logger or return the value to the caller"))
+		}
+		return true
+	})
+	return findings
+}
+
+// checkEmptyErrorBranch melaporkan if err != nil { } yang menelan error
+func checkEmptyErrorBranch(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.IfStmt)
+		if !ok || len(stmt.Body.List) > 0 {
+			return true
+		}
+		if name, ok := errNotNil(stmt.Cond); ok {
+			findings = append(findings, f.finding("empty-error-branch", entity.SeverityWarning, stmt.Cond,
+				"empty branch for "+name+" != nil silently ignores the error",
+				"return, wrap or log the error"))
+		}
+		return true
+	})
+	return findings
+}
+
+// errNotNil mengenali kondisi "err != nil" (atau nil != err) untuk variabel bernama err/xxxErr
+func errNotNil(cond ast.Expr) (string, bool) {
+	bin, ok := cond.(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return "", false
+	}
+	x, y := bin.X, bin.Y
+	if isNil(x) {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok || !isNil(y) {
+		return "", false
+	}
+	if id.Name == "err" || strings.HasSuffix(id.Name, "Err") || strings.HasSuffix(id.Name, "err") {
+		return id.Name, true
+	}
+	return "", false
+}
+
+func isNil(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+// checkFunctionSize melaporkan fungsi yang lebih panjang dari maxLines, dihitung dari
+// baris deklarasi sampai kurung tutup
+func checkFunctionSize(f *sourceFile, maxLines int) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+	for _, decl := range f.AST.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		lines := f.Fset.Position(fn.End()).Line - f.Fset.Position(fn.Pos()).Line + 1
+		if lines <= maxLines {
+			continue
+		}
+		findings = append(findings, f.finding("function-size", entity.SeverityInfo, fn.Name,
+			fmt.Sprintf("function %s is %d lines long (max %d)", fn.Name.Name, lines, maxLines),
+			"split it into smaller functions"))
+	}
+	return findings
+}
@@ -0,0 +1,86 @@
+package review
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Panjang maksimum teks TODO yang disalin ke pesan temuan
+const maxTodoText = 100
+
+// Penanda komentar (// # /* -- atau * di awal baris) yang diikuti TODO/FIXME pada baris yang sama
+var todoPattern = regexp.MustCompile(`(?://|#|/\*|--|^\s*\*).*?\b(TODO|FIXME)\b(.*)$`)
+
+// todoAnalyzer mencatat komentar TODO dan FIXME di file bahasa apa pun
+type todoAnalyzer struct{}
+
+// NewTodoAnalyzer melacak TODO/FIXME supaya utang teknis terlihat di laporan review
+func NewTodoAnalyzer() port.Analyzer {
+	return todoAnalyzer{}
+}
+
+func (todoAnalyzer) Name() string {
+	return "todo"
+}
+
+func (todoAnalyzer) Languages() []string {
+	return []string{port.AnyLanguage}
+}
+
+func (todoAnalyzer) Analyze(ctx context.Context, ws *port.AnalysisWorkspace) ([]entity.ReviewFinding, error) {
+	var findings []entity.ReviewFinding
+	for _, sf := range ws.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(sf.Src))
+		scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			m := todoPattern.FindStringSubmatchIndex(text)
+			if m == nil {
+				continue
+			}
+
+			keyword := text[m[2]:m[3]]
+			finding := entity.ReviewFinding{
+				RuleID:      "todo",
+				Severity:    entity.SeverityInfo,
+				File:        sf.Path,
+				StartLine:   line,
+				StartColumn: utf8.RuneCountInString(text[:m[2]]) + 1,
+				EndLine:     line,
+				EndColumn:   utf8.RuneCountInString(text) + 1,
+				Message:     keyword + " comment",
+			}
+			if keyword == "FIXME" {
+				finding.RuleID, finding.Severity = "fixme", entity.SeverityWarning
+			}
+			if note := todoText(text[m[4]:m[5]]); note != "" {
+				finding.Message += ": " + note
+			}
+			findings = append(findings, finding)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return findings, nil
+}
+
+// todoText membersihkan teks setelah TODO, contoh ": cek ulang */" -> "cek ulang"
+func todoText(s string) string {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "*/"))
+	s = strings.TrimSpace(strings.TrimLeft(s, ":-"))
+	if utf8.RuneCountInString(s) > maxTodoText {
+		s = string([]rune(s)[:maxTodoText]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+package review
+
+import (
+	"fmt"
+	"go-crud/internal/entity"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Diagnostik ala go vet yang bisa dijalankan hanya dari AST, tanpa build dependency repository
+
+// checkSelfAssign melaporkan assignment x = x
+func checkSelfAssign(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			if !isPlainRef(lhs) {
+				continue
+			}
+			if l, r := types.ExprString(lhs), types.ExprString(assign.Rhs[i]); l == r {
+				findings = append(findings, f.finding("vet/assign", entity.SeverityWarning, assign,
+					"self-assignment of "+r+" to "+l, "remove the assignment"))
+			}
+		}
+		return true
+	})
+
+	return findings
+}
+
+// isPlainRef bernilai true untuk identifier atau selector tanpa pemanggilan fungsi
+func isPlainRef(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name != "_"
+	case *ast.SelectorExpr:
+		return isPlainRef(e.X)
+	}
+	return false
+}
+
+// Fungsi printf-family dan posisi argumen format-nya
+var printfFuncs = map[string]int{
+	"fmt.Printf":  0,
+	"fmt.Sprintf": 0,
+	"fmt.Errorf":  0,
+	"fmt.Fprintf": 1,
+	"log.Printf":  0,
+	"log.Fatalf":  0,
+	"log.Panicf":  0,
+}
+
+var printlnFuncs = map[string]bool{
+	"fmt.Println":  true,
+	"fmt.Print":    true,
+	"fmt.Sprintln": true,
+	"fmt.Sprint":   true,
+	"log.Println":  true,
+	"log.Print":    true,
+}
+
+// checkPrintf mencocokkan jumlah verb dengan jumlah argumen, dan mencari directive di Println
+func checkPrintf(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() {
+			return true
+		}
+		name := calleeName(call)
+
+		if idx, ok := printfFuncs[name]; ok && len(call.Args) > idx {
+			format, ok := stringLit(call.Args[idx])
+			if !ok {
+				return true
+			}
+			want, ok := countVerbs(format)
+			if got := len(call.Args) - idx - 1; ok && want != got {
+				findings = append(findings, f.finding("vet/printf", entity.SeverityError, call,
+					fmt.Sprintf("%s format %q reads %d arg(s), but call has %d", name, format, want, got), ""))
+			}
+			return true
+		}
+
+		if printlnFuncs[name] && len(call.Args) > 0 {
+			if s, ok := stringLit(call.Args[0]); ok {
+				if verb, ok := hasVerb(s); ok {
+					findings = append(findings, f.finding("vet/printf", entity.SeverityWarning, call,
+						fmt.Sprintf("%s call has possible formatting directive %s", name, verb),
+						"use "+strings.TrimSuffix(name, "ln")+"f instead"))
+				}
+			}
+		}
+		return true
+	})
+
+	return findings
+}
+
+// countVerbs menghitung argumen yang dibaca format string, ok=false jika memakai index eksplisit [n]
+func countVerbs(format string) (n int, ok bool) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return n, true
+		}
+		if format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		// Width dan precision, '*' membaca satu argumen
+		for i < len(format) && (format[i] == '.' || format[i] == '*' || isDigit(format[i])) {
+			if format[i] == '*' {
+				n++
+			}
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			return 0, false
+		}
+		if i < len(format) {
+			n++
+		}
+	}
+	return n, true
+}
+
+// hasVerb mencari directive umum seperti %d, %s, %v di string biasa
+func hasVerb(s string) (string, bool) {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '%' && strings.IndexByte("dsvqxXtfgpTwe", s[i+1]) >= 0 {
+			return s[i : i+2], true
+		}
+	}
+	return "", false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// checkUnreachable melaporkan statement setelah return, panic, goto, break atau continue
+func checkUnreachable(f *sourceFile) []entity.ReviewFinding {
+	var findings []entity.ReviewFinding
+
+	report := func(list []ast.Stmt) {
+		for i, stmt := range list[:max(len(list)-1, 0)] {
+			if !terminates(stmt) {
+				continue
+			}
+			next := list[i+1]
+			if _, ok := next.(*ast.LabeledStmt); ok {
+				continue
+			}
+			if _, ok := next.(*ast.EmptyStmt); ok {
+				continue
+			}
+			findings = append(findings, f.finding("vet/unreachable", entity.SeverityWarning, next, "unreachable code", "remove the unreachable code"))
+			return
+		}
+	}
+
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			report(n.List)
+		case *ast.CaseClause:
+			report(n.Body)
+		case *ast.CommClause:
+			report(n.Body)
+		}
+		return true
+	})
+
+	return findings
+}
+
+func terminates(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok != token.FALLTHROUGH
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		return ok && calleeName(call) == "panic"
+	}
+	return false
+}
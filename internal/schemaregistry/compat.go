@@ -0,0 +1,45 @@
+package schemaregistry
+
+import "fmt"
+
+// CheckCompatible memastikan perubahan dari prev ke next kompatibel dua arah (FULL):
+// consumer lama bisa membaca data baru dan consumer baru bisa membaca data lama.
+//   - field baru dan field yang dihapus wajib punya default
+//   - field yang sama tidak boleh berubah tipe atau field-id
+//   - field-id milik field yang dihapus tidak boleh dipakai ulang
+func CheckCompatible(prev, next *Schema) error {
+	if prev.FullName() != next.FullName() {
+		return fmt.Errorf("schema name changed from %s to %s", prev.FullName(), next.FullName())
+	}
+
+	prevIDs := make(map[int]string, len(prev.Fields))
+	for _, f := range prev.Fields {
+		prevIDs[f.FieldID] = f.Name
+	}
+
+	for _, nf := range next.Fields {
+		pf, ok := prev.Field(nf.Name)
+		if !ok {
+			if nf.Default == nil {
+				return fmt.Errorf("%s: new field %s must have a default", next.FullName(), nf.Name)
+			}
+			if owner, used := prevIDs[nf.FieldID]; used {
+				return fmt.Errorf("%s: field-id %d of %s was used by %s", next.FullName(), nf.FieldID, nf.Name, owner)
+			}
+			continue
+		}
+		if pf.Type != nf.Type {
+			return fmt.Errorf("%s: field %s changed type from %s to %s", next.FullName(), nf.Name, pf.Type, nf.Type)
+		}
+		if pf.FieldID != nf.FieldID {
+			return fmt.Errorf("%s: field %s changed field-id from %d to %d", next.FullName(), nf.Name, pf.FieldID, nf.FieldID)
+		}
+	}
+
+	for _, pf := range prev.Fields {
+		if _, ok := next.Field(pf.Name); !ok && pf.Default == nil {
+			return fmt.Errorf("%s: removed field %s had no default", next.FullName(), pf.Name)
+		}
+	}
+	return nil
+}
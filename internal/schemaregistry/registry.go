@@ -0,0 +1,142 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Registry menyimpan setiap versi schema sebagai file <dir>/<subject>/<versi>.avsc
+type Registry struct {
+	dir      string
+	subjects map[string][]*Schema
+	byID     map[uint32]*Schema
+}
+
+// Open memuat semua schema di dir. Dir yang belum ada dianggap registry kosong.
+func Open(dir string) (*Registry, error) {
+	r := &Registry{
+		dir:      dir,
+		subjects: make(map[string][]*Schema),
+		byID:     make(map[uint32]*Schema),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		subject := entry.Name()
+		files, err := filepath.Glob(filepath.Join(dir, subject, "*.avsc"))
+		if err != nil {
+			return nil, err
+		}
+
+		versions := make(map[int]*Schema, len(files))
+		for _, file := range files {
+			version, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".avsc"))
+			if err != nil {
+				return nil, fmt.Errorf("registry file %s: version must be a number", file)
+			}
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			s, err := Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("registry file %s: %w", file, err)
+			}
+			versions[version] = s
+		}
+
+		for v := 1; v <= len(versions); v++ {
+			s, ok := versions[v]
+			if !ok {
+				return nil, fmt.Errorf("registry subject %s: missing version %d", subject, v)
+			}
+			r.subjects[subject] = append(r.subjects[subject], s)
+			r.byID[s.Fingerprint()] = s
+		}
+	}
+	return r, nil
+}
+
+// Latest mengembalikan versi terakhir sebuah subject
+func (r *Registry) Latest(subject string) (*Schema, int, bool) {
+	versions := r.subjects[subject]
+	if len(versions) == 0 {
+		return nil, 0, false
+	}
+	return versions[len(versions)-1], len(versions), true
+}
+
+// ByID mencari schema berdasarkan fingerprint (schema ID di payload Avro)
+func (r *Registry) ByID(id uint32) (*Schema, bool) {
+	s, ok := r.byID[id]
+	return s, ok
+}
+
+func (r *Registry) Subjects() []string {
+	subjects := make([]string, 0, len(r.subjects))
+	for subject := range r.subjects {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// Verify dipanggil saat startup: schema yang dikompilasi ke binary harus sudah
+// terdaftar dan kompatibel dengan versi terakhir di registry.
+func (r *Registry) Verify(s *Schema) error {
+	subject := s.FullName()
+	latest, version, ok := r.Latest(subject)
+	if !ok {
+		return fmt.Errorf("schema %s is not registered in %s", subject, r.dir)
+	}
+	if _, registered := r.byID[s.Fingerprint()]; !registered {
+		return fmt.Errorf("schema %s does not match any registered version, run go generate ./internal/event", subject)
+	}
+	if err := CheckCompatible(latest, s); err != nil {
+		return fmt.Errorf("schema %s is incompatible with registered v%d: %w", subject, version, err)
+	}
+	return nil
+}
+
+// Register menyimpan schema sebagai versi baru jika berbeda dari versi terakhir.
+// Schema yang tidak kompatibel ditolak.
+func (r *Registry) Register(s *Schema) (int, error) {
+	subject := s.FullName()
+	latest, version, ok := r.Latest(subject)
+	if ok {
+		if latest.Canonical() == s.Canonical() {
+			return version, nil
+		}
+		if err := CheckCompatible(latest, s); err != nil {
+			return 0, err
+		}
+	}
+
+	version++
+	dir := filepath.Join(r.dir, subject)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	file := filepath.Join(dir, strconv.Itoa(version)+".avsc")
+	if err := os.WriteFile(file, []byte(s.Canonical()+"\n"), 0o644); err != nil {
+		return 0, err
+	}
+
+	r.subjects[subject] = append(r.subjects[subject], s)
+	r.byID[s.Fingerprint()] = s
+	return version, nil
+}
@@ -0,0 +1,98 @@
+package schemaregistry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const userV1 = `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+	{"name":"id","type":"int","default":0,"field-id":1},
+	{"name":"name","type":"string","default":"","field-id":2}]}`
+
+func mustParse(t *testing.T, raw string) *Schema {
+	t.Helper()
+	s, err := Parse([]byte(raw))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return s
+}
+
+func TestRegisterRejectsIncompatibleSchema(t *testing.T) {
+	tests := map[string]string{
+		"new field without default": `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+			{"name":"id","type":"int","default":0,"field-id":1},
+			{"name":"name","type":"string","default":"","field-id":2},
+			{"name":"email","type":"string","field-id":3}]}`,
+		"changed type": `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+			{"name":"id","type":"long","default":0,"field-id":1},
+			{"name":"name","type":"string","default":"","field-id":2}]}`,
+		"changed field-id": `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+			{"name":"id","type":"int","default":0,"field-id":1},
+			{"name":"name","type":"string","default":"","field-id":5}]}`,
+		"reused field-id": `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+			{"name":"id","type":"int","default":0,"field-id":1},
+			{"name":"email","type":"string","default":"","field-id":2}]}`,
+	}
+
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := Open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v, err := r.Register(mustParse(t, userV1)); err != nil || v != 1 {
+				t.Fatalf("Register v1 = %d, %v", v, err)
+			}
+
+			if _, err := r.Register(mustParse(t, raw)); err == nil {
+				t.Fatal("Register succeeded, want incompatible schema error")
+			}
+			if _, err := os.Stat(filepath.Join(dir, "gocrud.events.UserData", "2.avsc")); !os.IsNotExist(err) {
+				t.Errorf("incompatible schema was written to the registry (stat err %v)", err)
+			}
+		})
+	}
+}
+
+func TestRegisterCompatibleSchema(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := mustParse(t, userV1)
+	if _, err := r.Register(v1); err != nil {
+		t.Fatal(err)
+	}
+	// Schema yang sama tidak membuat versi baru
+	if v, err := r.Register(v1); err != nil || v != 1 {
+		t.Fatalf("Register same schema = %d, %v, want 1", v, err)
+	}
+
+	v2 := mustParse(t, `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+		{"name":"id","type":"int","default":0,"field-id":1},
+		{"name":"name","type":"string","default":"","field-id":2},
+		{"name":"email","type":"string","default":"","field-id":3}]}`)
+	if v, err := r.Register(v2); err != nil || v != 2 {
+		t.Fatalf("Register v2 = %d, %v, want 2", v, err)
+	}
+
+	// Registry yang dibuka ulang mengenali kedua versi dan memverifikasi versi terakhir
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.ByID(v1.Fingerprint()); !ok {
+		t.Error("v1 not found by fingerprint after reopen")
+	}
+	if err := reopened.Verify(v2); err != nil {
+		t.Errorf("Verify v2: %v", err)
+	}
+	if err := reopened.Verify(mustParse(t, `{"type":"record","name":"UserData","namespace":"gocrud.events","fields":[
+		{"name":"id","type":"int","default":0,"field-id":1}]}`)); err == nil {
+		t.Error("Verify of an unregistered schema succeeded, want error")
+	}
+}
@@ -0,0 +1,177 @@
+// Package schemaregistry adalah schema registry berbasis file untuk payload event.
+// Schema ditulis dalam format Avro (record dengan field primitif) dan setiap field
+// punya "field-id" yang dipakai sebagai nomor field Protobuf.
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+)
+
+// Tipe field yang didukung
+const (
+	TypeInt     = "int"
+	TypeLong    = "long"
+	TypeString  = "string"
+	TypeBoolean = "boolean"
+	TypeDouble  = "double"
+)
+
+type Field struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Doc     string          `json:"doc,omitempty"`
+	Default json.RawMessage `json:"default,omitempty"`
+	FieldID int             `json:"field-id"`
+}
+
+type Schema struct {
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	Doc       string  `json:"doc,omitempty"`
+	Fields    []Field `json:"fields"`
+}
+
+// Parse membaca dan memvalidasi schema Avro
+func Parse(raw []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if s.Type != "record" {
+		return nil, fmt.Errorf("schema %s: only record schemas are supported", s.Name)
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf("schema name is required")
+	}
+
+	names := make(map[string]bool, len(s.Fields))
+	ids := make(map[int]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		switch f.Type {
+		case TypeInt, TypeLong, TypeString, TypeBoolean, TypeDouble:
+		default:
+			return nil, fmt.Errorf("schema %s: field %s has unsupported type %q", s.Name, f.Name, f.Type)
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("schema %s: duplicate field %s", s.Name, f.Name)
+		}
+		if f.FieldID <= 0 || ids[f.FieldID] {
+			return nil, fmt.Errorf("schema %s: field %s needs a unique positive field-id", s.Name, f.Name)
+		}
+		if f.Default != nil {
+			if _, err := f.DefaultValue(); err != nil {
+				return nil, fmt.Errorf("schema %s: field %s: %w", s.Name, f.Name, err)
+			}
+		}
+		names[f.Name] = true
+		ids[f.FieldID] = true
+	}
+	return &s, nil
+}
+
+// FullName adalah nama subject di registry, contoh gocrud.events.UserData
+func (s *Schema) FullName() string {
+	if s.Namespace == "" {
+		return s.Name
+	}
+	return s.Namespace + "." + s.Name
+}
+
+// Canonical mengembalikan JSON schema tanpa doc, dipakai untuk fingerprint
+func (s *Schema) Canonical() string {
+	c := Schema{Type: s.Type, Name: s.Name, Namespace: s.Namespace}
+	for _, f := range s.Fields {
+		f.Doc = ""
+		c.Fields = append(c.Fields, f)
+	}
+	raw, _ := json.Marshal(c)
+	return string(raw)
+}
+
+// Fingerprint dipakai sebagai schema ID di payload Avro
+func (s *Schema) Fingerprint() uint32 {
+	return crc32.ChecksumIEEE([]byte(s.Canonical()))
+}
+
+func (s *Schema) Field(name string) (*Field, bool) {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// DefaultValue mengembalikan default field, atau zero value tipe jika tidak ada default
+func (f *Field) DefaultValue() (interface{}, error) {
+	if f.Default == nil {
+		return ZeroValue(f.Type), nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(f.Default, &v); err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+	return Coerce(f.Type, v)
+}
+
+func ZeroValue(fieldType string) interface{} {
+	switch fieldType {
+	case TypeInt, TypeLong:
+		return int64(0)
+	case TypeBoolean:
+		return false
+	case TypeDouble:
+		return float64(0)
+	default:
+		return ""
+	}
+}
+
+// Coerce mengubah nilai hasil decode JSON ke tipe Go untuk tipe field.
+// Nilai dengan tipe yang salah menghasilkan error, bukan zero value.
+func Coerce(fieldType string, v interface{}) (interface{}, error) {
+	switch fieldType {
+	case TypeInt, TypeLong:
+		switch n := v.(type) {
+		case json.Number:
+			i, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("expected %s, got %s", fieldType, n)
+			}
+			return checkIntRange(fieldType, i)
+		case float64:
+			if n != float64(int64(n)) {
+				return nil, fmt.Errorf("expected %s, got %v", fieldType, n)
+			}
+			return checkIntRange(fieldType, int64(n))
+		case int64:
+			return checkIntRange(fieldType, n)
+		}
+	case TypeDouble:
+		switch n := v.(type) {
+		case json.Number:
+			return n.Float64()
+		case float64:
+			return n, nil
+		}
+	case TypeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case TypeString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %T", fieldType, v)
+}
+
+func checkIntRange(fieldType string, i int64) (interface{}, error) {
+	if fieldType == TypeInt && (i < -1<<31 || i > 1<<31-1) {
+		return nil, fmt.Errorf("value %d overflows int", i)
+	}
+	return i, nil
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Nama analyzer (dan rule temuan) untuk komentar model
+	aiAnalyzerName = "ai-review"
+
+	defaultAIChunkTokens   = 1500
+	defaultAIReviewTokens  = 100_000
+	defaultAIMonthlyTokens = 1_000_000
+
+	// Review AI untuk satu run dihentikan setelah sekian kegagalan endpoint berturut-turut
+	maxAIConsecutiveFailures = 3
+
+	// Baris konteks di sekitar perubahan yang ikut dikirim pada review incremental
+	aiDiffContextLines = 3
+)
+
+// ErrAIDisabled dikembalikan jika aplikasi berjalan tanpa AIReviewer
+var ErrAIDisabled = errors.New("AI review is not configured")
+
+// AIReviewConfig mengatur budget token review AI
+type AIReviewConfig struct {
+	// ChunkTokens adalah perkiraan token kode maksimum per hunk, file lebih besar dipotong
+	ChunkTokens int
+	// ReviewTokens adalah budget token untuk satu review run
+	ReviewTokens int
+	// MonthlyQuota adalah kuota token per user per bulan, <= 0 berarti tanpa batas
+	MonthlyQuota int64
+}
+
+// AIReviewConfigFromEnv membaca AI_CHUNK_TOKENS (default 1500), AI_REVIEW_TOKEN_BUDGET
+// (default 100000) dan AI_MONTHLY_TOKEN_QUOTA (default 1000000, 0 berarti tanpa batas)
+func AIReviewConfigFromEnv() AIReviewConfig {
+	cfg := AIReviewConfig{
+		ChunkTokens:  defaultAIChunkTokens,
+		ReviewTokens: defaultAIReviewTokens,
+		MonthlyQuota: defaultAIMonthlyTokens,
+	}
+	if n, err := strconv.Atoi(os.Getenv("AI_CHUNK_TOKENS")); err == nil && n > 0 {
+		cfg.ChunkTokens = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("AI_REVIEW_TOKEN_BUDGET")); err == nil && n > 0 {
+		cfg.ReviewTokens = n
+	}
+	if n, err := strconv.ParseInt(os.Getenv("AI_MONTHLY_TOKEN_QUOTA"), 10, 64); err == nil && n >= 0 {
+		cfg.MonthlyQuota = n
+	}
+	return cfg
+}
+
+// AIUsage adalah pemakaian token AI user pada bulan berjalan
+type AIUsage struct {
+	UserID int    `json:"user_id"`
+	Month  string `json:"month"`
+	Used   int64  `json:"tokens_used"`
+	// Quota 0 berarti tanpa batas, Remaining lalu tidak diisi
+	Quota     int64  `json:"quota"`
+	Remaining *int64 `json:"remaining,omitempty"`
+}
+
+// usageMonth mengembalikan tanggal 1 bulan t (UTC), kunci kuota bulanan
+func usageMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// aiAnalyzer mengirim file ke AIReviewer per hunk dan mengubah komentarnya menjadi temuan.
+// Dibuat per run karena menyimpan budget run dan user pemilik kuota; engine memanggil
+// Analyze per direktori secara berurutan, jadi state-nya tidak perlu dikunci.
+type aiAnalyzer struct {
+	reviewer port.AIReviewer
+	usage    repository.AIUsageRepository
+	cfg      AIReviewConfig
+	userID   int
+
+	used     int
+	failures int
+	// stopped berisi alasan review AI dihentikan untuk sisa run
+	stopped string
+}
+
+func newAIAnalyzer(reviewer port.AIReviewer, usage repository.AIUsageRepository, cfg AIReviewConfig, userID int) *aiAnalyzer {
+	return &aiAnalyzer{reviewer: reviewer, usage: usage, cfg: cfg, userID: userID}
+}
+
+func (a *aiAnalyzer) Name() string {
+	return aiAnalyzerName
+}
+
+func (a *aiAnalyzer) Languages() []string {
+	return []string{port.AnyLanguage}
+}
+
+func (a *aiAnalyzer) Analyze(ctx context.Context, ws *port.AnalysisWorkspace) ([]entity.ReviewFinding, error) {
+	var findings []entity.ReviewFinding
+	for _, sf := range ws.Files {
+		for _, hunk := range aiHunks(sf, ws.Diff, a.cfg.ChunkTokens) {
+			if a.stopped != "" {
+				return findings, nil
+			}
+			hunkFindings, err := a.reviewHunk(ctx, hunk)
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, hunkFindings...)
+		}
+	}
+	return findings, nil
+}
+
+// reviewHunk memesan token dari kuota bulanan, mengirim hunk, lalu mengoreksi pemakaian
+// dengan token yang benar-benar terpakai. Endpoint yang gagal tidak menggagalkan review.
+func (a *aiAnalyzer) reviewHunk(ctx context.Context, hunk port.AIHunk) ([]entity.ReviewFinding, error) {
+	estimate := a.reviewer.EstimateTokens(hunk)
+	if a.used+estimate > a.cfg.ReviewTokens {
+		a.stop(fmt.Sprintf("review token budget of %d exhausted", a.cfg.ReviewTokens))
+		return nil, nil
+	}
+
+	month := usageMonth(time.Now())
+	ok, err := a.usage.ReserveAITokens(ctx, a.userID, month, int64(estimate), a.cfg.MonthlyQuota)
+	if err != nil {
+		return nil, fmt.Errorf("reserve AI tokens: %w", err)
+	}
+	if !ok {
+		a.stop(fmt.Sprintf("monthly token quota of %d exhausted for user %d", a.cfg.MonthlyQuota, a.userID))
+		return nil, nil
+	}
+
+	result, err := a.reviewer.Review(ctx, hunk)
+	if err != nil {
+		a.adjustUsage(ctx, month, -int64(estimate))
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		a.failures++
+		log.Printf("⚠️ AI review %s baris %d-%d gagal (%s): %v", hunk.File, hunk.StartLine, hunk.EndLine(), a.reviewer.Name(), err)
+		if a.failures >= maxAIConsecutiveFailures {
+			a.stop(fmt.Sprintf("%d consecutive AI endpoint failures", a.failures))
+		}
+		return nil, nil
+	}
+	a.failures = 0
+	a.used += result.Tokens
+	a.adjustUsage(ctx, month, int64(result.Tokens-estimate))
+
+	findings := make([]entity.ReviewFinding, 0, len(result.Comments))
+	for _, c := range result.Comments {
+		if f, ok := aiFinding(hunk, c); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// adjustUsage tetap dicatat walaupun review dibatalkan, token reservasi sudah terpakai di kuota
+func (a *aiAnalyzer) adjustUsage(ctx context.Context, month time.Time, delta int64) {
+	if delta == 0 {
+		return
+	}
+	if err := a.usage.AddAITokens(context.WithoutCancel(ctx), a.userID, month, delta); err != nil {
+		log.Printf("⚠️ Gagal mencatat pemakaian token AI user %d: %v", a.userID, err)
+	}
+}
+
+func (a *aiAnalyzer) stop(reason string) {
+	a.stopped = reason
+	log.Printf("⚠️ AI review dihentikan: %s", reason)
+}
+
+// aiFinding mengubah komentar model menjadi temuan. Komentar untuk baris di luar hunk
+// dibuang karena model kadang mengarang nomor baris.
+func aiFinding(hunk port.AIHunk, c port.AIComment) (entity.ReviewFinding, bool) {
+	if c.Line < hunk.StartLine || c.Line > hunk.EndLine() {
+		return entity.ReviewFinding{}, false
+	}
+	end := min(max(c.EndLine, c.Line), hunk.EndLine())
+	return entity.ReviewFinding{
+		RuleID:       aiAnalyzerName,
+		Severity:     c.Severity,
+		File:         hunk.File,
+		StartLine:    c.Line,
+		StartColumn:  1,
+		EndLine:      end,
+		EndColumn:    utf8.RuneCountInString(hunk.Lines[end-hunk.StartLine]) + 1,
+		Message:      c.Message,
+		SuggestedFix: c.Suggestion,
+	}, true
+}
+
+// estimateTokens memperkirakan token teks, sekitar 4 karakter per token
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// aiHunks memilih bagian file yang dikirim ke model: seluruh file pada review penuh, atau
+// baris yang berubah beserta beberapa baris konteks pada review incremental.
+// Bagian yang terlalu besar dipotong menjadi beberapa hunk.
+func aiHunks(sf *port.SourceFile, diff *port.Diff, maxTokens int) []port.AIHunk {
+	src := strings.ReplaceAll(string(sf.Src), "\r\n", "\n")
+	src = strings.TrimRight(src, "\n")
+	if strings.TrimSpace(src) == "" {
+		return nil
+	}
+	lines := strings.Split(src, "\n")
+	if diff == nil {
+		return chunkLines(sf, lines, 0, len(lines), maxTokens)
+	}
+
+	var hunks []port.AIHunk
+	start, end := -1, -1
+	for _, r := range diff.Files[sf.Path] {
+		// Rentang 0-based [from, to) dengan konteks, digabung jika berdempetan
+		from := max(r.Start-1-aiDiffContextLines, 0)
+		to := min(r.End+aiDiffContextLines, len(lines))
+		if from >= to {
+			continue
+		}
+		if start >= 0 && from <= end {
+			end = max(end, to)
+			continue
+		}
+		if start >= 0 {
+			hunks = append(hunks, chunkLines(sf, lines, start, end, maxTokens)...)
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		hunks = append(hunks, chunkLines(sf, lines, start, end, maxTokens)...)
+	}
+	return hunks
+}
+
+// chunkLines memotong lines[from:to] menjadi hunk berukuran paling banyak maxTokens (perkiraan).
+// Potongan diusahakan jatuh di baris kosong supaya fungsi tidak terbelah di tengah.
+func chunkLines(sf *port.SourceFile, lines []string, from, to, maxTokens int) []port.AIHunk {
+	// Nomor baris yang ditambahkan ke prompt ikut dihitung
+	cost := func(line string) int { return estimateTokens(line) + 2 }
+	hunk := func(start, end int) port.AIHunk {
+		return port.AIHunk{File: sf.Path, Language: sf.Language, StartLine: start + 1, Lines: lines[start:end]}
+	}
+
+	var hunks []port.AIHunk
+	start, size, lastBlank := from, 0, -1
+	for i := from; i < to; i++ {
+		if size+cost(lines[i]) > maxTokens && i > start {
+			cut := i
+			if lastBlank > start+(i-start)/2 {
+				cut = lastBlank + 1
+			}
+			hunks = append(hunks, hunk(start, cut))
+
+			start, size, lastBlank = cut, 0, -1
+			for _, carried := range lines[cut:i] {
+				size += cost(carried)
+			}
+		}
+		size += cost(lines[i])
+		if strings.TrimSpace(lines[i]) == "" {
+			lastBlank = i
+		}
+	}
+	return append(hunks, hunk(start, to))
+}
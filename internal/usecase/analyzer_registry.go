@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-crud/internal/usecase/port"
+	"sync"
+)
+
+var (
+	// ErrUnknownAnalyzer dikembalikan jika nama analyzer tidak terdaftar
+	ErrUnknownAnalyzer = errors.New("unknown analyzer")
+	// ErrDuplicateAnalyzer dikembalikan saat mendaftarkan nama yang sudah dipakai
+	ErrDuplicateAnalyzer = errors.New("analyzer already registered")
+)
+
+// AnalyzerInfo adalah analyzer yang terdaftar beserta status aktifnya untuk satu repository
+type AnalyzerInfo struct {
+	Name      string   `json:"name"`
+	Languages []string `json:"languages"`
+	Enabled   bool     `json:"enabled"`
+}
+
+// AnalyzerRegistry berisi semua analyzer yang bisa dipakai code review, urut sesuai pendaftaran.
+// Analyzer didaftarkan saat start, repository memilih analyzer yang aktif berdasarkan nama.
+type AnalyzerRegistry struct {
+	mu        sync.RWMutex
+	analyzers map[string]port.Analyzer
+	order     []string
+}
+
+func NewAnalyzerRegistry() *AnalyzerRegistry {
+	return &AnalyzerRegistry{analyzers: make(map[string]port.Analyzer)}
+}
+
+func (r *AnalyzerRegistry) Register(a port.Analyzer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.analyzers[a.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateAnalyzer, a.Name())
+	}
+	r.analyzers[a.Name()] = a
+	r.order = append(r.order, a.Name())
+	return nil
+}
+
+func (r *AnalyzerRegistry) Get(name string) (port.Analyzer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.analyzers[name]
+	return a, ok
+}
+
+// All mengembalikan semua analyzer terdaftar
+func (r *AnalyzerRegistry) All() []port.Analyzer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]port.Analyzer, 0, len(r.order))
+	for _, name := range r.order {
+		all = append(all, r.analyzers[name])
+	}
+	return all
+}
+
+// Validate memastikan semua nama terdaftar
+func (r *AnalyzerRegistry) Validate(names []string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, name := range names {
+		if _, ok := r.analyzers[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownAnalyzer, name)
+		}
+	}
+	return nil
+}
+
+// Enabled mengembalikan analyzer untuk daftar nama (urut pendaftaran), nil berarti semua.
+// Nama yang sudah tidak terdaftar diabaikan.
+func (r *AnalyzerRegistry) Enabled(names []string) []port.Analyzer {
+	if names == nil {
+		return r.All()
+	}
+
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[name] = true
+	}
+	var enabled []port.Analyzer
+	for _, a := range r.All() {
+		if want[a.Name()] {
+			enabled = append(enabled, a)
+		}
+	}
+	return enabled
+}
+
+// Info mengembalikan semua analyzer dengan status aktif sesuai daftar nama (nil berarti semua)
+func (r *AnalyzerRegistry) Info(names []string) []AnalyzerInfo {
+	enabled := map[string]bool{}
+	for _, a := range r.Enabled(names) {
+		enabled[a.Name()] = true
+	}
+
+	all := r.All()
+	infos := make([]AnalyzerInfo, 0, len(all))
+	for _, a := range all {
+		infos = append(infos, AnalyzerInfo{Name: a.Name(), Languages: a.Languages(), Enabled: enabled[a.Name()]})
+	}
+	return infos
+}
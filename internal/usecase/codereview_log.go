@@ -1,117 +1,608 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"go-crud/internal/entity"
 	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
 	"log"
-	"sync"
-	"sync/atomic"
+	"path"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Checkpoint tidak disimpan lebih sering dari ini
+	reviewCheckpointInterval = 5 * time.Second
+	// Event progress n/m file tidak dikirim lebih sering dari ini
+	reviewProgressInterval = 500 * time.Millisecond
+)
+
+var (
+	// ErrRepositoryNotFound dikembalikan jika repository yang akan di-review tidak ada
+	ErrRepositoryNotFound = errors.New("repository not found")
+	// ErrReviewInterrupted dipakai sebagai cause pembatalan context saat review harus berhenti
+	// tanpa menutup run (shutdown, lease hilang), run dilanjutkan dari checkpoint nanti
+	ErrReviewInterrupted = errors.New("review interrupted")
+	// ErrInvalidReviewRef dikembalikan jika base/head bukan nama ref git yang valid
+	ErrInvalidReviewRef = errors.New("invalid review ref")
 )
 
-// Gunakan variabel global untuk tracking jumlah proses code review
-var ongoingRequests int32
+// Batas panjang ref base/head
+const maxReviewRefLength = 255
+
+// getRepository membedakan repository yang tidak ada (ErrRepositoryNotFound) dari error database
+func getRepository(ctx context.Context, repos repository.RepositoryRepository, id int) (*entity.Repository, error) {
+	repo, err := repos.GetRepositoryByID(ctx, id)
+	if errors.Is(err, repository.ErrRepositoryNotFound) {
+		return nil, ErrRepositoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get repository %d: %w", id, err)
+	}
+	return repo, nil
+}
+
+// ReviewOptions dipakai worker untuk melanjutkan dan mencatat progres review
+type ReviewOptions struct {
+	// JobID dipakai sebagai stream event progres, kosong berarti tanpa event
+	JobID string
+	// Head adalah ref yang di-review, kosong berarti HEAD branch default
+	Head string
+	// Base mengaktifkan review incremental: hanya file dan baris yang berubah dari Base ke Head
+	Base string
+	// Resume berisi checkpoint dari percobaan sebelumnya yang terputus
+	Resume *entity.ReviewCheckpoint
+	// Checkpoint dipanggil berkala dengan progres terbaru
+	Checkpoint func(cp *entity.ReviewCheckpoint)
+}
 
 type ICodeReviewUsecase interface {
-	RunCodeReview(ctx context.Context, repoID int) error
-	GetReviewLogs(ctx context.Context, repoID int) ([]entity.CodeReviewLog, error)
+	// EnqueueCodeReview memasukkan job ke antrean, dijalankan oleh ReviewWorkerPool.
+	// base dan head boleh kosong; dengan base hanya perubahan base..head yang di-review.
+	EnqueueCodeReview(ctx context.Context, repoID int, base, head string) (*entity.ReviewJob, error)
+	GetReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error)
+	// ReviewJobEvents mengembalikan event progres setelah afterID, menunggu paling lama wait jika belum ada
+	ReviewJobEvents(ctx context.Context, id string, afterID string, wait time.Duration) ([]entity.ReviewEvent, error)
+	// CancelReviewJob membatalkan job queued, atau meminta worker menghentikan job running
+	CancelReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error)
+	// RetryReviewJob memasukkan ulang job failed/cancelled dengan repository yang sama
+	RetryReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error)
+	// RunCodeReview menjalankan review secara sinkron dan mencatat hasilnya sebagai review run.
+	// Review yang gagal (misalnya checkout error) tetap mengembalikan run berstatus failed tanpa error.
+	// Jika ctx dibatalkan dengan cause ErrReviewInterrupted, run dibiarkan running untuk dilanjutkan.
+	RunCodeReview(ctx context.Context, repoID int, opts ReviewOptions) (*entity.ReviewRun, error)
+	GetReviewRuns(ctx context.Context, repoID int) ([]entity.ReviewRun, error)
+	// ListAnalyzers mengembalikan semua analyzer terdaftar
+	ListAnalyzers(ctx context.Context) []AnalyzerInfo
+	// GetRepositoryAnalyzers mengembalikan analyzer beserta status aktifnya untuk repository
+	GetRepositoryAnalyzers(ctx context.Context, repoID int) ([]AnalyzerInfo, error)
+	// SetRepositoryAnalyzers mengatur analyzer yang aktif, slice kosong kembali ke semua analyzer
+	SetRepositoryAnalyzers(ctx context.Context, repoID int, names []string) ([]AnalyzerInfo, error)
+	// GetRepositoryReviewConfig mengembalikan profil review default repository, kosong jika belum diatur
+	GetRepositoryReviewConfig(ctx context.Context, repoID int) (*entity.ReviewConfig, error)
+	// SetRepositoryReviewConfig menyimpan profil review default, dipakai jika repository
+	// tidak punya .gocrud-review.yml
+	SetRepositoryReviewConfig(ctx context.Context, repoID int, cfg *entity.ReviewConfig) (*entity.ReviewConfig, error)
+	GetReviewRun(ctx context.Context, runID int64) (*entity.ReviewRun, error)
+	GetReviewFindings(ctx context.Context, runID int64) ([]entity.ReviewFinding, error)
+	// GetReviewReport mengumpulkan run, repository dan temuannya untuk diekspor
+	GetReviewReport(ctx context.Context, runID int64) (*entity.ReviewReport, error)
+	// GetAIUsage mengembalikan pemakaian token AI user bulan ini beserta kuotanya
+	GetAIUsage(ctx context.Context, userID int) (*AIUsage, error)
+}
+
+// ReviewObserver diberi tahu setiap review run selesai (completed, failed atau cancelled).
+// Review yang terputus dan akan dilanjutkan worker lain belum dianggap selesai.
+type ReviewObserver interface {
+	ReviewFinished(ctx context.Context, repo *entity.Repository, run *entity.ReviewRun)
 }
 
 type codeReviewUsecase struct {
-	repo repository.CodeReviewRepository
-	wg   *sync.WaitGroup 
+	repo     repository.CodeReviewRepository
+	repoRepo repository.RepositoryRepository
+	jobRepo  repository.ReviewJobRepository
+	events   repository.ReviewEventRepository
+	settings repository.AnalyzerSettingsRepository
+	checkout port.SourceCheckout
+	engine   port.ReviewEngine
+	registry *AnalyzerRegistry
+	// ai boleh nil, repository dengan ai_enabled lalu di-review tanpa AI
+	ai      port.AIReviewer
+	aiUsage repository.AIUsageRepository
+	aiCfg   AIReviewConfig
+	// observers menerima setiap run yang selesai, contoh webhook review.*
+	observers []ReviewObserver
 }
 
-// Modifikasi constructor untuk menerima WaitGroup
-func NewCodeReviewUsecase(repo repository.CodeReviewRepository, wg *sync.WaitGroup) ICodeReviewUsecase {
+func NewCodeReviewUsecase(repo repository.CodeReviewRepository, repoRepo repository.RepositoryRepository, jobRepo repository.ReviewJobRepository, events repository.ReviewEventRepository, settings repository.AnalyzerSettingsRepository, checkout port.SourceCheckout, engine port.ReviewEngine, registry *AnalyzerRegistry, ai port.AIReviewer, aiUsage repository.AIUsageRepository, aiCfg AIReviewConfig, observers ...ReviewObserver) ICodeReviewUsecase {
 	return &codeReviewUsecase{
-		repo: repo,
-		wg:   wg,
+		repo:     repo,
+		repoRepo: repoRepo,
+		jobRepo:  jobRepo,
+		events:   events,
+		settings: settings,
+		checkout: checkout,
+		engine:   engine,
+		registry: registry,
+		ai:       ai,
+		aiUsage:  aiUsage,
+		aiCfg:    aiCfg,
+
+		observers: observers,
 	}
 }
 
-func (uc *codeReviewUsecase) RunCodeReview(ctx context.Context, repoID int) error {
-	atomic.AddInt32(&ongoingRequests, 1)
-	uc.wg.Add(1) 
+func (uc *codeReviewUsecase) EnqueueCodeReview(ctx context.Context, repoID int, base, head string) (*entity.ReviewJob, error) {
+	for _, ref := range []string{base, head} {
+		if err := validateReviewRef(ref); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return nil, err
+	}
 
-	defer func() {
-		atomic.AddInt32(&ongoingRequests, -1)
-		uc.wg.Done()
-	}()
+	job := &entity.ReviewJob{
+		ID:           uuid.NewString(),
+		RepositoryID: repoID,
+		BaseRef:      base,
+		HeadRef:      head,
+		Status:       entity.ReviewJobQueued,
+	}
+	if err := uc.jobRepo.EnqueueJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("❌ Gagal memasukkan job code review: %w", err)
+	}
+
+	if base != "" {
+		log.Printf("📥 Job code review %s masuk antrean untuk repo %d (%s..%s)", job.ID, repoID, base, cmp.Or(head, "HEAD"))
+	} else {
+		log.Printf("📥 Job code review %s masuk antrean untuk repo %d", job.ID, repoID)
+	}
+	uc.publish(ctx, entity.ReviewEvent{JobID: job.ID, Type: entity.ReviewEventQueued})
+	return job, nil
+}
+
+// validateReviewRef menolak ref yang tidak mungkin valid di git (lihat git check-ref-format)
+// atau bisa dibaca git sebagai opsi. Ref kosong valid.
+func validateReviewRef(ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if len(ref) > maxReviewRefLength || strings.HasPrefix(ref, "-") || strings.Contains(ref, "..") ||
+		strings.ContainsAny(ref, " \\:?*[") || strings.HasSuffix(ref, ".lock") {
+		return fmt.Errorf("%w: %q", ErrInvalidReviewRef, ref)
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("%w: %q", ErrInvalidReviewRef, ref)
+		}
+	}
+	return nil
+}
+
+func (uc *codeReviewUsecase) GetReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error) {
+	return uc.jobRepo.GetJobByID(ctx, id)
+}
+
+func (uc *codeReviewUsecase) ReviewJobEvents(ctx context.Context, id string, afterID string, wait time.Duration) ([]entity.ReviewEvent, error) {
+	return uc.events.ReadReviewEvents(ctx, id, afterID, wait)
+}
+
+// publish menyimpan event progres. Gagal menyimpan event tidak menggagalkan review.
+func (uc *codeReviewUsecase) publish(ctx context.Context, ev entity.ReviewEvent) {
+	if ev.JobID == "" {
+		return
+	}
+	ev.At = time.Now()
+	if err := uc.events.AppendReviewEvent(context.WithoutCancel(ctx), &ev); err != nil {
+		log.Printf("⚠️ Gagal menyimpan event %s job %s: %v", ev.Type, ev.JobID, err)
+	}
+}
 
+func (uc *codeReviewUsecase) CancelReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error) {
+	job, err := uc.jobRepo.CancelJob(ctx, id, "cancelled via API")
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == entity.ReviewJobCancelled {
+		log.Printf("🚫 Job code review %s dibatalkan sebelum berjalan", id)
+		uc.publish(ctx, entity.ReviewEvent{JobID: id, Type: entity.ReviewEventCancelled, Message: "cancelled via API"})
+	} else {
+		log.Printf("🚫 Job code review %s diminta berhenti", id)
+	}
+	return job, nil
+}
+
+func (uc *codeReviewUsecase) RetryReviewJob(ctx context.Context, id string) (*entity.ReviewJob, error) {
+	job, err := uc.jobRepo.RetryJob(ctx, id, "retried via API")
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔁 Job code review %s masuk antrean lagi untuk repo %d", id, job.RepositoryID)
+	uc.publish(ctx, entity.ReviewEvent{JobID: id, Type: entity.ReviewEventQueued, Message: "retried via API"})
+	return job, nil
+}
+
+func (uc *codeReviewUsecase) RunCodeReview(ctx context.Context, repoID int, opts ReviewOptions) (*entity.ReviewRun, error) {
 	log.Println("🚀 Memulai code review untuk repo:", repoID)
 
-	// Simulasi kerja selama 10 detik
-	for i := 0; i < 10; i++ {
-		select {
-		case <-ctx.Done(): // Jika context dibatalkan, beri kesempatan untuk menyelesaikan
-			log.Println("⏳ Code review sedang dalam proses, menunggu hingga aman untuk keluar...")
-
-			// Tunggu sebentar untuk menyelesaikan tugas dengan aman
-			time.Sleep(2 * time.Second)
-			log.Println("⏹️ Code review dihentikan untuk repo:", repoID)
-			return ctx.Err()
-		default:
-			time.Sleep(1 * time.Second) // Simulasi kerja per detik
+	repo, err := getRepository(ctx, uc.repoRepo, repoID)
+	if err != nil {
+		log.Printf("❌ Repository %d gagal dibaca: %v", repoID, err)
+		return nil, err
+	}
+
+	run, progress := uc.resumeRun(ctx, opts.Resume)
+	if run != nil {
+		uc.publish(ctx, entity.ReviewEvent{JobID: opts.JobID, Type: entity.ReviewEventResumed, RunID: run.ID,
+			FilesDone: len(progress.cp.FilesDone), Message: fmt.Sprintf("resume #%d", run.ResumeCount)})
+	} else {
+		run = &entity.ReviewRun{
+			RepositoryID: repoID,
+			Status:       entity.ReviewRunRunning,
+			StartedAt:    time.Now(),
+		}
+		if err := uc.repo.CreateReviewRun(ctx, run); err != nil {
+			return nil, fmt.Errorf("❌ Gagal mencatat review run: %w", err)
 		}
+		progress = &reviewProgress{cp: entity.ReviewCheckpoint{RunID: run.ID}}
+		uc.publish(ctx, entity.ReviewEvent{JobID: opts.JobID, Type: entity.ReviewEventStarted, RunID: run.ID})
+	}
+	progress.save = opts.Checkpoint
+	progress.emit = func(ev entity.ReviewEvent) {
+		ev.JobID, ev.RunID = opts.JobID, run.ID
+		uc.publish(ctx, ev)
 	}
 
-	// Simpan hasil review
-	reviewLog := entity.CodeReviewLog{
-		RepositoryID: repoID,
-		ReviewResult: "Code review completed: No critical issues found",
+	findings, failure, err := uc.review(ctx, repo, run, progress, opts)
+
+	if err != nil && errors.Is(context.Cause(ctx), ErrReviewInterrupted) {
+		// Run tetap running, progres terakhir disimpan supaya worker berikutnya bisa melanjutkan
+		progress.flush()
+		log.Printf("⏸️ Code review repo %d (run %d) terputus setelah %d file: %v", repoID, run.ID, len(progress.cp.FilesDone), context.Cause(ctx))
+		progress.emit(entity.ReviewEvent{Type: entity.ReviewEventInterrupted, FilesDone: len(progress.cp.FilesDone),
+			FilesTotal: progress.total, Message: context.Cause(ctx).Error()})
+		return run, context.Cause(ctx)
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		run.Status = entity.ReviewRunCancelled
+		run.Error = ctx.Err().Error()
+		findings = nil
+		log.Println("⏹️ Code review dihentikan untuk repo:", repoID)
+	case err != nil:
+		run.Status = entity.ReviewRunFailed
+		run.Error = err.Error()
+		findings = nil
+		log.Printf("❌ Code review gagal untuk repo %d: %v", repoID, err)
+	case failure != "":
+		// Temuan tetap disimpan, run gagal karena melewati ambang fail_on
+		run.Status = entity.ReviewRunFailed
+		run.Error = failure
+		for _, f := range findings {
+			run.Summary.Add(f.Severity)
+		}
+		log.Printf("❌ Code review repo %d (run %d) gagal: %s", repoID, run.ID, failure)
+	default:
+		run.Status = entity.ReviewRunCompleted
+		for _, f := range findings {
+			run.Summary.Add(f.Severity)
+		}
+	}
+
+	// Run tetap ditandai selesai walaupun context review sudah dibatalkan
+	if err := uc.repo.FinishReviewRun(context.WithoutCancel(ctx), run, findings); err != nil {
+		progress.emit(entity.ReviewEvent{Type: entity.ReviewEventFailed, Message: err.Error()})
+		return run, fmt.Errorf("❌ Gagal menyimpan hasil code review: %w", err)
+	}
+
+	final := entity.ReviewEvent{Type: entity.ReviewEventCompleted, Summary: &run.Summary,
+		FilesDone: len(progress.cp.FilesDone), FilesTotal: progress.total, Message: run.Error}
+	switch run.Status {
+	case entity.ReviewRunFailed:
+		final.Type = entity.ReviewEventFailed
+	case entity.ReviewRunCancelled:
+		final.Type = entity.ReviewEventCancelled
+	}
+	progress.emit(final)
+	for _, o := range uc.observers {
+		o.ReviewFinished(context.WithoutCancel(ctx), repo, run)
+	}
+
+	if run.Status != entity.ReviewRunCompleted {
+		return run, ctx.Err()
 	}
 
-	err := uc.repo.InsertCodeReviewLog(ctx, &reviewLog)
+	log.Printf("✅ Code review selesai untuk repo %d (run %d): %d temuan", repoID, run.ID, run.Summary.Total)
+	return run, nil
+}
+
+// resumeRun membuka kembali run dari checkpoint. nil jika tidak ada checkpoint atau run-nya
+// sudah tidak ada, review lalu dimulai dari awal dengan run baru.
+func (uc *codeReviewUsecase) resumeRun(ctx context.Context, cp *entity.ReviewCheckpoint) (*entity.ReviewRun, *reviewProgress) {
+	if cp == nil || cp.RunID == 0 {
+		return nil, nil
+	}
+
+	run, err := uc.repo.ResumeReviewRun(ctx, cp.RunID)
 	if err != nil {
-		return fmt.Errorf("❌ Gagal menyimpan hasil code review: %w", err)
+		log.Printf("⚠️ Review run %d tidak bisa dilanjutkan, memulai dari awal: %v", cp.RunID, err)
+		return nil, nil
 	}
+	run.CommitSHA = cp.CommitSHA
+	run.BaseSHA = cp.BaseSHA
 
-	log.Println("✅ Code review selesai untuk repo:", repoID)
-	return nil
+	log.Printf("🔄 Melanjutkan review run %d untuk repo %d (resume ke-%d, %d file sudah selesai)",
+		run.ID, run.RepositoryID, run.ResumeCount, len(cp.FilesDone))
+	// Temuan dari checkpoint dipisah karena Analyze hanya mengembalikan temuan file yang belum selesai
+	return run, &reviewProgress{cp: *cp, resumed: append([]entity.ReviewFinding{}, cp.Findings...)}
 }
 
-// // Simulasi Code Review (long-running task)
-// func (uc *codeReviewUsecase) RunCodeReview(ctx context.Context, repoID int) error {
-// 	atomic.AddInt32(&ongoingRequests, 1)
-// 	uc.wg.Add(1) 
+// review melakukan checkout repository lalu menjalankan engine atas source-nya.
+// Run yang dilanjutkan memakai commit (dan base) dari checkpoint dan melewati file yang sudah selesai.
+// failure berisi alasan run gagal jika temuan melewati ambang fail_on konfigurasi review.
+func (uc *codeReviewUsecase) review(ctx context.Context, repo *entity.Repository, run *entity.ReviewRun, progress *reviewProgress, opts ReviewOptions) (findings []entity.ReviewFinding, failure string, err error) {
+	progress.emit(entity.ReviewEvent{Type: entity.ReviewEventCloning, Message: repo.URL})
+	ws, err := uc.checkout.Checkout(ctx, repo.URL, cmp.Or(run.CommitSHA, opts.Head))
+	if err != nil {
+		return nil, "", fmt.Errorf("checkout %s: %w", repo.URL, err)
+	}
+	defer ws.Cleanup()
+	run.CommitSHA = ws.CommitSHA
+	progress.cp.CommitSHA = ws.CommitSHA
 
-// 	defer func() {
-// 		atomic.AddInt32(&ongoingRequests, -1)
-// 		uc.wg.Done()
-// 	}()
+	var diff *port.Diff
+	if base := cmp.Or(run.BaseSHA, opts.Base); base != "" {
+		diff, err = uc.checkout.Diff(ctx, ws, base)
+		if err != nil {
+			return nil, "", fmt.Errorf("diff %s..%s: %w", base, ws.CommitSHA, err)
+		}
+		run.BaseSHA = diff.BaseSHA
+		progress.cp.BaseSHA = diff.BaseSHA
+		log.Printf("🔀 Review incremental repo %d (run %d): %d file berubah dari %.12s ke %.12s",
+			repo.ID, run.ID, len(diff.Files), diff.BaseSHA, diff.HeadSHA)
+	}
+	progress.flush()
 
-// 	log.Println("🚀 Memulai code review untuk repo:", repoID)
+	rules, err := uc.loadReviewRules(ctx, repo.ID, ws.Dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if rules != nil {
+		log.Printf("⚙️ Review repo %d (run %d) memakai konfigurasi dari %s", repo.ID, run.ID, rules.source)
+	}
+
+	names, err := uc.settings.GetEnabledAnalyzers(ctx, repo.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("load analyzers: %w", err)
+	}
+	analyzers := rules.analyzers(uc.registry, names)
+
+	// Komentar model ikut dianalisis per file supaya masuk checkpoint dan event progres
+	var ai *aiAnalyzer
+	if repo.AIEnabled && uc.ai != nil && !rules.disabled(aiAnalyzerName) {
+		ai = newAIAnalyzer(uc.ai, uc.aiUsage, uc.aiCfg, repo.UserID)
+		analyzers = append(analyzers, ai)
+	}
+
+	skip := make(map[string]bool, len(progress.cp.FilesDone))
+	for _, file := range progress.cp.FilesDone {
+		skip[file] = true
+	}
+
+	findings, err = uc.engine.Analyze(ctx, ws.Dir, port.AnalyzeOptions{
+		Analyzers: analyzers,
+		Skip:      skip,
+		Diff:      diff,
+		Include:   rules.filter(),
+		OnStart:   progress.start,
+		// Severity override diterapkan sebelum temuan masuk checkpoint dan event
+		OnFile: func(file string, found []entity.ReviewFinding) { progress.add(file, rules.rewrite(found)) },
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("analyze: %w", err)
+	}
+	if ai != nil {
+		log.Printf("🤖 AI review repo %d (run %d) memakai %d token dari %s", repo.ID, run.ID, ai.used, uc.ai.Name())
+	}
+
+	if len(skip) > 0 {
+		findings = append(findings, progress.resumed...)
+		sort.SliceStable(findings, func(i, j int) bool {
+			a, b := findings[i], findings[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if a.StartLine != b.StartLine {
+				return a.StartLine < b.StartLine
+			}
+			return a.StartColumn < b.StartColumn
+		})
+	}
+
+	// Ambang dihitung sebelum max_findings supaya temuan yang dipotong tetap ikut dihitung
+	findings = rules.rewrite(findings)
+	failure = rules.gate(findings)
+	findings, dropped := rules.limit(findings)
+	if dropped > 0 {
+		log.Printf("✂️ Review repo %d (run %d): %d temuan tidak disimpan karena max_findings %d",
+			repo.ID, run.ID, dropped, rules.cfg.MaxFindings)
+	}
+	return findings, failure, nil
+}
+
+// reviewProgress mengumpulkan file yang sudah selesai, menyimpannya sebagai checkpoint
+// dan mengirim event progres
+type reviewProgress struct {
+	cp      entity.ReviewCheckpoint
+	resumed []entity.ReviewFinding
+	save    func(cp *entity.ReviewCheckpoint)
+	last    time.Time
+
+	emit         func(ev entity.ReviewEvent)
+	total        int
+	pkg          string
+	lastProgress time.Time
+}
+
+func (p *reviewProgress) start(totalFiles int) {
+	p.total = totalFiles
+	p.emit(entity.ReviewEvent{Type: entity.ReviewEventProgress, FilesDone: len(p.cp.FilesDone), FilesTotal: totalFiles})
+}
+
+func (p *reviewProgress) add(file string, findings []entity.ReviewFinding) {
+	if pkg := path.Dir(file); pkg != p.pkg {
+		p.pkg = pkg
+		p.emit(entity.ReviewEvent{Type: entity.ReviewEventAnalyzing, Package: pkg})
+	}
+
+	p.cp.FilesDone = append(p.cp.FilesDone, file)
+	p.cp.Findings = append(p.cp.Findings, findings...)
+	for i := range findings {
+		p.emit(entity.ReviewEvent{Type: entity.ReviewEventFinding, Finding: &findings[i]})
+	}
+
+	done := len(p.cp.FilesDone)
+	if done == p.total || time.Since(p.lastProgress) >= reviewProgressInterval {
+		p.lastProgress = time.Now()
+		p.emit(entity.ReviewEvent{Type: entity.ReviewEventProgress, FilesDone: done, FilesTotal: p.total})
+	}
+	if time.Since(p.last) >= reviewCheckpointInterval {
+		p.flush()
+	}
+}
+
+func (p *reviewProgress) flush() {
+	if p.save == nil {
+		return
+	}
+	p.last = time.Now()
+	p.cp.UpdatedAt = p.last
+	p.save(&p.cp)
+}
+
+func (uc *codeReviewUsecase) GetReviewRuns(ctx context.Context, repoID int) ([]entity.ReviewRun, error) {
+	return uc.repo.GetReviewRunsByRepoID(ctx, repoID)
+}
+
+func (uc *codeReviewUsecase) ListAnalyzers(ctx context.Context) []AnalyzerInfo {
+	return uc.registry.Info(nil)
+}
+
+func (uc *codeReviewUsecase) GetRepositoryAnalyzers(ctx context.Context, repoID int) ([]AnalyzerInfo, error) {
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return nil, err
+	}
+	names, err := uc.settings.GetEnabledAnalyzers(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.registry.Info(names), nil
+}
+
+func (uc *codeReviewUsecase) SetRepositoryAnalyzers(ctx context.Context, repoID int, names []string) ([]AnalyzerInfo, error) {
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return nil, err
+	}
+	if err := uc.registry.Validate(names); err != nil {
+		return nil, err
+	}
+	if err := uc.settings.SetEnabledAnalyzers(ctx, repoID, names); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧩 Analyzer repo %d diubah: %v", repoID, names)
+	if len(names) == 0 {
+		names = nil
+	}
+	return uc.registry.Info(names), nil
+}
+
+func (uc *codeReviewUsecase) GetRepositoryReviewConfig(ctx context.Context, repoID int) (*entity.ReviewConfig, error) {
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return nil, err
+	}
+	cfg, err := uc.settings.GetReviewConfig(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &entity.ReviewConfig{}
+	}
+	return cfg, nil
+}
 
-// 	// Loop untuk bisa menangkap sinyal shutdown saat sleep
-// 	for i := 0; i < 10; i++ {
-// 		select {
-// 		case <-ctx.Done(): // Jika context dibatalkan, hentikan proses
-// 			log.Println("⏹️ Code review dibatalkan untuk repo:", repoID)
-// 			return ctx.Err()
-// 		default:
-// 			time.Sleep(1 * time.Second) // Tunggu per detik untuk bisa dicek
-// 		}
-// 	}
+func (uc *codeReviewUsecase) SetRepositoryReviewConfig(ctx context.Context, repoID int, cfg *entity.ReviewConfig) (*entity.ReviewConfig, error) {
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return nil, err
+	}
+	// Berbeda dengan .gocrud-review.yml, nama analyzer yang tidak dikenal langsung ditolak
+	if _, err := compileReviewConfig(cfg, uc.knownAnalyzer); err != nil {
+		return nil, err
+	}
+	if err := uc.settings.SetReviewConfig(ctx, repoID, cfg); err != nil {
+		return nil, err
+	}
 
-// 	// Hasil review (dummy result)
-// 	reviewLog := entity.CodeReviewLog{
-// 		RepositoryID: repoID,
-// 		ReviewResult: "Code review completed: No critical issues found",
-// 	}
+	log.Printf("⚙️ Profil review repo %d diubah", repoID)
+	return cfg, nil
+}
 
-// 	err := uc.repo.InsertCodeReviewLog(ctx, &reviewLog)
-// 	if err != nil {
-// 		return fmt.Errorf("❌ Gagal menyimpan hasil code review: %w", err)
-// 	}
+func (uc *codeReviewUsecase) GetReviewRun(ctx context.Context, runID int64) (*entity.ReviewRun, error) {
+	return uc.repo.GetReviewRunByID(ctx, runID)
+}
 
-// 	log.Println("✅ Code review selesai untuk repo:", repoID)
-// 	return nil
-// }
+func (uc *codeReviewUsecase) GetAIUsage(ctx context.Context, userID int) (*AIUsage, error) {
+	if uc.ai == nil {
+		return nil, ErrAIDisabled
+	}
+	month := usageMonth(time.Now())
+	used, err := uc.aiUsage.GetAITokenUsage(ctx, userID, month)
+	if err != nil {
+		return nil, err
+	}
 
+	usage := &AIUsage{UserID: userID, Month: month.Format("2006-01"), Used: used, Quota: max(uc.aiCfg.MonthlyQuota, 0)}
+	if usage.Quota > 0 {
+		remaining := max(usage.Quota-used, 0)
+		usage.Remaining = &remaining
+	}
+	return usage, nil
+}
 
-func (uc *codeReviewUsecase) GetReviewLogs(ctx context.Context, repoID int) ([]entity.CodeReviewLog, error) {
-	return uc.repo.GetCodeReviewLogsByRepoID(ctx, repoID)
+// GetReviewFindings memastikan run ada dulu supaya run tanpa temuan bisa dibedakan dari run yang tidak ada
+func (uc *codeReviewUsecase) GetReviewFindings(ctx context.Context, runID int64) ([]entity.ReviewFinding, error) {
+	if _, err := uc.repo.GetReviewRunByID(ctx, runID); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetReviewFindingsByRunID(ctx, runID)
+}
+
+func (uc *codeReviewUsecase) GetReviewReport(ctx context.Context, runID int64) (*entity.ReviewReport, error) {
+	run, err := uc.repo.GetReviewRunByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := getRepository(ctx, uc.repoRepo, run.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := uc.repo.GetReviewFindingsByRunID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ReviewReport{Repository: *repo, Run: *run, Findings: findings}, nil
 }
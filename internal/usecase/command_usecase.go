@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/repository"
+	"go-crud/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ICommandUsecase melacak status setiap write async yang dikirim lewat Kafka
+type ICommandUsecase interface {
+	SubmitCommand(ctx context.Context, cmdType string, topic string, subject string, data interface{}) (*entity.Command, error)
+	SubmitCommands(ctx context.Context, reqs []CommandRequest) ([]*entity.Command, error)
+	GetCommand(ctx context.Context, id string) (*entity.Command, error)
+	MarkSucceeded(ctx context.Context, id string, entityID int) error
+	MarkFailed(ctx context.Context, id string, cause error) error
+}
+
+type commandUsecase struct {
+	repo repository.CommandRepository
+}
+
+func NewCommandUsecase(repo repository.CommandRepository) ICommandUsecase {
+	return &commandUsecase{repo: repo}
+}
+
+// CommandRequest adalah satu command untuk SubmitCommands
+type CommandRequest struct {
+	Type    string
+	Topic   string
+	Subject string
+	Data    interface{}
+}
+
+// SubmitCommand membuat command pending dan menulis event-nya ke outbox dalam satu transaksi.
+// Pengiriman ke Kafka dilakukan oleh outbox relay; data user/repository baru berubah saat
+// consumer memproses event, hasilnya terlihat lewat status command.
+func (uc *commandUsecase) SubmitCommand(ctx context.Context, cmdType string, topic string, subject string, data interface{}) (*entity.Command, error) {
+	cmds, err := uc.SubmitCommands(ctx, []CommandRequest{{Type: cmdType, Topic: topic, Subject: subject, Data: data}})
+	if err != nil {
+		return nil, err
+	}
+	return cmds[0], nil
+}
+
+// SubmitCommands seperti SubmitCommand untuk beberapa command sekaligus: semua command dan
+// event-nya tersimpan dalam satu transaksi, atau tidak sama sekali.
+func (uc *commandUsecase) SubmitCommands(ctx context.Context, reqs []CommandRequest) ([]*entity.Command, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "CommandUsecase.SubmitCommands")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("command.count", len(reqs)))
+
+	cmds := make([]*entity.Command, 0, len(reqs))
+	msgs := make([]*entity.OutboxMessage, 0, len(reqs))
+	for _, req := range reqs {
+		cmd := &entity.Command{
+			ID:     uuid.NewString(),
+			Type:   req.Type,
+			Status: entity.CommandStatusPending,
+		}
+		span.AddEvent("command", trace.WithAttributes(
+			attribute.String("command.id", cmd.ID),
+			attribute.String("command.type", req.Type),
+			attribute.String("command.topic", req.Topic),
+		))
+
+		env, err := event.New(ctx, req.Type, req.Subject, req.Data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		env.CommandID = cmd.ID
+
+		payload, err := json.Marshal(env)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		cmds = append(cmds, cmd)
+		msgs = append(msgs, &entity.OutboxMessage{
+			Topic:     req.Topic,
+			EventType: req.Type,
+			Payload:   payload,
+		})
+	}
+
+	if err := uc.repo.CreateCommands(ctx, cmds, msgs); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return cmds, nil
+}
+
+func (uc *commandUsecase) GetCommand(ctx context.Context, id string) (*entity.Command, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "CommandUsecase.GetCommand")
+	defer span.End()
+
+	return uc.repo.GetCommandByID(ctx, id)
+}
+
+func (uc *commandUsecase) MarkSucceeded(ctx context.Context, id string, entityID int) error {
+	ctx, span := tracing.Tracer.Start(ctx, "CommandUsecase.MarkSucceeded")
+	defer span.End()
+
+	return uc.repo.UpdateCommandStatus(ctx, id, entity.CommandStatusSucceeded, &entityID, "")
+}
+
+func (uc *commandUsecase) MarkFailed(ctx context.Context, id string, cause error) error {
+	ctx, span := tracing.Tracer.Start(ctx, "CommandUsecase.MarkFailed")
+	defer span.End()
+
+	return uc.repo.UpdateCommandStatus(ctx, id, entity.CommandStatusFailed, nil, cause.Error())
+}
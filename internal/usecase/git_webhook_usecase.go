@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// Panjang minimum secret webhook (masuk dan keluar) yang diatur sendiri, secret buatan server 32 byte (64 hex)
+const minWebhookSecretLength = 16
+
+var (
+	// ErrUnknownWebhookProvider dikembalikan untuk provider di luar github, gitlab dan gitea
+	ErrUnknownWebhookProvider = errors.New("unknown git webhook provider")
+	// ErrInvalidWebhookPayload dikembalikan jika payload tidak bisa dibaca
+	ErrInvalidWebhookPayload = errors.New("invalid git webhook payload")
+	// ErrWebhookSignature dikembalikan jika tidak ada repository dengan URL payload yang
+	// secret-nya cocok. Keduanya tidak dibedakan supaya URL yang terdaftar tidak bisa ditebak.
+	ErrWebhookSignature = errors.New("webhook signature does not match any repository")
+	// ErrInvalidWebhookSecret dikembalikan jika secret yang diatur terlalu pendek
+	ErrInvalidWebhookSecret = errors.New("invalid webhook secret")
+)
+
+// GitWebhookResult adalah hasil satu webhook: job review yang dibuat, atau alasan diabaikan
+type GitWebhookResult struct {
+	Event   string             `json:"event"`
+	Ignored string             `json:"ignored,omitempty"`
+	Jobs    []entity.ReviewJob `json:"jobs,omitempty"`
+}
+
+type IGitWebhookUsecase interface {
+	// ReceiveGitWebhook memverifikasi dan membaca webhook provider, lalu memasukkan review
+	// incremental untuk setiap repository dengan URL dan secret yang cocok
+	ReceiveGitWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*GitWebhookResult, error)
+	// SetWebhookSecret mengganti secret webhook repository, secret kosong dibuatkan secara acak.
+	// Secret dikembalikan supaya bisa dipasang di provider.
+	SetWebhookSecret(ctx context.Context, repoID int, secret string) (string, error)
+}
+
+type gitWebhookUsecase struct {
+	repoRepo  repository.RepositoryRepository
+	secrets   repository.WebhookSecretRepository
+	reviews   ICodeReviewUsecase
+	providers map[string]port.GitWebhookProvider
+}
+
+func NewGitWebhookUsecase(repoRepo repository.RepositoryRepository, secrets repository.WebhookSecretRepository, reviews ICodeReviewUsecase, providers []port.GitWebhookProvider) IGitWebhookUsecase {
+	byName := make(map[string]port.GitWebhookProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &gitWebhookUsecase{repoRepo: repoRepo, secrets: secrets, reviews: reviews, providers: byName}
+}
+
+func (uc *gitWebhookUsecase) ReceiveGitWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*GitWebhookResult, error) {
+	p, ok := uc.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWebhookProvider, provider)
+	}
+	ev, err := p.Parse(header, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	repos, err := uc.verifiedRepositories(ctx, p, ev, header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GitWebhookResult{Event: ev.Summary, Ignored: ev.Ignore}
+	if ev.Ignore != "" {
+		log.Printf("🪝 Webhook %s %s diabaikan: %s", provider, ev.Summary, ev.Ignore)
+		return result, nil
+	}
+
+	// Satu repository yang gagal tidak membatalkan job repository lain yang sudah masuk antrean
+	var lastErr error
+	for _, repo := range repos {
+		job, err := uc.reviews.EnqueueCodeReview(ctx, repo.ID, ev.Base, ev.Head)
+		if err != nil {
+			log.Printf("❌ Webhook %s %s gagal memicu review repo %d: %v", provider, ev.Summary, repo.ID, err)
+			lastErr = fmt.Errorf("enqueue review for repository %d: %w", repo.ID, err)
+			continue
+		}
+		log.Printf("🪝 Webhook %s %s memicu review repo %d (job %s)", provider, ev.Summary, repo.ID, job.ID)
+		result.Jobs = append(result.Jobs, *job)
+	}
+	if len(result.Jobs) == 0 {
+		return nil, lastErr
+	}
+	return result, nil
+}
+
+// verifiedRepositories mencari repository dengan URL yang sama dengan payload (lewat url_key)
+// dan secret yang cocok dengan tanda tangan request. Satu URL bisa didaftarkan beberapa user.
+func (uc *gitWebhookUsecase) verifiedRepositories(ctx context.Context, p port.GitWebhookProvider, ev *port.GitEvent, header http.Header, body []byte) ([]entity.Repository, error) {
+	keys := make([]string, 0, len(ev.CloneURLs))
+	for _, u := range ev.CloneURLs {
+		if key := entity.NormalizeGitURL(u); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	var verified []entity.Repository
+	if len(keys) > 0 {
+		candidates, err := uc.repoRepo.GetRepositoriesByURLKeys(ctx, keys)
+		if err != nil {
+			return nil, fmt.Errorf("load repositories: %w", err)
+		}
+		ids := make([]int, len(candidates))
+		for i, repo := range candidates {
+			ids[i] = repo.ID
+		}
+		secrets, err := uc.secrets.GetWebhookSecrets(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("load webhook secrets: %w", err)
+		}
+
+		for _, repo := range candidates {
+			if secret := secrets[repo.ID]; secret != "" && p.Verify(header, body, secret) {
+				verified = append(verified, repo)
+			}
+		}
+	}
+	if len(verified) == 0 {
+		log.Printf("⚠️ Webhook %s %s ditolak: tidak ada repository dengan URL %v dan secret yang cocok", p.Name(), ev.Summary, ev.CloneURLs)
+		return nil, ErrWebhookSignature
+	}
+	return verified, nil
+}
+
+func (uc *gitWebhookUsecase) SetWebhookSecret(ctx context.Context, repoID int, secret string) (string, error) {
+	if _, err := getRepository(ctx, uc.repoRepo, repoID); err != nil {
+		return "", err
+	}
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return "", err
+		}
+	}
+	if len(secret) < minWebhookSecretLength {
+		return "", fmt.Errorf("%w: must be at least %d characters", ErrInvalidWebhookSecret, minWebhookSecretLength)
+	}
+
+	if err := uc.secrets.SetWebhookSecret(ctx, repoID, secret); err != nil {
+		return "", err
+	}
+	log.Printf("🔑 Secret webhook repo %d diganti", repoID)
+	return secret, nil
+}
+
+// generateWebhookSecret membuat secret acak 32 byte (64 hex) untuk webhook masuk maupun keluar
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
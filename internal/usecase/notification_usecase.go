@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPublicBaseURL  = "http://localhost:8080"
+	defaultNotifyTimezone = "UTC"
+	// defaultNotifyChannel dipakai jika user tidak memilih channel: notifikasi review bersifat
+	// pribadi, jadi tidak ikut routing topic ke channel bersama
+	defaultNotifyChannel = "email"
+	// reviewNotifyTimeout membatasi pengiriman notifikasi review supaya worker review tidak tertahan
+	reviewNotifyTimeout = 30 * time.Second
+)
+
+var (
+	// ErrUserNotFound dikembalikan jika user pemilik preferensi tidak ada
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidNotificationPreferences dikembalikan untuk channel, severity, jam tenang atau timezone yang tidak valid
+	ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+)
+
+// notifySeverityRank mengurutkan severity notifikasi untuk min_severity
+var notifySeverityRank = map[string]int{
+	port.NotifyInfo:     0,
+	port.NotifyWarning:  1,
+	port.NotifyCritical: 2,
+}
+
+// PublicBaseURLFromEnv membaca PUBLIC_BASE_URL (default http://localhost:8080), dipakai untuk link di notifikasi
+func PublicBaseURLFromEnv() string {
+	base := os.Getenv("PUBLIC_BASE_URL")
+	if base == "" {
+		base = defaultPublicBaseURL
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// NotificationPreferencesInput adalah body PUT /users/{id}/notification-preferences.
+// Seluruh preferensi diganti; min_severity kosong berarti info, timezone kosong berarti UTC.
+type NotificationPreferencesInput struct {
+	Channels        []string `json:"channels"`
+	MinSeverity     string   `json:"min_severity"`
+	QuietHoursStart string   `json:"quiet_hours_start"`
+	QuietHoursEnd   string   `json:"quiet_hours_end"`
+	Timezone        string   `json:"timezone"`
+}
+
+// INotificationUsecase mengirim notifikasi review selesai ke pemilik repository sesuai preferensinya
+type INotificationUsecase interface {
+	ReviewObserver
+	// GetNotificationPreferences mengembalikan preferensi default jika user belum menyimpannya
+	GetNotificationPreferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, userID int, input NotificationPreferencesInput) (*entity.NotificationPreferences, error)
+}
+
+type notificationUsecase struct {
+	prefs    repository.NotificationPreferenceRepository
+	userRepo repository.UserRepository
+	notifier port.ChannelNotifier
+	// baseURL dipakai untuk link laporan review
+	baseURL string
+	// now diganti di test untuk memeriksa jam tenang
+	now func() time.Time
+}
+
+func NewNotificationUsecase(prefs repository.NotificationPreferenceRepository, userRepo repository.UserRepository, notifier port.ChannelNotifier, baseURL string) INotificationUsecase {
+	return &notificationUsecase{prefs: prefs, userRepo: userRepo, notifier: notifier, baseURL: baseURL, now: time.Now}
+}
+
+func (uc *notificationUsecase) GetNotificationPreferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error) {
+	if err := uc.userExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	return uc.preferences(ctx, userID)
+}
+
+func (uc *notificationUsecase) UpdateNotificationPreferences(ctx context.Context, userID int, input NotificationPreferencesInput) (*entity.NotificationPreferences, error) {
+	if err := uc.userExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	prefs, err := uc.validatePreferences(input)
+	if err != nil {
+		return nil, err
+	}
+	prefs.UserID = userID
+	if err := uc.prefs.SaveNotificationPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// userExists membedakan user yang tidak ada (ErrUserNotFound) dari error database
+func (uc *notificationUsecase) userExists(ctx context.Context, userID int) error {
+	_, err := uc.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// ReviewFinished mengirim jumlah temuan dan link laporan ke pemilik repository lewat channel
+// pilihannya, default email ke User.Email. Notifikasi non-critical di jam tenang dibuang.
+// Kegagalan hanya dicatat di log, review tetap dianggap selesai.
+func (uc *notificationUsecase) ReviewFinished(ctx context.Context, repo *entity.Repository, run *entity.ReviewRun) {
+	user, err := uc.userRepo.GetUserByID(ctx, repo.UserID)
+	if err != nil {
+		log.Printf("⚠️ Notifikasi review run %d dilewati, pemilik repo %d tidak ditemukan: %v", run.ID, repo.ID, err)
+		return
+	}
+	prefs, err := uc.preferences(ctx, user.ID)
+	if err != nil {
+		log.Printf("❌ Gagal membaca preferensi notifikasi user %d: %v", user.ID, err)
+		return
+	}
+
+	n := uc.reviewNotification(user, repo, run)
+	if notifySeverityRank[n.Severity] < notifySeverityRank[prefs.MinSeverity] {
+		return
+	}
+	if n.Severity != port.NotifyCritical && inQuietHours(prefs, uc.now()) {
+		log.Printf("🌙 Notifikasi review run %d untuk user %d dibuang, jam tenang", run.ID, user.ID)
+		return
+	}
+
+	channels := prefs.Channels
+	if len(channels) == 0 {
+		if !slices.Contains(uc.notifier.Channels(), defaultNotifyChannel) {
+			log.Printf("⚠️ Notifikasi review run %d untuk user %d dilewati, channel email belum dikonfigurasi", run.ID, user.ID)
+			return
+		}
+		channels = []string{defaultNotifyChannel}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reviewNotifyTimeout)
+	defer cancel()
+	if err := uc.notifier.NotifyVia(ctx, channels, n); err != nil {
+		log.Printf("❌ Gagal kirim notifikasi review run %d ke user %d: %v", run.ID, user.ID, err)
+	}
+}
+
+func (uc *notificationUsecase) preferences(ctx context.Context, userID int) (*entity.NotificationPreferences, error) {
+	prefs, err := uc.prefs.GetNotificationPreferences(ctx, userID)
+	if errors.Is(err, repository.ErrNotificationPreferencesNotFound) {
+		return &entity.NotificationPreferences{
+			UserID:      userID,
+			Channels:    []string{},
+			MinSeverity: port.NotifyInfo,
+			Timezone:    defaultNotifyTimezone,
+		}, nil
+	}
+	return prefs, err
+}
+
+// reviewNotification: run gagal critical, selesai dengan temuan error warning, selain itu info
+func (uc *notificationUsecase) reviewNotification(user *entity.User, repo *entity.Repository, run *entity.ReviewRun) port.Notification {
+	severity := port.NotifyInfo
+	switch {
+	case run.Status == entity.ReviewRunFailed:
+		severity = port.NotifyCritical
+	case run.Summary.Errors > 0:
+		severity = port.NotifyWarning
+	}
+
+	s := run.Summary
+	text := fmt.Sprintf("Code review %s (run %d) %s: %d findings (%d errors, %d warnings, %d infos)",
+		repo.Name, run.ID, run.Status, s.Total, s.Errors, s.Warnings, s.Infos)
+	if run.Error != "" {
+		text += "\n" + run.Error
+	}
+
+	fields := map[string]string{
+		"repository": repo.Name,
+		"run":        strconv.FormatInt(run.ID, 10),
+		"status":     run.Status,
+		"findings":   strconv.Itoa(s.Total),
+		"errors":     strconv.Itoa(s.Errors),
+		"warnings":   strconv.Itoa(s.Warnings),
+	}
+	if run.CommitSHA != "" {
+		fields["commit"] = run.CommitSHA
+	}
+
+	return port.Notification{
+		Topic:    port.NotifyTopicReviewPrefix + run.Status,
+		Severity: severity,
+		Title:    fmt.Sprintf("Code review %s: %s", run.Status, repo.Name),
+		Text:     text,
+		URL:      fmt.Sprintf("%s/codereview/runs/%d/report", uc.baseURL, run.ID),
+		Fields:   fields,
+		To:       []string{user.Email},
+		Time:     uc.now(),
+	}
+}
+
+// validatePreferences memeriksa input dan mengisi default, channel dirapikan (tanpa duplikat)
+func (uc *notificationUsecase) validatePreferences(input NotificationPreferencesInput) (*entity.NotificationPreferences, error) {
+	prefs := &entity.NotificationPreferences{
+		Channels:        []string{},
+		MinSeverity:     strings.ToLower(strings.TrimSpace(input.MinSeverity)),
+		QuietHoursStart: strings.TrimSpace(input.QuietHoursStart),
+		QuietHoursEnd:   strings.TrimSpace(input.QuietHoursEnd),
+		Timezone:        strings.TrimSpace(input.Timezone),
+	}
+
+	configured := uc.notifier.Channels()
+	for _, c := range input.Channels {
+		c = strings.TrimSpace(c)
+		if !slices.Contains(configured, c) {
+			return nil, fmt.Errorf("%w: unknown channel %q, configured channels: %s", ErrInvalidNotificationPreferences, c, strings.Join(configured, ", "))
+		}
+		if !slices.Contains(prefs.Channels, c) {
+			prefs.Channels = append(prefs.Channels, c)
+		}
+	}
+
+	if prefs.MinSeverity == "" {
+		prefs.MinSeverity = port.NotifyInfo
+	}
+	if _, ok := notifySeverityRank[prefs.MinSeverity]; !ok {
+		return nil, fmt.Errorf("%w: min_severity must be info, warning or critical", ErrInvalidNotificationPreferences)
+	}
+
+	if (prefs.QuietHoursStart == "") != (prefs.QuietHoursEnd == "") {
+		return nil, fmt.Errorf("%w: quiet_hours_start and quiet_hours_end must be set together", ErrInvalidNotificationPreferences)
+	}
+	if prefs.QuietHoursStart != "" {
+		start, errStart := clockMinutes(prefs.QuietHoursStart)
+		end, errEnd := clockMinutes(prefs.QuietHoursEnd)
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("%w: quiet hours must use HH:MM", ErrInvalidNotificationPreferences)
+		}
+		if start == end {
+			return nil, fmt.Errorf("%w: quiet hours must not start and end at the same time", ErrInvalidNotificationPreferences)
+		}
+	}
+
+	if prefs.Timezone == "" {
+		prefs.Timezone = defaultNotifyTimezone
+	}
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidNotificationPreferences, prefs.Timezone)
+	}
+	return prefs, nil
+}
+
+// inQuietHours memeriksa now di timezone user, jam tenang boleh melewati tengah malam (22:00 - 07:00)
+func inQuietHours(prefs *entity.NotificationPreferences, now time.Time) bool {
+	if prefs.QuietHoursStart == "" {
+		return false
+	}
+	start, errStart := clockMinutes(prefs.QuietHoursStart)
+	end, errEnd := clockMinutes(prefs.QuietHoursEnd)
+	loc, errLoc := time.LoadLocation(prefs.Timezone)
+	if errStart != nil || errEnd != nil || errLoc != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// clockMinutes mengubah "HH:MM" menjadi menit sejak tengah malam
+func clockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
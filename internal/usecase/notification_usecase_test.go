@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-crud/internal/entity"
+	"go-crud/internal/repository/memory"
+	"go-crud/internal/usecase/port"
+)
+
+// recordingChannelNotifier mencatat notifikasi yang dikirim lewat NotifyVia
+type recordingChannelNotifier struct {
+	sent []port.Notification
+}
+
+func (n *recordingChannelNotifier) Name() string       { return "test" }
+func (n *recordingChannelNotifier) Channels() []string { return []string{"email", "slack"} }
+
+func (n *recordingChannelNotifier) Notify(ctx context.Context, msg port.Notification) error {
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func (n *recordingChannelNotifier) NotifyVia(ctx context.Context, channels []string, msg port.Notification) error {
+	return n.Notify(ctx, msg)
+}
+
+func TestValidatePreferences(t *testing.T) {
+	uc := &notificationUsecase{notifier: &recordingChannelNotifier{}}
+
+	tests := []struct {
+		name    string
+		input   NotificationPreferencesInput
+		want    *entity.NotificationPreferences
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: NotificationPreferencesInput{},
+			want:  &entity.NotificationPreferences{Channels: []string{}, MinSeverity: port.NotifyInfo, Timezone: "UTC"},
+		},
+		{
+			name: "quiet hours across midnight in a timezone",
+			input: NotificationPreferencesInput{Channels: []string{" slack", "slack", "email"}, MinSeverity: "Warning",
+				QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Asia/Jakarta"},
+			want: &entity.NotificationPreferences{Channels: []string{"slack", "email"}, MinSeverity: port.NotifyWarning,
+				QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Asia/Jakarta"},
+		},
+		{name: "start equals end", input: NotificationPreferencesInput{QuietHoursStart: "22:00", QuietHoursEnd: "22:00"}, wantErr: true},
+		{name: "only start", input: NotificationPreferencesInput{QuietHoursStart: "22:00"}, wantErr: true},
+		{name: "not HH:MM", input: NotificationPreferencesInput{QuietHoursStart: "10pm", QuietHoursEnd: "07:00"}, wantErr: true},
+		{name: "hour out of range", input: NotificationPreferencesInput{QuietHoursStart: "24:00", QuietHoursEnd: "07:00"}, wantErr: true},
+		{name: "unknown timezone", input: NotificationPreferencesInput{Timezone: "Mars/Olympus"}, wantErr: true},
+		{name: "unknown channel", input: NotificationPreferencesInput{Channels: []string{"pager"}}, wantErr: true},
+		{name: "unknown severity", input: NotificationPreferencesInput{MinSeverity: "debug"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.validatePreferences(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidNotificationPreferences) {
+					t.Fatalf("err = %v, want ErrInvalidNotificationPreferences", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validatePreferences: %v", err)
+			}
+			if got.MinSeverity != tt.want.MinSeverity || got.Timezone != tt.want.Timezone ||
+				got.QuietHoursStart != tt.want.QuietHoursStart || got.QuietHoursEnd != tt.want.QuietHoursEnd ||
+				len(got.Channels) != len(tt.want.Channels) {
+				t.Fatalf("prefs = %+v, want %+v", got, tt.want)
+			}
+			for i := range got.Channels {
+				if got.Channels[i] != tt.want.Channels[i] {
+					t.Errorf("channels = %v, want %v", got.Channels, tt.want.Channels)
+				}
+			}
+		})
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := &entity.NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "UTC"}
+	daytime := &entity.NotificationPreferences{QuietHoursStart: "09:00", QuietHoursEnd: "17:00", Timezone: "UTC"}
+	// Asia/Jakarta UTC+7: 22:00-07:00 lokal sama dengan 15:00-00:00 UTC
+	jakarta := &entity.NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Asia/Jakarta"}
+
+	tests := []struct {
+		name  string
+		prefs *entity.NotificationPreferences
+		now   time.Time
+		want  bool
+	}{
+		{"overnight before start", overnight, at(21, 59), false},
+		{"overnight at start", overnight, at(22, 0), true},
+		{"overnight before midnight", overnight, at(23, 30), true},
+		{"overnight after midnight", overnight, at(3, 0), true},
+		{"overnight at end", overnight, at(7, 0), false},
+		{"daytime inside", daytime, at(12, 0), true},
+		{"daytime at end", daytime, at(17, 0), false},
+		{"daytime before start", daytime, at(8, 59), false},
+		{"timezone local 23:00", jakarta, at(16, 0), true},
+		{"timezone local 06:59", jakarta, at(23, 59), true},
+		{"timezone local 08:00", jakarta, at(1, 0), false},
+		{"timezone local 14:00", jakarta, at(7, 0), false},
+		{"no quiet hours", &entity.NotificationPreferences{Timezone: "UTC"}, at(23, 0), false},
+	}
+	for _, tt := range tests {
+		if got := inQuietHours(tt.prefs, tt.now); got != tt.want {
+			t.Errorf("%s: inQuietHours(%s) = %v, want %v", tt.name, tt.now.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestReviewFinishedQuietHours(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	prefs := memory.NewNotificationPreferenceRepository(store)
+
+	user := &entity.User{Name: "Budi", Email: "budi@example.com"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := prefs.SaveNotificationPreferences(ctx, &entity.NotificationPreferences{
+		UserID: user.ID, Channels: []string{"slack"}, MinSeverity: port.NotifyInfo,
+		QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "UTC",
+	}); err != nil {
+		t.Fatalf("SaveNotificationPreferences: %v", err)
+	}
+	repo := &entity.Repository{ID: 1, UserID: user.ID, Name: "go-crud"}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		run      *entity.ReviewRun
+		wantSent bool
+	}{
+		{"warning in quiet hours is dropped", time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC),
+			&entity.ReviewRun{ID: 1, Status: entity.ReviewRunCompleted, Summary: entity.ReviewSummary{Total: 1, Errors: 1}}, false},
+		{"info in quiet hours is dropped", time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC),
+			&entity.ReviewRun{ID: 2, Status: entity.ReviewRunCompleted}, false},
+		{"critical in quiet hours is sent", time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC),
+			&entity.ReviewRun{ID: 3, Status: entity.ReviewRunFailed, Error: "clone failed"}, true},
+		{"warning outside quiet hours is sent", time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			&entity.ReviewRun{ID: 4, Status: entity.ReviewRunCompleted, Summary: entity.ReviewSummary{Total: 1, Errors: 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingChannelNotifier{}
+			uc := NewNotificationUsecase(prefs, users, notifier, "http://localhost:8080").(*notificationUsecase)
+			uc.now = func() time.Time { return tt.now }
+
+			uc.ReviewFinished(ctx, repo, tt.run)
+			if sent := len(notifier.sent) == 1; sent != tt.wantSent {
+				t.Errorf("sent = %v (%d notifications), want %v", sent, len(notifier.sent), tt.wantSent)
+			}
+		})
+	}
+}
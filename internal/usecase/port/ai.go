@@ -0,0 +1,42 @@
+package port
+
+import "context"
+
+// AIHunk adalah potongan kode yang dikirim ke AIReviewer
+type AIHunk struct {
+	File     string
+	Language string
+	// StartLine adalah nomor baris (1-based) dari Lines[0] di file
+	StartLine int
+	Lines     []string
+}
+
+// EndLine adalah nomor baris terakhir hunk
+func (h AIHunk) EndLine() int {
+	return h.StartLine + len(h.Lines) - 1
+}
+
+// AIComment adalah satu komentar model untuk baris di dalam hunk
+type AIComment struct {
+	Line       int
+	EndLine    int
+	Severity   string
+	Message    string
+	Suggestion string
+}
+
+// AIReviewResult berisi komentar model dan token yang benar-benar terpakai
+type AIReviewResult struct {
+	Comments []AIComment
+	Tokens   int
+}
+
+// AIReviewer meminta review dari model bahasa (endpoint OpenAI-compatible atau stub lokal)
+type AIReviewer interface {
+	// Name dipakai di log dan temuan, contoh "openai:gpt-4o-mini" atau "stub"
+	Name() string
+	// EstimateTokens memperkirakan token prompt + jawaban sebelum hunk dikirim,
+	// dipakai untuk budget per review dan kuota bulanan user
+	EstimateTokens(hunk AIHunk) int
+	Review(ctx context.Context, hunk AIHunk) (*AIReviewResult, error)
+}
@@ -0,0 +1,33 @@
+package port
+
+import "net/http"
+
+// Jenis event git yang bisa memicu review
+const (
+	GitEventPush        = "push"
+	GitEventPullRequest = "pull_request"
+)
+
+// GitEvent adalah payload webhook git yang sudah dinormalisasi dari format provider
+type GitEvent struct {
+	Kind string
+	// CloneURLs berisi semua URL repository di payload (https, ssh, halaman web),
+	// dicocokkan dengan entity.Repository.URL
+	CloneURLs []string
+	// Base kosong berarti review penuh Head (misalnya push yang membuat branch baru)
+	Base string
+	Head string
+	// Summary untuk log, contoh "push refs/heads/main" atau "pull_request #12 synchronize"
+	Summary string
+	// Ignore berisi alasan event tidak memicu review (ping, branch dihapus, PR ditutup, ...)
+	Ignore string
+}
+
+// GitWebhookProvider membaca webhook dari satu layanan git (GitHub, GitLab, Gitea)
+type GitWebhookProvider interface {
+	// Name dipakai di path /webhooks/git/{provider}
+	Name() string
+	// Verify memeriksa tanda tangan request dengan secret webhook repository
+	Verify(header http.Header, body []byte, secret string) bool
+	Parse(header http.Header, body []byte) (*GitEvent, error)
+}
@@ -0,0 +1,51 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// Severity notifikasi
+const (
+	NotifyInfo     = "info"
+	NotifyWarning  = "warning"
+	NotifyCritical = "critical"
+)
+
+// Topic notifikasi, dipakai aturan routing (NOTIFY_ROUTES) untuk memilih channel
+const (
+	NotifyTopicBreaker = "breaker"
+	// Review selesai ke pemilik repository: review.completed, review.failed, review.cancelled
+	NotifyTopicReviewPrefix = "review."
+)
+
+// Notification adalah satu pesan untuk manusia (Telegram, Slack, email, ...)
+type Notification struct {
+	Topic    string            `json:"topic"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text"`
+	URL      string            `json:"url,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	// To diisi untuk pesan ke orang tertentu (alamat email), kosong berarti penerima
+	// bawaan channel. Channel tanpa konsep penerima (Slack, Telegram) mengabaikannya.
+	To   []string  `json:"-"`
+	Time time.Time `json:"time"`
+}
+
+// Notifier mengirim notifikasi ke satu channel, atau ke beberapa channel sekaligus (router)
+type Notifier interface {
+	// Name dipakai di log dan aturan routing, contoh "slack" atau "email"
+	Name() string
+	// Notify mengembalikan error jika channel menolak pesan (status HTTP bukan 2xx, SMTP error)
+	Notify(ctx context.Context, n Notification) error
+}
+
+// ChannelNotifier bisa mengirim ke channel tertentu saja tanpa melihat routing topic,
+// contoh sesuai preferensi user
+type ChannelNotifier interface {
+	Notifier
+	// Channels mengembalikan nama channel yang dikonfigurasi
+	Channels() []string
+	NotifyVia(ctx context.Context, channels []string, n Notification) error
+}
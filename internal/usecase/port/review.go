@@ -0,0 +1,120 @@
+package port
+
+import (
+	"context"
+	"go-crud/internal/entity"
+	"go/ast"
+	"go/token"
+)
+
+// Workspace adalah hasil checkout repository yang siap dianalisis
+type Workspace struct {
+	Dir       string
+	CommitSHA string
+	Cleanup   func()
+}
+
+// SourceCheckout mengambil source repository ke direktori lokal.
+// ref kosong berarti HEAD, selain itu commit SHA atau nama branch/tag.
+type SourceCheckout interface {
+	Checkout(ctx context.Context, url string, ref string) (*Workspace, error)
+	// Diff menghitung perubahan dari base ke commit workspace
+	Diff(ctx context.Context, ws *Workspace, base string) (*Diff, error)
+}
+
+// LineRange adalah rentang baris (1-based, inklusif)
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Diff adalah perubahan antara dua commit: file yang ditambah/diubah beserta rentang baris
+// yang berubah di versi head. File yang dihapus tidak ikut.
+type Diff struct {
+	BaseSHA string
+	HeadSHA string
+	Files   map[string][]LineRange
+}
+
+// Changed melaporkan apakah file ada di diff
+func (d *Diff) Changed(file string) bool {
+	_, ok := d.Files[file]
+	return ok
+}
+
+// Touches melaporkan apakah rentang baris start-end di file mengenai baris yang berubah
+func (d *Diff) Touches(file string, start, end int) bool {
+	if end < start {
+		end = start
+	}
+	for _, r := range d.Files[file] {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// Bahasa yang dikenali engine, ditentukan dari ekstensi file
+const (
+	LanguageGo = "go"
+	// AnyLanguage dipakai Analyzer yang bisa membaca file bahasa apa pun
+	AnyLanguage = "*"
+)
+
+// SourceFile adalah satu file di workspace beserta isinya
+type SourceFile struct {
+	Path     string // relatif terhadap root workspace, selalu pakai '/'
+	Language string
+	Src      []byte
+	// Hanya untuk file Go yang berhasil di-parse, nil jika bukan Go atau ada syntax error
+	Fset *token.FileSet
+	AST  *ast.File
+}
+
+// AnalysisWorkspace adalah sebagian file workspace yang diberikan ke Analyzer.
+// Engine memanggil Analyzer per direktori (satu package Go), hanya dengan file
+// yang bahasanya didukung Analyzer tersebut.
+type AnalysisWorkspace struct {
+	Dir   string
+	Files []*SourceFile
+	// Diff diisi pada review incremental. Files tetap berisi seluruh package supaya analyzer
+	// punya konteks, temuan di luar baris yang berubah dibuang engine. Analyzer yang mahal
+	// (misalnya AI) sebaiknya hanya memproses baris di Diff.
+	Diff *Diff
+}
+
+// Analyzer adalah satu kelompok aturan code review
+type Analyzer interface {
+	// Name unik, dipakai untuk mengaktifkan analyzer per repository
+	Name() string
+	// Languages berisi bahasa yang didukung, atau AnyLanguage
+	Languages() []string
+	Analyze(ctx context.Context, ws *AnalysisWorkspace) ([]entity.ReviewFinding, error)
+}
+
+// AnalyzeOptions mengatur jalannya analisis
+type AnalyzeOptions struct {
+	// Analyzers yang dijalankan, file yang tidak didukung satu pun analyzer diabaikan
+	Analyzers []Analyzer
+	// Skip berisi path relatif file yang sudah dianalisis (dari checkpoint)
+	Skip map[string]bool
+	// Diff membatasi analisis ke file yang berubah dan temuan ke baris yang berubah, nil berarti semua
+	Diff *Diff
+	// Include memilih file (path relatif) yang dianalisis, nil berarti semua.
+	// File yang tidak dipilih juga tidak diberikan ke analyzer sebagai konteks.
+	Include func(file string) bool
+	// OnStart dipanggil sekali sebelum analisis dengan jumlah seluruh file yang akan dianalisis
+	// (dipilih Include dan berubah, jika ada Diff), termasuk yang di-skip
+	OnStart func(totalFiles int)
+	// OnFile dipanggil setelah satu file selesai dianalisis
+	OnFile func(file string, findings []entity.ReviewFinding)
+}
+
+// ReviewEngine menjalankan opts.Analyzers atas source di direktori workspace
+type ReviewEngine interface {
+	Analyze(ctx context.Context, dir string, opts AnalyzeOptions) ([]entity.ReviewFinding, error)
+	// LoadConfig membaca konfigurasi review dari root workspace, nil jika repository tidak punya.
+	// name adalah nama file yang dibaca, untuk log dan pesan error.
+	LoadConfig(dir string) (cfg *entity.ReviewConfig, name string, err error)
+}
@@ -0,0 +1,56 @@
+package port
+
+import (
+	"context"
+	"net/netip"
+)
+
+// WebhookRequest adalah satu percobaan pengiriman event ke endpoint subscription
+type WebhookRequest struct {
+	URL    string
+	Secret string
+	// EventType dan DeliveryID dikirim di header supaya penerima bisa memilah dan men-dedupe
+	EventType  string
+	DeliveryID int64
+	Attempt    int
+	Body       []byte
+}
+
+// WebhookResponse adalah jawaban endpoint, Body dipotong untuk log pengiriman
+type WebhookResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// WebhookSender mengirim payload webhook yang ditandatangani HMAC-SHA256 dengan secret subscription
+type WebhookSender interface {
+	// Send mengembalikan error untuk kegagalan jaringan dan status selain 2xx. Response tetap
+	// diisi jika endpoint sempat menjawab.
+	Send(ctx context.Context, req WebhookRequest) (*WebhookResponse, error)
+}
+
+// blockedWebhookPrefixes adalah jaringan yang tidak terlihat sebagai private/loopback oleh
+// netip tetapi tetap bukan tujuan publik (CGNAT, benchmark, NAT64 lokal)
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// PublicWebhookAddr melaporkan apakah ip boleh menjadi tujuan webhook keluar. Loopback, private,
+// link-local (termasuk metadata cloud 169.254.169.254), multicast dan unspecified ditolak.
+// Dipakai saat validasi URL subscription dan lagi saat dial karena DNS bisa berubah (rebinding).
+func PublicWebhookAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedWebhookPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
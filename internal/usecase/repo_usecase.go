@@ -11,13 +11,14 @@ import (
 	"go-crud/internal/circuitbreaker"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/sony/gobreaker"
 )
 
 // Interface untuk RepositoryUsecase
 type IRepositoryUsecase interface {
 	CreateRepository(ctx context.Context, repo *entity.Repository) error
+	// ValidateOwner mengembalikan ErrUserNotFound jika user pemilik repository tidak ada
+	ValidateOwner(ctx context.Context, userID int) error
 	GetRepositoryByID(ctx context.Context, id int) (*entity.Repository, error)
 	GetAllRepositories(ctx context.Context) ([]entity.Repository, error)
 	UpdateRepository(ctx context.Context, id int, input RepositoryInput) (entity.Repository, error)
@@ -29,7 +30,7 @@ type IRepositoryUsecase interface {
 type RepositoryUsecase struct {
 	repoRepo   repository.RepositoryRepository
 	userRepo   repository.UserRepository
-	redis      *redis.Client
+	cache      repository.CacheRepository
 	cbRedis    *gobreaker.CircuitBreaker
 	cbPostgres *gobreaker.CircuitBreaker
 }
@@ -45,14 +46,15 @@ type RepositoryInput struct {
 func NewRepositoryUsecase(
 	repoRepo repository.RepositoryRepository,
 	userRepo repository.UserRepository,
-	redisClient *redis.Client,
+	cache repository.CacheRepository,
+	alerts *cbreaker.Alerter,
 ) IRepositoryUsecase {
 	return &RepositoryUsecase{
 		repoRepo:   repoRepo,
 		userRepo:   userRepo,
-		redis:      redisClient,
-		cbRedis:    cbreaker.NewBreaker("RepositoryRedisCB"),
-		cbPostgres: cbreaker.NewBreaker("RepositoryPostgresCB"),
+		cache:      cache,
+		cbRedis:    cbreaker.NewBreaker("RepositoryRedisCB", alerts),
+		cbPostgres: cbreaker.NewBreaker("RepositoryPostgresCB", alerts),
 	}
 }
 
@@ -61,12 +63,22 @@ func (u *RepositoryUsecase) CreateRepository(ctx context.Context, repo *entity.R
 	ctx, span := tracing.Tracer.Start(ctx, "RepositoryUsecase.CreateRepository")
 	defer span.End()
 
-	_, err := u.userRepo.GetUserByID(ctx, repo.UserID)
+	// Tidak langsung simpan ke DB, tugas Kafka consumer
+	return u.ValidateOwner(ctx, repo.UserID)
+}
+
+func (u *RepositoryUsecase) ValidateOwner(ctx context.Context, userID int) error {
+	ctx, span := tracing.Tracer.Start(ctx, "RepositoryUsecase.ValidateOwner")
+	defer span.End()
+
+	_, err := u.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
 	if err != nil {
-		return errors.New("user not found")
+		span.RecordError(err)
+		return fmt.Errorf("get user %d: %w", userID, err)
 	}
-
-	// Tidak langsung simpan ke DB, tugas Kafka consumer
 	return nil
 }
 
@@ -84,9 +96,12 @@ func (u *RepositoryUsecase) GetRepositoriesByUserID(ctx context.Context, userID
 	defer span.End()
 
 	_, err := u.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
 	if err != nil {
 		span.RecordError(err)
-		return nil, errors.New("user not found")
+		return nil, fmt.Errorf("get user %d: %w", userID, err)
 	}
 
 	return u.repoRepo.GetRepositoriesByUserID(ctx, userID)
@@ -100,7 +115,7 @@ func (u *RepositoryUsecase) GetRepositoryByID(ctx context.Context, id int) (*ent
 	cacheKey := fmt.Sprintf("repository:%d", id)
 
 	val, err := u.cbRedis.Execute(func() (interface{}, error) {
-		return u.redis.Get(ctx, cacheKey).Result()
+		return u.cache.Get(ctx, cacheKey)
 	})
 	if err == nil {
 		var cached entity.Repository
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/usecase/port"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Batas isi konfigurasi review supaya profil tidak dipakai untuk membebani server
+const (
+	maxReviewConfigGlobs     = 100
+	maxReviewConfigOverrides = 200
+)
+
+// ErrInvalidReviewConfig dikembalikan jika .gocrud-review.yml atau profil review tidak valid
+var ErrInvalidReviewConfig = errors.New("invalid review config")
+
+// Urutan severity untuk ambang fail_on dan max_findings, makin besar makin parah
+var severityRank = map[string]int{
+	entity.SeverityInfo:    1,
+	entity.SeverityWarning: 2,
+	entity.SeverityError:   3,
+}
+
+// reviewRules adalah ReviewConfig yang sudah divalidasi dan glob-nya sudah dikompilasi.
+// nil berarti tanpa konfigurasi: semua file, severity asli, tanpa batas.
+type reviewRules struct {
+	cfg     *entity.ReviewConfig
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	// source adalah asal konfigurasi untuk log, contoh ".gocrud-review.yml" atau "repository profile"
+	source string
+}
+
+// compileReviewConfig memvalidasi cfg. known dipakai untuk memeriksa nama analyzer,
+// nil berarti nama analyzer tidak diperiksa.
+func compileReviewConfig(cfg *entity.ReviewConfig, known func(name string) bool) (*reviewRules, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidReviewConfig, fmt.Sprintf(format, args...))
+	}
+
+	if known != nil {
+		for _, name := range slices.Concat(cfg.Analyzers.Enable, cfg.Analyzers.Disable) {
+			if !known(name) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownAnalyzer, name)
+			}
+		}
+	}
+	if len(cfg.Severity) > maxReviewConfigOverrides {
+		return nil, invalid("at most %d severity overrides are allowed", maxReviewConfigOverrides)
+	}
+	for rule, severity := range cfg.Severity {
+		if _, ok := severityRank[severity]; !ok && severity != entity.SeverityOff {
+			return nil, invalid("severity %q for rule %q must be error, warning, info or off", severity, rule)
+		}
+	}
+	if cfg.MaxFindings < 0 {
+		return nil, invalid("max_findings must not be negative")
+	}
+	if t := cfg.FailOn; t != nil {
+		if _, ok := severityRank[cmp.Or(t.Severity, entity.SeverityError)]; !ok {
+			return nil, invalid("fail_on.severity %q must be error, warning or info", t.Severity)
+		}
+		if t.Max < 0 {
+			return nil, invalid("fail_on.max must not be negative")
+		}
+	}
+	if len(cfg.Include)+len(cfg.Exclude) > maxReviewConfigGlobs {
+		return nil, invalid("at most %d include/exclude globs are allowed", maxReviewConfigGlobs)
+	}
+
+	rules := &reviewRules{cfg: cfg}
+	for _, list := range []struct {
+		globs []string
+		out   *[]*regexp.Regexp
+	}{{cfg.Include, &rules.include}, {cfg.Exclude, &rules.exclude}} {
+		for _, glob := range list.globs {
+			re, err := globRegexp(glob)
+			if err != nil {
+				return nil, invalid("glob %q: %v", glob, err)
+			}
+			*list.out = append(*list.out, re)
+		}
+	}
+	return rules, nil
+}
+
+// globRegexp mengubah glob path menjadi regexp. * dan ? tidak melewati '/', ** melewati direktori.
+// Glob tanpa '/' berlaku di direktori mana pun (seperti .gitignore), dan glob yang cocok dengan
+// sebuah direktori berlaku untuk semua file di dalamnya.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	g := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(glob), "/"), "/")
+	if g == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if !strings.Contains(strings.TrimSpace(glob), "/") {
+		g = "**/" + g
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(g); i++ {
+		switch {
+		case strings.HasPrefix(g[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(g[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case g[i] == '*':
+			b.WriteString("[^/]*")
+		case g[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(g[i : i+1]))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+	return regexp.Compile(b.String())
+}
+
+// loadReviewRules memakai .gocrud-review.yml dari workspace jika ada, selain itu profil
+// yang disimpan untuk repository. Nama analyzer yang tidak dikenal di file repository hanya
+// dicatat di log karena file bisa tertinggal dari daftar analyzer server.
+func (uc *codeReviewUsecase) loadReviewRules(ctx context.Context, repoID int, dir string) (*reviewRules, error) {
+	cfg, source, err := uc.engine.LoadConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReviewConfig, err)
+	}
+	if cfg == nil {
+		if cfg, err = uc.settings.GetReviewConfig(ctx, repoID); err != nil {
+			return nil, fmt.Errorf("load review config: %w", err)
+		}
+		if cfg == nil {
+			return nil, nil
+		}
+		source = "repository profile"
+	}
+
+	var unknown []string
+	known := func(names []string) []string {
+		var kept []string
+		for _, name := range names {
+			if uc.knownAnalyzer(name) {
+				kept = append(kept, name)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+		return kept
+	}
+	cfg.Analyzers.Enable = known(cfg.Analyzers.Enable)
+	cfg.Analyzers.Disable = known(cfg.Analyzers.Disable)
+	if len(unknown) > 0 {
+		log.Printf("⚠️ %s repo %d menyebut analyzer yang tidak dikenal, diabaikan: %v", source, repoID, unknown)
+	}
+
+	rules, err := compileReviewConfig(cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	rules.source = source
+	return rules, nil
+}
+
+// knownAnalyzer mengenali analyzer terdaftar dan analyzer AI (yang dibuat per run)
+func (uc *codeReviewUsecase) knownAnalyzer(name string) bool {
+	_, ok := uc.registry.Get(name)
+	return ok || name == aiAnalyzerName
+}
+
+// analyzers mengaktifkan/mematikan analyzer di atas daftar nama yang diatur untuk repository
+// (nil berarti semua analyzer terdaftar)
+func (r *reviewRules) analyzers(registry *AnalyzerRegistry, names []string) []port.Analyzer {
+	if r == nil || (len(r.cfg.Analyzers.Enable) == 0 && len(r.cfg.Analyzers.Disable) == 0) {
+		return registry.Enabled(names)
+	}
+
+	enabled := map[string]bool{}
+	for _, a := range registry.Enabled(names) {
+		enabled[a.Name()] = true
+	}
+	for _, name := range r.cfg.Analyzers.Enable {
+		enabled[name] = true
+	}
+	for _, name := range r.cfg.Analyzers.Disable {
+		delete(enabled, name)
+	}
+
+	var selected []port.Analyzer
+	for _, a := range registry.All() {
+		if enabled[a.Name()] {
+			selected = append(selected, a)
+		}
+	}
+	return selected
+}
+
+// disabled melaporkan apakah analyzer dimatikan konfigurasi
+func (r *reviewRules) disabled(name string) bool {
+	return r != nil && slices.Contains(r.cfg.Analyzers.Disable, name)
+}
+
+// filter mengembalikan AnalyzeOptions.Include, nil jika semua file dianalisis
+func (r *reviewRules) filter() func(file string) bool {
+	if r == nil || (len(r.include) == 0 && len(r.exclude) == 0) {
+		return nil
+	}
+	return r.match
+}
+
+func (r *reviewRules) match(file string) bool {
+	matches := func(res []*regexp.Regexp) bool {
+		return slices.ContainsFunc(res, func(re *regexp.Regexp) bool { return re.MatchString(file) })
+	}
+	return (len(r.include) == 0 || matches(r.include)) && !matches(r.exclude)
+}
+
+// rewrite menerapkan severity override, temuan rule yang di-set "off" dibuang
+func (r *reviewRules) rewrite(findings []entity.ReviewFinding) []entity.ReviewFinding {
+	if r == nil || len(r.cfg.Severity) == 0 {
+		return findings
+	}
+	kept := findings[:0:0]
+	for _, f := range findings {
+		switch severity, ok := r.cfg.Severity[f.RuleID]; {
+		case !ok:
+		case severity == entity.SeverityOff:
+			continue
+		default:
+			f.Severity = severity
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// limit menyisakan paling banyak max_findings temuan, yang paling parah didahulukan.
+// Urutan temuan yang tersisa tidak berubah.
+func (r *reviewRules) limit(findings []entity.ReviewFinding) ([]entity.ReviewFinding, int) {
+	if r == nil || r.cfg.MaxFindings == 0 || len(findings) <= r.cfg.MaxFindings {
+		return findings, 0
+	}
+
+	order := make([]int, len(findings))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return severityRank[findings[b].Severity] - severityRank[findings[a].Severity]
+	})
+	keep := order[:r.cfg.MaxFindings]
+	slices.Sort(keep)
+
+	kept := make([]entity.ReviewFinding, 0, len(keep))
+	for _, i := range keep {
+		kept = append(kept, findings[i])
+	}
+	return kept, len(findings) - len(kept)
+}
+
+// gate mengembalikan alasan run gagal jika temuan melewati ambang fail_on, kosong jika lolos
+func (r *reviewRules) gate(findings []entity.ReviewFinding) string {
+	if r == nil || r.cfg.FailOn == nil {
+		return ""
+	}
+	severity := cmp.Or(r.cfg.FailOn.Severity, entity.SeverityError)
+	count := 0
+	for _, f := range findings {
+		if severityRank[f.Severity] >= severityRank[severity] {
+			count++
+		}
+	}
+	if count <= r.cfg.FailOn.Max {
+		return ""
+	}
+	return fmt.Sprintf("%d findings at or above %s exceed the fail_on limit of %d (%s)", count, severity, r.cfg.FailOn.Max, r.source)
+}
@@ -0,0 +1,280 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	reviewPollInterval    = 1 * time.Second
+	defaultReviewWorkers  = 2
+	defaultReviewLeaseTTL = 30 * time.Second
+	defaultReviewShutdown = 25 * time.Second
+	minReviewLeaseTTL     = 3 * time.Second
+	reviewReleaseReason   = "released on shutdown"
+)
+
+// ReviewWorkerConfig mengatur jumlah worker, lease job dan batas waktu shutdown
+type ReviewWorkerConfig struct {
+	Workers int
+	// LeaseTTL adalah lama job dianggap masih dipegang worker tanpa heartbeat
+	LeaseTTL time.Duration
+	// ShutdownTimeout adalah lama menunggu review selesai sebelum dihentikan dan dikembalikan ke antrean
+	ShutdownTimeout time.Duration
+	// InstanceID menandai proses ini di kolom worker_id
+	InstanceID string
+}
+
+// ReviewWorkerConfigFromEnv membaca REVIEW_WORKERS (default 2), REVIEW_LEASE_TTL (default 30s)
+// dan REVIEW_SHUTDOWN_TIMEOUT (default 25s)
+func ReviewWorkerConfigFromEnv() ReviewWorkerConfig {
+	cfg := ReviewWorkerConfig{
+		Workers:         defaultReviewWorkers,
+		LeaseTTL:        defaultReviewLeaseTTL,
+		ShutdownTimeout: defaultReviewShutdown,
+	}
+	if n, err := strconv.Atoi(os.Getenv("REVIEW_WORKERS")); err == nil && n > 0 {
+		cfg.Workers = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("REVIEW_LEASE_TTL")); err == nil && d > 0 {
+		cfg.LeaseTTL = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("REVIEW_SHUTDOWN_TIMEOUT")); err == nil && d >= 0 {
+		cfg.ShutdownTimeout = d
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "review"
+	}
+	cfg.InstanceID = host + "-" + uuid.NewString()[:8]
+	return cfg
+}
+
+// ReviewWorkerPool mengambil job dari antrean review_jobs dan menjalankan code review.
+// Antrean ada di database, jadi job yang belum diambil tidak hilang saat proses mati.
+// Job running dipegang dengan lease yang diperpanjang lewat heartbeat; jika instance mati,
+// lease habis dan job dikembalikan ke antrean lalu dilanjutkan dari checkpoint terakhir.
+type ReviewWorkerPool struct {
+	jobs     repository.ReviewJobRepository
+	reviewer ICodeReviewUsecase
+	cfg      ReviewWorkerConfig
+
+	// haltCtx dibatalkan oleh Halt untuk menghentikan review yang sedang berjalan
+	haltCtx context.Context
+	halt    context.CancelFunc
+}
+
+func NewReviewWorkerPool(jobs repository.ReviewJobRepository, reviewer ICodeReviewUsecase, cfg ReviewWorkerConfig) *ReviewWorkerPool {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.LeaseTTL < minReviewLeaseTTL {
+		cfg.LeaseTTL = minReviewLeaseTTL
+	}
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = uuid.NewString()[:8]
+	}
+
+	haltCtx, halt := context.WithCancel(context.Background())
+	return &ReviewWorkerPool{
+		jobs:     jobs,
+		reviewer: reviewer,
+		cfg:      cfg,
+		haltCtx:  haltCtx,
+		halt:     halt,
+	}
+}
+
+// ShutdownTimeout adalah batas waktu menunggu Start kembali sebelum memanggil Halt
+func (p *ReviewWorkerPool) ShutdownTimeout() time.Duration {
+	return p.cfg.ShutdownTimeout
+}
+
+// Start menjalankan worker sampai ctx dibatalkan. Review yang sedang berjalan dibiarkan
+// selesai (kecuali Halt dipanggil), Start baru kembali setelah semua worker berhenti.
+func (p *ReviewWorkerPool) Start(ctx context.Context) {
+	log.Printf("🚀 Review worker pool started (%d worker, instance %s, lease %s)\n", p.cfg.Workers, p.cfg.InstanceID, p.cfg.LeaseTTL)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.reap(ctx)
+	}()
+	for i := 1; i <= p.cfg.Workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			p.work(ctx, id)
+		}(i)
+	}
+	wg.Wait()
+
+	log.Println("🛑 Review worker pool stopped")
+}
+
+// Halt menghentikan review yang sedang berjalan. Progresnya disimpan sebagai checkpoint
+// dan job dikembalikan ke antrean, sehingga bisa dilanjutkan instance berikutnya.
+func (p *ReviewWorkerPool) Halt() {
+	p.halt()
+}
+
+// reap mengembalikan job milik instance yang sudah mati ke antrean, sekali saat start
+// lalu berkala selama pool berjalan
+func (p *ReviewWorkerPool) reap(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.LeaseTTL)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := p.jobs.RequeueExpiredJobs(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Gagal memeriksa lease job review: %v\n", err)
+		}
+		for _, job := range jobs {
+			if job.Checkpoint != nil {
+				log.Printf("♻️ Job %s (repo %d) ditinggalkan worker yang mati, akan dilanjutkan dari checkpoint (%d file selesai)\n",
+					job.ID, job.RepositoryID, len(job.Checkpoint.FilesDone))
+			} else {
+				log.Printf("♻️ Job %s (repo %d) ditinggalkan worker yang mati, masuk antrean lagi\n", job.ID, job.RepositoryID)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *ReviewWorkerPool) work(ctx context.Context, id int) {
+	workerID := fmt.Sprintf("%s/%d", p.cfg.InstanceID, id)
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := p.jobs.ClaimNextJob(ctx, workerID, p.cfg.LeaseTTL)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Worker %d gagal mengambil job review: %v\n", id, err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reviewPollInterval):
+			}
+			continue
+		}
+
+		// Shutdown tidak memotong review yang sudah berjalan, hanya Halt yang bisa
+		p.process(context.WithoutCancel(ctx), workerID, job)
+	}
+}
+
+func (p *ReviewWorkerPool) process(ctx context.Context, workerID string, job *entity.ReviewJob) {
+	log.Printf("⚙️ Worker %s menjalankan job %s (repo %d, attempt %d)\n", workerID, job.ID, job.RepositoryID, job.Attempts)
+
+	// Cancel func per job, dipanggil saat DELETE /codereview/jobs/{id} menandai cancel_requested,
+	// saat lease hilang, atau saat Halt
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(p.haltCtx, func() {
+		cancel(fmt.Errorf("%w: instance shutting down", ErrReviewInterrupted))
+	})
+	defer stop()
+	go p.heartbeat(jobCtx, workerID, job.ID, cancel)
+
+	opts := ReviewOptions{
+		JobID:  job.ID,
+		Head:   job.HeadRef,
+		Base:   job.BaseRef,
+		Resume: job.Checkpoint,
+		Checkpoint: func(cp *entity.ReviewCheckpoint) {
+			if err := p.jobs.SaveCheckpoint(ctx, job.ID, workerID, cp); err != nil {
+				log.Printf("⚠️ Gagal menyimpan checkpoint job %s: %v\n", job.ID, err)
+			}
+		},
+	}
+	run, err := p.reviewer.RunCodeReview(jobCtx, job.RepositoryID, opts)
+
+	// Run yang belum selesai saat terputus dilanjutkan nanti, job tidak ditandai selesai
+	if cause := context.Cause(jobCtx); err != nil && errors.Is(cause, ErrReviewInterrupted) &&
+		(run == nil || run.Status == entity.ReviewRunRunning) {
+		if errors.Is(cause, repository.ErrReviewJobLeaseLost) {
+			// Job sudah diambil alih atau diubah instance lain, tidak ada yang perlu dicatat
+			log.Printf("⚠️ Job %s tidak lagi dipegang %s, review dihentikan\n", job.ID, workerID)
+			return
+		}
+		if err := p.jobs.ReleaseJob(ctx, job.ID, workerID, reviewReleaseReason); err != nil {
+			log.Printf("⚠️ Gagal mengembalikan job %s ke antrean: %v\n", job.ID, err)
+			return
+		}
+		log.Printf("⏸️ Job %s dikembalikan ke antrean, akan dilanjutkan dari checkpoint\n", job.ID)
+		return
+	}
+
+	var runID *int64
+	if run != nil {
+		runID = &run.ID
+	}
+
+	status, errMsg := entity.ReviewJobSucceeded, ""
+	switch {
+	case errors.Is(err, context.Canceled) || (run != nil && run.Status == entity.ReviewRunCancelled):
+		status, errMsg = entity.ReviewJobCancelled, "cancelled via API"
+	case err != nil:
+		status, errMsg = entity.ReviewJobFailed, err.Error()
+	case run.Status == entity.ReviewRunFailed:
+		status, errMsg = entity.ReviewJobFailed, run.Error
+	}
+
+	if err := p.jobs.FinishJob(ctx, job.ID, workerID, status, runID, errMsg); err != nil {
+		log.Printf("⚠️ Gagal menyimpan status job %s: %v\n", job.ID, err)
+		return
+	}
+	log.Printf("🏁 Job %s selesai dengan status %s\n", job.ID, status)
+}
+
+// heartbeat memperpanjang lease job secara berkala. Sekaligus memeriksa cancel_requested,
+// sehingga job juga bisa dibatalkan lewat instance lain yang menerima request DELETE.
+func (p *ReviewWorkerPool) heartbeat(ctx context.Context, workerID, jobID string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(p.cfg.LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cancelRequested, err := p.jobs.Heartbeat(ctx, jobID, workerID, p.cfg.LeaseTTL)
+			switch {
+			case errors.Is(err, repository.ErrReviewJobLeaseLost):
+				log.Printf("⚠️ Lease job %s hilang, menghentikan review\n", jobID)
+				cancel(fmt.Errorf("%w: %w", ErrReviewInterrupted, err))
+				return
+			case err != nil:
+				// Lease masih berlaku sampai LeaseTTL, coba lagi di tick berikutnya
+				if ctx.Err() == nil {
+					log.Printf("⚠️ Heartbeat job %s gagal: %v\n", jobID, err)
+				}
+			case cancelRequested:
+				log.Printf("⏹️ Job %s dibatalkan, menghentikan review\n", jobID)
+				cancel(nil)
+				return
+			}
+		}
+	}
+}
@@ -14,12 +14,16 @@ import (
 
 	"time"
 
-	"github.com/redis/go-redis/v9"
+	"github.com/jackc/pgx/v5"
 	"github.com/sony/gobreaker"
 	"go.opentelemetry.io/otel/attribute"
 )
 
 
+// ErrEmailExists dikembalikan jika email sudah dipakai user lain. Error permanen: event yang
+// gagal karena ini tidak diulang, command-nya langsung ditandai gagal.
+var ErrEmailExists = errors.New("email already exists")
+
 type IUserUsecase interface {
     CreateUser(ctx context.Context, user *entity.User) error
     GetUserByID(ctx context.Context, id int) (*entity.User, error)
@@ -32,7 +36,7 @@ type IUserUsecase interface {
 // UserUsecase mengelola logika bisnis untuk User
 type UserUsecase struct {
 	UserRepo   repository.UserRepository
-	redis      *redis.Client
+	cache      repository.CacheRepository
 	cbRedis    *gobreaker.CircuitBreaker
 	cbPostgres *gobreaker.CircuitBreaker
 	EventPublisher port.EventPublisher 
@@ -45,12 +49,12 @@ type UserInput struct {
 
 // NewUserUsecase membuat instance UserUsecase
 
-func NewUserUsecase(userRepo repository.UserRepository, redisClient *redis.Client, userPublisher port.EventPublisher) IUserUsecase {
+func NewUserUsecase(userRepo repository.UserRepository, cache repository.CacheRepository, userPublisher port.EventPublisher, alerts *cbreaker.Alerter) IUserUsecase {
 	return &UserUsecase{
 		UserRepo:   userRepo,
-		redis:      redisClient,
-		cbRedis:    cbreaker.NewBreaker("RedisBreaker"),
-		cbPostgres: cbreaker.NewBreaker("PostgresBreaker"),
+		cache:      cache,
+		cbRedis:    cbreaker.NewBreaker("RedisBreaker", alerts),
+		cbPostgres: cbreaker.NewBreaker("PostgresBreaker", alerts),
 		EventPublisher: userPublisher,
 	}
 }
@@ -61,7 +65,7 @@ func (uc *UserUsecase) IsEmailExists(ctx context.Context, email string) (bool, e
 
 	_, err := uc.UserRepo.GetByEmail(ctx, email)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
 		return false, err
@@ -78,7 +82,16 @@ func (uc *UserUsecase) CreateUser(ctx context.Context, user *entity.User) error
 		attribute.String("user.email", user.Email),
 	)
 
-	return uc.UserRepo.CreateUser(ctx, user)
+	// Idempotent lewat user.EventID: event yang dikirim ulang mengembalikan user yang sama.
+	// Email yang sudah dipakai user lain (event lain) selalu ditolak.
+	err := uc.UserRepo.CreateUser(ctx, user)
+	if errors.Is(err, repository.ErrDuplicateEmail) {
+		return ErrEmailExists
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
 }
 
 
@@ -90,7 +103,7 @@ func (uc *UserUsecase) GetAllUsers(ctx context.Context) ([]entity.User, error) {
 	return uc.UserRepo.GetAllUsers(ctx)
 }
 
-// ✅ Get user dari cache (Redis) atau DB
+// ✅ Get user dari cache atau DB
 func (uc *UserUsecase) GetUserByID(ctx context.Context, id int) (*entity.User, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "UserUsecase.GetUserById")
 	defer span.End()
@@ -99,7 +112,7 @@ func (uc *UserUsecase) GetUserByID(ctx context.Context, id int) (*entity.User, e
 
 	// Coba ambil dari Redis
 	val, err := uc.cbRedis.Execute(func() (interface{}, error) {
-		return uc.redis.Get(ctx, cacheKey).Result()
+		return uc.cache.Get(ctx, cacheKey)
 	})
 	if err == nil {
 		var cachedUser entity.User
@@ -133,6 +146,9 @@ func (uc *UserUsecase) UpdateUser(ctx context.Context, id int, input UserInput)
 	user.UpdatedAt = time.Now()
 
 	err = uc.UserRepo.UpdateUser(ctx, user)
+	if errors.Is(err, repository.ErrDuplicateEmail) {
+		return entity.User{}, ErrEmailExists
+	}
 	if err != nil {
 		return entity.User{}, err
 	}
@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/event"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
+	"log"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookEventAll memilih semua event, "<grup>.*" memilih semua event satu grup
+	webhookEventAll = "*"
+	maxWebhookURL   = 2048
+
+	defaultWebhookDeliveryLimit = 50
+	maxWebhookDeliveryLimit     = 200
+)
+
+var (
+	// ErrWebhookNotFound dikembalikan jika subscription webhook tidak ada
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidWebhook dikembalikan untuk URL, filter event atau secret yang tidak valid
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
+)
+
+// webhookEventTypes adalah event yang bisa dipilih subscription
+var webhookEventTypes = []string{
+	event.TypeUserCreated, event.TypeUserUpdated, event.TypeUserDeleted,
+	event.TypeRepositoryCreated, event.TypeRepositoryUpdated, event.TypeRepositoryDeleted,
+	entity.WebhookEventReviewCompleted, entity.WebhookEventReviewFailed, entity.WebhookEventReviewCancelled,
+}
+
+// WebhookInput adalah body POST /webhooks dan PUT /webhooks/{id}
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret kosong saat create dibuatkan secara acak, saat update berarti secret tidak diganti
+	Secret      string `json:"secret"`
+	Description string `json:"description"`
+	// Active hanya dipakai update, nil berarti tidak berubah
+	Active *bool `json:"active"`
+}
+
+// webhookPayload adalah body JSON yang dikirim ke endpoint subscription
+type webhookPayload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// webhookReviewData adalah data event review.*
+type webhookReviewData struct {
+	Run        *entity.ReviewRun    `json:"run"`
+	Repository event.RepositoryData `json:"repository"`
+}
+
+// WebhookPublisher dipakai sumber event (consumer event bus, code review) untuk meneruskan
+// event ke subscription webhook. Kegagalan hanya dicatat di log, tidak menggagalkan pemanggil.
+type WebhookPublisher interface {
+	// PublishWebhookEvent memasukkan satu pengiriman per subscription aktif yang filternya cocok.
+	// eventID yang sama tidak dikirim dua kali ke subscription yang sama.
+	PublishWebhookEvent(ctx context.Context, eventID, eventType string, data any)
+}
+
+type IWebhookUsecase interface {
+	WebhookPublisher
+	ReviewObserver
+	// CreateWebhook mengembalikan subscription beserta secret-nya, satu-satunya saat secret ditampilkan
+	CreateWebhook(ctx context.Context, input WebhookInput) (*entity.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	// UpdateWebhook mengganti url, events dan description. Active true mengaktifkan kembali
+	// subscription yang dinonaktifkan otomatis, secret hanya ditampilkan jika diganti.
+	UpdateWebhook(ctx context.Context, id int64, input WebhookInput) (*entity.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	// ListWebhookDeliveries mengembalikan log pengiriman terbaru subscription
+	ListWebhookDeliveries(ctx context.Context, id int64, limit int) ([]entity.WebhookDelivery, error)
+}
+
+type webhookUsecase struct {
+	repo repository.WebhookRepository
+	// allowPrivate mengizinkan URL ke alamat private/loopback, lihat WebhookWorkerConfig.AllowPrivate
+	allowPrivate bool
+}
+
+func NewWebhookUsecase(repo repository.WebhookRepository, allowPrivate bool) IWebhookUsecase {
+	return &webhookUsecase{repo: repo, allowPrivate: allowPrivate}
+}
+
+func (uc *webhookUsecase) CreateWebhook(ctx context.Context, input WebhookInput) (*entity.WebhookSubscription, error) {
+	sub, err := uc.validateWebhookInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		if sub.Secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	log.Printf("🔔 Webhook %d dibuat untuk %s, events %v", sub.ID, sub.URL, sub.Events)
+	return sub, nil
+}
+
+func (uc *webhookUsecase) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	subs, err := uc.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (uc *webhookUsecase) GetWebhook(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	sub, err := uc.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (uc *webhookUsecase) UpdateWebhook(ctx context.Context, id int64, input WebhookInput) (*entity.WebhookSubscription, error) {
+	current, err := uc.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := uc.validateWebhookInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	sub.ID = id
+	sub.Active = current.Active
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	if !sub.Active {
+		sub.DisabledReason = "disabled via API"
+	}
+
+	if err := uc.repo.UpdateSubscription(ctx, sub); err != nil {
+		if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+		}
+		return nil, err
+	}
+	if sub.Active && !current.Active {
+		log.Printf("🔔 Webhook %d diaktifkan kembali", id)
+	}
+	if input.Secret == "" {
+		sub.Secret = ""
+	}
+	return sub, nil
+}
+
+func (uc *webhookUsecase) DeleteWebhook(ctx context.Context, id int64) error {
+	if err := uc.repo.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			return fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+		}
+		return err
+	}
+	log.Printf("🗑️ Webhook %d dihapus", id)
+	return nil
+}
+
+func (uc *webhookUsecase) ListWebhookDeliveries(ctx context.Context, id int64, limit int) ([]entity.WebhookDelivery, error) {
+	if _, err := uc.getSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryLimit
+	}
+	return uc.repo.ListDeliveries(ctx, id, min(limit, maxWebhookDeliveryLimit))
+}
+
+func (uc *webhookUsecase) getSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	sub, err := uc.repo.GetSubscription(ctx, id)
+	if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+	}
+	return sub, err
+}
+
+func (uc *webhookUsecase) PublishWebhookEvent(ctx context.Context, eventID, eventType string, data any) {
+	subs, err := uc.repo.ListSubscriptions(ctx)
+	if err != nil {
+		log.Printf("⚠️ Gagal membaca subscription webhook untuk %s: %v", eventType, err)
+		return
+	}
+	var targets []entity.WebhookSubscription
+	for _, sub := range subs {
+		if sub.Active && webhookEventMatches(sub.Events, eventType) {
+			targets = append(targets, sub)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(webhookPayload{ID: eventID, Type: eventType, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		log.Printf("⚠️ Gagal menyusun payload webhook %s: %v", eventType, err)
+		return
+	}
+	deliveries := make([]entity.WebhookDelivery, 0, len(targets))
+	for _, sub := range targets {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+	}
+
+	n, err := uc.repo.EnqueueDeliveries(ctx, deliveries)
+	if err != nil {
+		log.Printf("⚠️ Gagal memasukkan pengiriman webhook %s (%s): %v", eventType, eventID, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("🔔 Event %s (%s) masuk antrean ke %d webhook", eventType, eventID, n)
+	}
+}
+
+// ReviewFinished meneruskan review run yang selesai sebagai review.completed, review.failed
+// atau review.cancelled
+func (uc *webhookUsecase) ReviewFinished(ctx context.Context, repo *entity.Repository, run *entity.ReviewRun) {
+	eventType := "review." + run.Status
+	if !slices.Contains(webhookEventTypes, eventType) {
+		return
+	}
+	data := webhookReviewData{
+		Run: run,
+		Repository: event.RepositoryData{
+			ID:        repo.ID,
+			UserID:    repo.UserID,
+			Name:      repo.Name,
+			URL:       repo.URL,
+			AIEnabled: repo.AIEnabled,
+		},
+	}
+	uc.PublishWebhookEvent(ctx, uuid.NewString(), eventType, data)
+}
+
+// validateWebhookInput memeriksa URL dan filter event, filter dirapikan (tanpa duplikat)
+func (uc *webhookUsecase) validateWebhookInput(ctx context.Context, input WebhookInput) (*entity.WebhookSubscription, error) {
+	target := strings.TrimSpace(input.URL)
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(target) > maxWebhookURL {
+		return nil, fmt.Errorf("%w: url is longer than %d characters", ErrInvalidWebhook, maxWebhookURL)
+	}
+	if !uc.allowPrivate {
+		if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(input.Events) == 0 {
+		return nil, fmt.Errorf("%w: events must not be empty, use \"*\" for all events", ErrInvalidWebhook)
+	}
+	var events []string
+	for _, e := range input.Events {
+		e = strings.TrimSpace(e)
+		if !validWebhookEventFilter(e) {
+			return nil, fmt.Errorf("%w: unknown event %q, expected one of %v, \"<group>.*\" or \"*\"", ErrInvalidWebhook, e, webhookEventTypes)
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+
+	if input.Secret != "" && len(input.Secret) < minWebhookSecretLength {
+		return nil, fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidWebhook, minWebhookSecretLength)
+	}
+
+	return &entity.WebhookSubscription{
+		URL:         target,
+		Events:      events,
+		Secret:      input.Secret,
+		Description: strings.TrimSpace(input.Description),
+	}, nil
+}
+
+// checkWebhookHost menolak host yang (salah satu alamatnya) private, loopback atau link-local.
+// Sender memeriksa ulang saat dial, pemeriksaan ini supaya subscription yang jelas salah
+// langsung ditolak dengan 400.
+func checkWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve host %q", ErrInvalidWebhook, host)
+	}
+	for _, ip := range addrs {
+		if !port.PublicWebhookAddr(ip) {
+			return fmt.Errorf("%w: url must not point to a private, loopback or link-local address", ErrInvalidWebhook)
+		}
+	}
+	return nil
+}
+
+func validWebhookEventFilter(filter string) bool {
+	if filter == webhookEventAll || slices.Contains(webhookEventTypes, filter) {
+		return true
+	}
+	group, ok := strings.CutSuffix(filter, ".*")
+	return ok && slices.ContainsFunc(webhookEventTypes, func(t string) bool {
+		return strings.HasPrefix(t, group+".")
+	})
+}
+
+func webhookEventMatches(filters []string, eventType string) bool {
+	for _, f := range filters {
+		if f == webhookEventAll || f == eventType {
+			return true
+		}
+		if group, ok := strings.CutSuffix(f, ".*"); ok && strings.HasPrefix(eventType, group+".") {
+			return true
+		}
+	}
+	return false
+}
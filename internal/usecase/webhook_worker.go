@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crud/internal/entity"
+	"go-crud/internal/repository"
+	"go-crud/internal/usecase/port"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	webhookPollInterval        = 1 * time.Second
+	webhookBatchSize           = 20
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookRetryBase    = 30 * time.Second
+	defaultWebhookDisableAfter = 20
+	webhookMaxBackoff          = 1 * time.Hour
+	// webhookClaimMargin ditambahkan ke timeout request supaya claim tidak habis saat request berjalan
+	webhookClaimMargin = 30 * time.Second
+)
+
+// WebhookWorkerConfig mengatur batas waktu, retry dan ambang nonaktif otomatis webhook keluar
+type WebhookWorkerConfig struct {
+	// Timeout adalah batas waktu satu request ke endpoint
+	Timeout time.Duration
+	// MaxAttempts adalah jumlah percobaan sebelum pengiriman ditandai failed
+	MaxAttempts int
+	// RetryBase adalah jeda sebelum percobaan kedua, berlipat dua setiap percobaan (maksimal 1 jam)
+	RetryBase time.Duration
+	// DisableAfter adalah jumlah percobaan gagal berturut-turut sebelum subscription dinonaktifkan
+	DisableAfter int
+	// AllowPrivate mengizinkan endpoint di alamat private, loopback atau link-local, hanya untuk
+	// pengembangan lokal. Default ditolak supaya webhook tidak bisa dipakai untuk SSRF.
+	AllowPrivate bool
+}
+
+// WebhookWorkerConfigFromEnv membaca WEBHOOK_TIMEOUT (default 10s), WEBHOOK_MAX_ATTEMPTS (default 8),
+// WEBHOOK_RETRY_BASE (default 30s), WEBHOOK_DISABLE_AFTER (default 20) dan WEBHOOK_ALLOW_PRIVATE (default false)
+func WebhookWorkerConfigFromEnv() WebhookWorkerConfig {
+	cfg := WebhookWorkerConfig{
+		Timeout:      defaultWebhookTimeout,
+		MaxAttempts:  defaultWebhookMaxAttempts,
+		RetryBase:    defaultWebhookRetryBase,
+		DisableAfter: defaultWebhookDisableAfter,
+	}
+	if d, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT")); err == nil && d > 0 {
+		cfg.Timeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("WEBHOOK_RETRY_BASE")); err == nil && d > 0 {
+		cfg.RetryBase = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("WEBHOOK_DISABLE_AFTER")); err == nil && n > 0 {
+		cfg.DisableAfter = n
+	}
+	cfg.AllowPrivate, _ = strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE"))
+	return cfg
+}
+
+// WebhookDeliveryWorker mengirim antrean webhook_deliveries ke endpoint subscription.
+// Pengiriman at-least-once: penerima men-dedupe dengan header delivery ID atau id di payload.
+type WebhookDeliveryWorker struct {
+	repo   repository.WebhookRepository
+	sender port.WebhookSender
+	cfg    WebhookWorkerConfig
+}
+
+func NewWebhookDeliveryWorker(repo repository.WebhookRepository, sender port.WebhookSender, cfg WebhookWorkerConfig) *WebhookDeliveryWorker {
+	return &WebhookDeliveryWorker{repo: repo, sender: sender, cfg: cfg}
+}
+
+func (w *WebhookDeliveryWorker) Start(ctx context.Context) {
+	log.Println("🚀 Webhook delivery worker started...")
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+			w.deliverBatch(ctx)
+		}
+	}
+}
+
+func (w *WebhookDeliveryWorker) deliverBatch(ctx context.Context) {
+	deliveries, err := w.repo.ClaimDueDeliveries(ctx, webhookBatchSize, w.cfg.Timeout+webhookClaimMargin)
+	if err != nil {
+		log.Printf("⚠️ Failed to claim webhook deliveries: %v\n", err)
+		return
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	subs := make(map[int64]*entity.WebhookSubscription)
+	for _, d := range deliveries {
+		if _, ok := subs[d.SubscriptionID]; ok {
+			continue
+		}
+		sub, err := w.repo.GetSubscription(ctx, d.SubscriptionID)
+		if err != nil && !errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			log.Printf("⚠️ Failed to load webhook subscription %d: %v\n", d.SubscriptionID, err)
+		}
+		subs[d.SubscriptionID] = sub
+	}
+
+	// Request yang sudah dikirim diselesaikan walaupun worker dihentikan, supaya hasilnya tercatat
+	sendCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		sub := subs[d.SubscriptionID]
+		if sub == nil {
+			// Subscription dihapus (pengirimannya ikut terhapus) atau gagal dibaca, dicoba lagi setelah claim habis
+			continue
+		}
+		wg.Add(1)
+		go func(d entity.WebhookDelivery) {
+			defer wg.Done()
+			w.deliver(sendCtx, sub, d)
+		}(d)
+	}
+	wg.Wait()
+}
+
+func (w *WebhookDeliveryWorker) deliver(ctx context.Context, sub *entity.WebhookSubscription, d entity.WebhookDelivery) {
+	d.Attempts++
+	start := time.Now()
+	resp, sendErr := w.sender.Send(ctx, port.WebhookRequest{
+		URL:        sub.URL,
+		Secret:     sub.Secret,
+		EventType:  d.EventType,
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts,
+		Body:       d.Payload,
+	})
+	d.DurationMs = time.Since(start).Milliseconds()
+	d.LastStatusCode, d.LastResponse, d.LastError = 0, "", ""
+	if resp != nil {
+		d.LastStatusCode, d.LastResponse = resp.StatusCode, resp.Body
+	}
+
+	var retryIn time.Duration
+	switch {
+	case sendErr == nil:
+		d.Status = entity.WebhookDeliverySucceeded
+		log.Printf("📤 Webhook %d: %s (delivery %d) terkirim ke %s [%d]", sub.ID, d.EventType, d.ID, sub.URL, d.LastStatusCode)
+	case d.Attempts >= w.cfg.MaxAttempts:
+		d.Status = entity.WebhookDeliveryFailed
+		d.LastError = sendErr.Error()
+		log.Printf("❌ Webhook %d: %s (delivery %d) gagal setelah %d percobaan: %v", sub.ID, d.EventType, d.ID, d.Attempts, sendErr)
+	default:
+		d.Status = entity.WebhookDeliveryPending
+		d.LastError = sendErr.Error()
+		retryIn = webhookBackoff(w.cfg.RetryBase, d.Attempts)
+		log.Printf("⚠️ Webhook %d: %s (delivery %d) gagal (percobaan %d), retry in %s: %v", sub.ID, d.EventType, d.ID, d.Attempts, retryIn, sendErr)
+	}
+
+	if err := w.repo.RecordAttempt(ctx, &d, retryIn); err != nil {
+		// Pengiriman dicoba lagi setelah claim habis, penerima harus tahan duplikat
+		log.Printf("⚠️ Failed to record webhook delivery %d: %v\n", d.ID, err)
+	}
+
+	reason := ""
+	if sendErr != nil {
+		reason = fmt.Sprintf("%d consecutive failed deliveries, last error: %v", w.cfg.DisableAfter, sendErr)
+	}
+	disabled, err := w.repo.RecordSubscriptionResult(ctx, sub.ID, sendErr == nil, w.cfg.DisableAfter, reason)
+	if err != nil {
+		log.Printf("⚠️ Failed to update webhook subscription %d: %v\n", sub.ID, err)
+		return
+	}
+	if disabled {
+		log.Printf("🚫 Webhook %d (%s) dinonaktifkan: %s", sub.ID, sub.URL, reason)
+	}
+}
+
+// webhookBackoff adalah jeda sebelum percobaan berikutnya: base, 2x base, 4x base, ... maksimal 1 jam
+func webhookBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
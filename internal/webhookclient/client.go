@@ -0,0 +1,128 @@
+// Package webhookclient mengirim webhook keluar ke endpoint subscription lewat HTTP.
+//
+// Setiap request membawa header:
+//
+//	X-GoCrud-Event: repository.created
+//	X-GoCrud-Delivery: 42
+//	X-GoCrud-Timestamp: 1767225600
+//	X-GoCrud-Signature-256: sha256=<hex HMAC-SHA256(secret, timestamp + "." + body)>
+//
+// Timestamp ikut ditandatangani supaya penerima bisa menolak request lama yang diputar ulang.
+package webhookclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-crud/internal/usecase/port"
+)
+
+const (
+	HeaderEvent     = "X-GoCrud-Event"
+	HeaderDelivery  = "X-GoCrud-Delivery"
+	HeaderTimestamp = "X-GoCrud-Timestamp"
+	HeaderSignature = "X-GoCrud-Signature-256"
+
+	// Body response yang disimpan di log pengiriman
+	maxResponseBody = 2 << 10
+	userAgent       = "go-crud-webhooks/1.0"
+)
+
+// ErrBlockedAddress dikembalikan jika endpoint ter-resolve ke alamat private, loopback atau link-local
+var ErrBlockedAddress = errors.New("webhook endpoint resolves to a non-public address")
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender membuat sender dengan batas waktu per request. Redirect tidak diikuti,
+// endpoint harus menjawab langsung dengan 2xx. Tanpa allowPrivate setiap koneksi diperiksa
+// saat dial, setelah DNS di-resolve, sehingga hostname yang berganti ke alamat internal
+// (DNS rebinding) tetap ditolak. Proxy dari environment tidak dipakai karena dial ke proxy
+// akan melewati pemeriksaan itu.
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) port.WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: publicOnly}
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+	return &httpSender{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// publicOnly adalah net.Dialer.Control, dipanggil dengan alamat IP yang benar-benar akan di-dial
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if !port.PublicWebhookAddr(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr.Addr())
+	}
+	return nil
+}
+
+// Sign menghitung nilai header X-GoCrud-Signature-256, dipakai juga oleh penerima untuk verifikasi
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *httpSender) Send(ctx context.Context, r port.WebhookRequest) (*port.WebhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, r.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(r.DeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, timestamp, r.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Sisa body dibuang supaya koneksi bisa dipakai ulang
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	out := &port.WebhookResponse{StatusCode: resp.StatusCode, Body: printable(body)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return out, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return out, nil
+}
+
+// printable membuang byte yang tidak valid UTF-8 (termasuk karakter yang terpotong batas
+// baca) dan NUL supaya body aman disimpan di kolom text
+func printable(b []byte) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(string(b), ""), "\x00", "")
+}
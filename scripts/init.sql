@@ -22,46 +22,6 @@ SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
---
--- TOC entry 220 (class 1259 OID 53396)
--- Name: codereview_log; Type: TABLE; Schema: public; Owner: postgres
---
-
-CREATE TABLE public.codereview_log (
-    id integer NOT NULL,
-    repository_id integer NOT NULL,
-    review_result text NOT NULL,
-    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
-);
-
-
-ALTER TABLE public.codereview_log OWNER TO postgres;
-
---
--- TOC entry 219 (class 1259 OID 53395)
--- Name: codereview_log_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
---
-
-CREATE SEQUENCE public.codereview_log_id_seq
-    AS integer
-    START WITH 1
-    INCREMENT BY 1
-    NO MINVALUE
-    NO MAXVALUE
-    CACHE 1;
-
-
-ALTER SEQUENCE public.codereview_log_id_seq OWNER TO postgres;
-
---
--- TOC entry 4862 (class 0 OID 0)
--- Dependencies: 219
--- Name: codereview_log_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: postgres
---
-
-ALTER SEQUENCE public.codereview_log_id_seq OWNED BY public.codereview_log.id;
-
-
 --
 -- TOC entry 218 (class 1259 OID 53379)
 -- Name: repositories; Type: TABLE; Schema: public; Owner: postgres
@@ -72,9 +32,13 @@ CREATE TABLE public.repositories (
     user_id integer NOT NULL,
     name character varying(100) NOT NULL,
     url text NOT NULL,
+    -- URL yang dinormalisasi (entity.NormalizeGitURL) untuk mencocokkan webhook git
+    url_key text,
     ai_enabled boolean DEFAULT false,
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
-    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    -- ID event repository.created yang membuat baris ini, kunci idempoten consumer
+    event_id character varying(64)
 );
 
 
@@ -115,7 +79,9 @@ CREATE TABLE public.users (
     name character varying(100) NOT NULL,
     email character varying(100) NOT NULL,
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
-    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    -- ID event user.created yang membuat baris ini, kunci idempoten consumer
+    event_id character varying(64)
 );
 
 
@@ -146,14 +112,6 @@ ALTER SEQUENCE public.users_id_seq OWNER TO postgres;
 ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
 
 
---
--- TOC entry 4702 (class 2604 OID 53399)
--- Name: codereview_log id; Type: DEFAULT; Schema: public; Owner: postgres
---
-
-ALTER TABLE ONLY public.codereview_log ALTER COLUMN id SET DEFAULT nextval('public.codereview_log_id_seq'::regclass);
-
-
 --
 -- TOC entry 4698 (class 2604 OID 53382)
 -- Name: repositories id; Type: DEFAULT; Schema: public; Owner: postgres
@@ -170,15 +128,6 @@ ALTER TABLE ONLY public.repositories ALTER COLUMN id SET DEFAULT nextval('public
 ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
 
 
---
--- TOC entry 4711 (class 2606 OID 53404)
--- Name: codereview_log codereview_log_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
-ALTER TABLE ONLY public.codereview_log
-    ADD CONSTRAINT codereview_log_pkey PRIMARY KEY (id);
-
-
 --
 -- TOC entry 4709 (class 2606 OID 53389)
 -- Name: repositories repositories_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
@@ -187,6 +136,11 @@ ALTER TABLE ONLY public.codereview_log
 ALTER TABLE ONLY public.repositories
     ADD CONSTRAINT repositories_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY public.repositories
+    ADD CONSTRAINT repositories_event_id_key UNIQUE (event_id);
+
+CREATE INDEX repositories_url_key_idx ON public.repositories (url_key);
+
 
 --
 -- TOC entry 4705 (class 2606 OID 53377)
@@ -196,6 +150,9 @@ ALTER TABLE ONLY public.repositories
 ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_email_key UNIQUE (email);
 
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_event_id_key UNIQUE (event_id);
+
 
 --
 -- TOC entry 4707 (class 2606 OID 53375)
@@ -206,15 +163,6 @@ ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
 
---
--- TOC entry 4713 (class 2606 OID 53405)
--- Name: codereview_log codereview_log_repository_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
---
-
-ALTER TABLE ONLY public.codereview_log
-    ADD CONSTRAINT codereview_log_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES public.repositories(id) ON DELETE CASCADE;
-
-
 --
 -- TOC entry 4712 (class 2606 OID 53390)
 -- Name: repositories repositories_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
@@ -230,3 +178,194 @@ ALTER TABLE ONLY public.repositories
 -- PostgreSQL database dump complete
 --
 
+
+-- Status command async untuk write endpoint yang mengembalikan 202
+CREATE TABLE public.commands (
+    id character varying(36) NOT NULL PRIMARY KEY,
+    type character varying(50) NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    entity_id integer,
+    error text,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Outbox event, ditulis satu transaksi dengan baris commands lalu dikirim ke Kafka oleh outbox relay.
+-- Perubahan data users/repositories terjadi kemudian di consumer, bukan di transaksi ini.
+CREATE TABLE public.outbox (
+    id bigserial PRIMARY KEY,
+    topic character varying(100) NOT NULL,
+    event_type character varying(100) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    next_attempt_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    delivered_at timestamp without time zone
+);
+
+CREATE INDEX outbox_pending_idx ON public.outbox (next_attempt_at) WHERE status = 'pending';
+
+-- Pesan Kafka yang gagal setelah semua retry topic
+CREATE TABLE public.dead_letters (
+    id bigserial PRIMARY KEY,
+    topic character varying(150) NOT NULL,
+    original_topic character varying(100) NOT NULL,
+    original_partition integer NOT NULL,
+    original_offset bigint NOT NULL,
+    event_type character varying(100) NOT NULL,
+    -- Value pesan apa adanya (JSON, Avro atau Protobuf) beserta header aslinya
+    payload bytea NOT NULL,
+    headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    -- Envelope hasil decode consumer, NULL jika pesan tidak bisa di-decode
+    envelope jsonb,
+    attempts integer DEFAULT 0 NOT NULL,
+    error text NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    replayed_at timestamp without time zone
+);
+
+-- Satu baris per eksekusi code review, ringkasan jumlah temuan per severity
+CREATE TABLE public.review_runs (
+    id bigserial PRIMARY KEY,
+    repository_id integer NOT NULL REFERENCES public.repositories(id) ON DELETE CASCADE,
+    status character varying(20) DEFAULT 'running' NOT NULL,
+    commit_sha character varying(64),
+    base_sha character varying(64),
+    error text,
+    error_count integer DEFAULT 0 NOT NULL,
+    warning_count integer DEFAULT 0 NOT NULL,
+    info_count integer DEFAULT 0 NOT NULL,
+    duration_ms bigint DEFAULT 0 NOT NULL,
+    resume_count integer DEFAULT 0 NOT NULL,
+    resumed_at timestamp without time zone,
+    started_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    finished_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX review_runs_repository_idx ON public.review_runs (repository_id, id DESC);
+
+-- Temuan analyzer per run, fingerprint stabil antar run untuk temuan yang sama
+CREATE TABLE public.review_findings (
+    id bigserial PRIMARY KEY,
+    run_id bigint NOT NULL REFERENCES public.review_runs(id) ON DELETE CASCADE,
+    rule_id character varying(100) NOT NULL,
+    severity character varying(20) NOT NULL,
+    file_path text NOT NULL,
+    start_line integer NOT NULL,
+    start_column integer NOT NULL,
+    end_line integer NOT NULL,
+    end_column integer NOT NULL,
+    message text NOT NULL,
+    suggested_fix text,
+    fingerprint character varying(64) NOT NULL
+);
+
+CREATE INDEX review_findings_run_idx ON public.review_findings (run_id);
+
+-- Antrean code review, diambil worker dengan FOR UPDATE SKIP LOCKED
+CREATE TABLE public.review_jobs (
+    id uuid PRIMARY KEY,
+    repository_id integer NOT NULL REFERENCES public.repositories(id) ON DELETE CASCADE,
+    base_ref text,
+    head_ref text,
+    status character varying(20) DEFAULT 'queued' NOT NULL,
+    run_id bigint REFERENCES public.review_runs(id) ON DELETE SET NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    error text,
+    cancel_requested boolean DEFAULT false NOT NULL,
+    worker_id character varying(100),
+    lease_expires_at timestamp with time zone,
+    checkpoint jsonb,
+    transitions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    started_at timestamp without time zone,
+    finished_at timestamp without time zone
+);
+
+CREATE INDEX review_jobs_queued_idx ON public.review_jobs (created_at) WHERE status = 'queued';
+CREATE INDEX review_jobs_lease_idx ON public.review_jobs (lease_expires_at) WHERE status = 'running';
+
+-- Analyzer code review yang diaktifkan per repository, tanpa baris berarti semua analyzer bawaan
+CREATE TABLE public.repository_analyzers (
+    repository_id integer NOT NULL REFERENCES public.repositories(id) ON DELETE CASCADE,
+    analyzer character varying(100) NOT NULL,
+    PRIMARY KEY (repository_id, analyzer)
+);
+
+-- Profil review default per repository (format sama dengan .gocrud-review.yml),
+-- dipakai jika repository tidak punya file konfigurasi sendiri
+CREATE TABLE public.repository_review_configs (
+    repository_id integer PRIMARY KEY REFERENCES public.repositories(id) ON DELETE CASCADE,
+    config jsonb NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Secret webhook git per repository untuk memverifikasi POST /webhooks/git/{provider}
+CREATE TABLE public.repository_webhook_secrets (
+    repository_id integer PRIMARY KEY REFERENCES public.repositories(id) ON DELETE CASCADE,
+    secret text NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Pemakaian token AI code review per user per bulan (month = tanggal 1), dibatasi AI_MONTHLY_TOKEN_QUOTA
+CREATE TABLE public.ai_token_usage (
+    user_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    month date NOT NULL,
+    tokens bigint DEFAULT 0 NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, month)
+);
+
+-- Subscription webhook keluar, events berisi tipe event, "<grup>.*" atau "*"
+CREATE TABLE public.webhook_subscriptions (
+    id bigserial PRIMARY KEY,
+    url text NOT NULL,
+    events text[] NOT NULL,
+    secret text NOT NULL,
+    description text,
+    active boolean DEFAULT true NOT NULL,
+    consecutive_failures integer DEFAULT 0 NOT NULL,
+    disabled_reason text,
+    disabled_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Antrean + log pengiriman webhook, satu baris per event per subscription
+CREATE TABLE public.webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES public.webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id character varying(64) NOT NULL,
+    event_type character varying(100) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    next_attempt_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_status_code integer,
+    last_error text,
+    last_response text,
+    duration_ms bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    delivered_at timestamp without time zone,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON public.webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_idx ON public.webhook_deliveries (subscription_id, id DESC);
+
+-- Preferensi notifikasi per user (review selesai). channels kosong berarti email ke alamat user,
+-- jam tenang "HH:MM" di timezone (notifikasi non-critical dibuang), kosong berarti tanpa jam tenang
+CREATE TABLE public.notification_preferences (
+    user_id integer PRIMARY KEY REFERENCES public.users(id) ON DELETE CASCADE,
+    channels text[] DEFAULT '{}' NOT NULL,
+    min_severity character varying(16) DEFAULT 'info' NOT NULL,
+    quiet_hours_start character varying(5) DEFAULT '' NOT NULL,
+    quiet_hours_end character varying(5) DEFAULT '' NOT NULL,
+    timezone character varying(64) DEFAULT 'UTC' NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -0,0 +1,31 @@
+-- Migrasi database lama: tabel commands dan outbox untuk write endpoint yang mengembalikan 202.
+-- Definisinya sama dengan init.sql, aman dijalankan ulang.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.commands (
+    id character varying(36) NOT NULL PRIMARY KEY,
+    type character varying(50) NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    entity_id integer,
+    error text,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.outbox (
+    id bigserial PRIMARY KEY,
+    topic character varying(100) NOT NULL,
+    event_type character varying(100) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    next_attempt_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    delivered_at timestamp without time zone
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON public.outbox (next_attempt_at) WHERE status = 'pending';
+
+COMMIT;
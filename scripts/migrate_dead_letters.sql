@@ -0,0 +1,25 @@
+-- Migrasi database lama: tabel dead_letters untuk pesan yang gagal setelah semua retry topic.
+-- Definisinya sama dengan init.sql, aman dijalankan ulang. Endpoint /admin/dlq membaca tabel ini.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.dead_letters (
+    id bigserial PRIMARY KEY,
+    topic character varying(150) NOT NULL,
+    original_topic character varying(100) NOT NULL,
+    original_partition integer NOT NULL,
+    original_offset bigint NOT NULL,
+    event_type character varying(100) NOT NULL,
+    -- Value pesan apa adanya (JSON, Avro atau Protobuf) beserta header aslinya
+    payload bytea NOT NULL,
+    headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    -- Envelope hasil decode consumer, NULL jika pesan tidak bisa di-decode
+    envelope jsonb,
+    attempts integer DEFAULT 0 NOT NULL,
+    error text NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    replayed_at timestamp without time zone
+);
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migrasi database lama: kolom event_id di users dan repositories.
+-- Consumer memakai INSERT ... ON CONFLICT (event_id) supaya event *.created yang dikirim
+-- ulang (redelivery, replay DLQ) tidak membuat baris ganda. Baris lama tetap NULL.
+
+BEGIN;
+
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS event_id character varying(64);
+
+ALTER TABLE public.repositories
+    ADD COLUMN IF NOT EXISTS event_id character varying(64);
+
+-- ADD CONSTRAINT tidak punya IF NOT EXISTS, dicek lewat pg_constraint supaya aman dijalankan ulang
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_event_id_key') THEN
+        ALTER TABLE ONLY public.users
+            ADD CONSTRAINT users_event_id_key UNIQUE (event_id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'repositories_event_id_key') THEN
+        ALTER TABLE ONLY public.repositories
+            ADD CONSTRAINT repositories_event_id_key UNIQUE (event_id);
+    END IF;
+END $$;
+
+COMMIT;
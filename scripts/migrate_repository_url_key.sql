@@ -0,0 +1,32 @@
+-- Migrasi database lama: kolom repositories.url_key untuk mencari repository dari webhook git.
+-- Backfill mengikuti entity.NormalizeGitURL: tanpa scheme dan user, bentuk scp (git@host:path)
+-- diubah ke host/path, tanpa port 22/80/443, query, '/' di akhir dan .git, lalu huruf kecil.
+-- Repository yang disimpan ulang lewat API selalu memakai hasil NormalizeGitURL.
+
+BEGIN;
+
+ALTER TABLE public.repositories
+    ADD COLUMN IF NOT EXISTS url_key text;
+
+UPDATE public.repositories
+SET url_key = lower(
+    regexp_replace(
+    regexp_replace(
+    regexp_replace(
+    regexp_replace(
+        CASE
+            WHEN btrim(url) ~ '^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]'
+                THEN regexp_replace(btrim(url), '^[a-zA-Z][a-zA-Z0-9+.-]*://([^@/]*@)?', '')
+            WHEN btrim(url) !~ '://' AND btrim(url) ~ '@[^:]*:'
+                THEN regexp_replace(btrim(url), '^[^@]*@([^:]*):', '\1/')
+            ELSE btrim(url)
+        END,
+        '^([^/:]+):(22|80|443)(/|$)', '\1\3'),
+        '[?#].*$', ''),
+        '/+$', ''),
+        '\.git$', ''))
+WHERE url_key IS NULL;
+
+CREATE INDEX IF NOT EXISTS repositories_url_key_idx ON public.repositories (url_key);
+
+COMMIT;
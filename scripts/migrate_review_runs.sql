@@ -0,0 +1,17 @@
+-- Migrasi database lama: codereview_log -> review_runs.
+-- Jalankan sekali setelah tabel review_runs dan review_findings dari init.sql dibuat.
+-- Hasil lama hanya berupa teks, jadi tidak ada temuan yang bisa dipindahkan.
+
+BEGIN;
+
+INSERT INTO public.review_runs (repository_id, status, error, started_at, finished_at, created_at)
+SELECT repository_id,
+       CASE WHEN review_result LIKE 'Code review failed:%' THEN 'failed' ELSE 'completed' END,
+       CASE WHEN review_result LIKE 'Code review failed:%' THEN review_result END,
+       created_at, created_at, created_at
+FROM public.codereview_log
+ORDER BY id;
+
+DROP TABLE public.codereview_log;
+
+COMMIT;
@@ -5,8 +5,8 @@ INSERT INTO users (id, name, email, created_at, updated_at) VALUES
 (3, 'Jane Smith', 'jane@example.com', NOW(), NOW());
 
 -- Insert data ke tabel repositories
-INSERT INTO repositories (id, user_id, name, url, ai_enabled, created_at, updated_at) VALUES
-(1, 1, 'Go CRUD API', 'https://github.com/annisa/go-crud', TRUE, NOW(), NOW()),
-(2, 1, 'Golang CLI Tool', 'https://github.com/annisa/go-cli', FALSE, NOW(), NOW()),
-(3, 2, 'React Frontend', 'https://github.com/johndoe/react-app', TRUE, NOW(), NOW()),
-(4, 3, 'Python ML Project', 'https://github.com/janesmith/ml-project', FALSE, NOW(), NOW());
+INSERT INTO repositories (id, user_id, name, url, url_key, ai_enabled, created_at, updated_at) VALUES
+(1, 1, 'Go CRUD API', 'https://github.com/annisa/go-crud', 'github.com/annisa/go-crud', TRUE, NOW(), NOW()),
+(2, 1, 'Golang CLI Tool', 'https://github.com/annisa/go-cli', 'github.com/annisa/go-cli', FALSE, NOW(), NOW()),
+(3, 2, 'React Frontend', 'https://github.com/johndoe/react-app', 'github.com/johndoe/react-app', TRUE, NOW(), NOW()),
+(4, 3, 'Python ML Project', 'https://github.com/janesmith/ml-project', 'github.com/janesmith/ml-project', FALSE, NOW(), NOW());
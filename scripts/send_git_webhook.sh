@@ -0,0 +1,43 @@
+#!/bin/sh
+# Kirim contoh payload webhook git ke server lokal, lengkap dengan header event dan signature.
+#
+#   scripts/send_git_webhook.sh <fixture.json> <secret> [base-url]
+#
+# Provider dan event diambil dari nama file di internal/gitwebhook/testdata, contoh
+# github_push.json atau gitlab_merge_request.json. Repository tujuan harus terdaftar dengan
+# URL yang sama seperti di payload (contoh https://github.com/acme/widgets) dan secret-nya
+# diatur lewat PUT /repositories/{id}/webhook-secret.
+set -eu
+
+fixture=$1
+secret=$2
+base=${3:-http://localhost:8080}
+
+name=$(basename "$fixture" .json)
+provider=${name%%_*}
+event=${name#*_}
+
+case $provider in
+github)
+	sig=$(openssl dgst -sha256 -hmac "$secret" -hex <"$fixture" | sed 's/^.* //')
+	set -- -H "X-GitHub-Event: $event" -H "X-Hub-Signature-256: sha256=$sig"
+	;;
+gitea)
+	sig=$(openssl dgst -sha256 -hmac "$secret" -hex <"$fixture" | sed 's/^.* //')
+	set -- -H "X-Gitea-Event: $event" -H "X-Gitea-Signature: $sig"
+	;;
+gitlab)
+	case $event in
+	push) event="Push Hook" ;;
+	merge_request) event="Merge Request Hook" ;;
+	esac
+	set -- -H "X-Gitlab-Event: $event" -H "X-Gitlab-Token: $secret"
+	;;
+*)
+	echo "unknown provider in fixture name: $name" >&2
+	exit 1
+	;;
+esac
+
+curl -sS -X POST "$base/webhooks/git/$provider" -H "Content-Type: application/json" "$@" --data-binary "@$fixture"
+echo